@@ -0,0 +1,378 @@
+// Command arena plays a match between two bot configurations, to compare
+// checkpoints and settings before one goes into the bots table. The bots
+// alternate colours over pairs of games that start from the same random
+// opening, no two pairs from symmetric ones, and the report gives the score, the Elo difference with its
+// error bars and the verdict of an SPRT.
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/bot"
+	"tic-tac-chec/internal/bot/player"
+	"tic-tac-chec/internal/bot/search"
+
+	"github.com/alecthomas/kong"
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+const (
+	inferenceNative      = "native"
+	inferenceONNXRuntime = "onnxruntime"
+)
+
+// openingDraws is the number of times an opening is drawn before one
+// symmetric to an earlier opening is kept, when there are few to go round.
+const openingDraws = 10
+
+var colorNames = [engine.ColorCount]string{"white", "black"}
+
+var cli struct {
+	A botSpec `arg:"" help:"First bot, e.g. model=bot/models/bot_hard.onnx,sims=400"`
+	B botSpec `arg:"" help:"Second bot, e.g. search=4"`
+
+	Games        int    `help:"Most games to play" default:"400"`
+	Parallel     int    `help:"Games played at once, GOMAXPROCS if 0"`
+	OpeningPlies int    `help:"Random plies each pair of games starts with" default:"2"`
+	Seed         uint64 `help:"Seed of the openings and rollouts"`
+
+	Elo0  float64 `help:"Elo advantage of the first bot under H0" default:"0"`
+	Elo1  float64 `help:"Elo advantage of the first bot under H1" default:"10"`
+	Alpha float64 `help:"Chance of accepting H1 when H0 holds" default:"0.05"`
+	Beta  float64 `help:"Chance of accepting H0 when H1 holds" default:"0.05"`
+	Stop  bool    `help:"Stop as soon as the SPRT decides" default:"true" negatable:""`
+
+	Inference  string `help:"Backend running the models: ${enum}" enum:"native,onnxruntime" default:"onnxruntime"`
+	OrtLibPath string `name:"ort-lib" help:"Path to the ONNX Runtime shared library" env:"ORT_LIB_PATH"`
+}
+
+func main() {
+	ctx := kong.Parse(&cli,
+		kong.Name("arena"),
+		kong.Description("Play a match between two bots and estimate their Elo difference."),
+		kong.UsageOnError(),
+	)
+
+	evals := &evaluators{batchers: make(map[string]*bot.Batcher)}
+	defer evals.close()
+
+	a, err := evals.mover(cli.A)
+	ctx.FatalIfErrorf(err)
+	b, err := evals.mover(cli.B)
+	ctx.FatalIfErrorf(err)
+
+	m := match{
+		bots:         [2]player.Mover{a, b},
+		games:        cli.Games,
+		parallel:     cli.Parallel,
+		openingPlies: cli.OpeningPlies,
+		seed:         cli.Seed,
+		test:         sprt{elo0: cli.Elo0, elo1: cli.Elo1, alpha: cli.Alpha, beta: cli.Beta},
+		stop:         cli.Stop,
+		progress:     os.Stderr,
+	}
+	start := time.Now()
+	result, err := m.run()
+	ctx.FatalIfErrorf(err)
+
+	report(result, m.test, time.Since(start))
+}
+
+// evaluators loads every model once, for all bots using it.
+type evaluators struct {
+	batchers map[string]*bot.Batcher
+	ort      bool
+}
+
+func (e *evaluators) mover(spec botSpec) (player.Mover, error) {
+	switch {
+	case spec.Depth > 0:
+		return search.New(spec.Depth), nil
+	case spec.Rollouts > 0:
+		return bot.NewWithEvaluator(bot.NewRolloutEvaluator(spec.Rollouts, cli.Seed), spec.Options), nil
+	}
+
+	batcher, err := e.batcher(spec.Model)
+	if err != nil {
+		return nil, err
+	}
+	return bot.NewWithEvaluator(batcher, spec.Options), nil
+}
+
+func (e *evaluators) batcher(path string) (*bot.Batcher, error) {
+	if b, ok := e.batchers[path]; ok {
+		return b, nil
+	}
+
+	var (
+		b   *bot.Batcher
+		err error
+	)
+	if cli.Inference == inferenceNative {
+		b, err = bot.NewNativeBatcher(path, bot.BatchOptions{})
+	} else {
+		if err := e.initORT(); err != nil {
+			return nil, err
+		}
+		b, err = bot.NewBatcher(path, bot.BatchOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	e.batchers[path] = b
+	return b, nil
+}
+
+func (e *evaluators) initORT() error {
+	if e.ort {
+		return nil
+	}
+	if cli.OrtLibPath == "" {
+		return fmt.Errorf("--ort-lib or ORT_LIB_PATH is needed with --inference=%s", inferenceONNXRuntime)
+	}
+
+	ort.SetSharedLibraryPath(cli.OrtLibPath)
+	if err := ort.InitializeEnvironment(); err != nil {
+		return fmt.Errorf("initialize ONNX Runtime: %w", err)
+	}
+	e.ort = true
+	return nil
+}
+
+func (e *evaluators) close() {
+	for _, b := range e.batchers {
+		b.Close()
+	}
+	if e.ort {
+		ort.DestroyEnvironment()
+	}
+}
+
+// match is a series of games between two bots.
+type match struct {
+	bots         [2]player.Mover
+	games        int
+	parallel     int
+	openingPlies int
+	seed         uint64
+	test         sprt
+	stop         bool
+	// progress, if set, gets the running score after every game.
+	progress io.Writer
+}
+
+// matchResult is the outcome of a match, from the first bot's side.
+type matchResult struct {
+	total score
+	// byColor splits the total by the first bot's colour.
+	byColor [engine.ColorCount]score
+}
+
+// run plays the games, parallel of them at once. Game i starts from
+// opening i/2, the first bot playing white in the even games. With stop
+// set no game is started once the SPRT has decided; the games under way
+// are finished and counted.
+func (m match) run() (matchResult, error) {
+	if m.games < 1 {
+		return matchResult{}, fmt.Errorf("games must be positive, got %d", m.games)
+	}
+	parallel := m.parallel
+	if parallel <= 0 {
+		parallel = runtime.GOMAXPROCS(0)
+	}
+	openings, err := m.openings((m.games + 1) / 2)
+	if err != nil {
+		return matchResult{}, err
+	}
+
+	queue := make(chan int, m.games)
+	for i := range m.games {
+		queue <- i
+	}
+	close(queue)
+
+	var (
+		mu       sync.Mutex
+		result   matchResult
+		decided  bool
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for range min(parallel, m.games) {
+		wg.Go(func() {
+			for i := range queue {
+				mu.Lock()
+				done := firstErr != nil || (m.stop && decided)
+				mu.Unlock()
+				if done {
+					return
+				}
+
+				color := engine.Color(i % 2)
+				points, err := m.play(openings[i/2], color)
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+				} else {
+					result.add(color, points)
+					v, llr := m.test.test(result.total)
+					decided = v != undecided
+					if m.progress != nil {
+						fmt.Fprintf(m.progress, "\r%d games: +%d =%d -%d, LLR %.2f",
+							result.total.games(), result.total.wins, result.total.draws, result.total.losses, llr)
+					}
+				}
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	if m.progress != nil {
+		fmt.Fprintln(m.progress)
+	}
+
+	return result, firstErr
+}
+
+func (r *matchResult) add(color engine.Color, points float64) {
+	for _, s := range []*score{&r.total, &r.byColor[color]} {
+		switch points {
+		case 1:
+			s.wins++
+		case 0:
+			s.losses++
+		default:
+			s.draws++
+		}
+	}
+}
+
+// play plays a game from the given opening, the first bot playing color,
+// and returns its points: 1 for a win, 1/2 for a draw, 0 for a loss.
+func (m match) play(opening *engine.Game, color engine.Color) (float64, error) {
+	g := opening.Clone()
+
+	var movers [engine.ColorCount]player.Mover
+	movers[color], movers[1-color] = m.bots[0], m.bots[1]
+
+	for g.Status != engine.GameOver {
+		piece, cell, err := movers[g.Turn].SelectAction(g)
+		if err != nil {
+			return 0, err
+		}
+		if err := g.Move(piece, cell); err != nil {
+			return 0, fmt.Errorf("bot %d played %v to %v: %w", botIndex(g.Turn, color), piece, cell, err)
+		}
+	}
+
+	switch {
+	case g.Winner == nil:
+		return 0.5, nil
+	case *g.Winner == color:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// botIndex returns the number, 1 or 2, of the bot playing turn.
+func botIndex(turn, first engine.Color) int {
+	if turn == first {
+		return 1
+	}
+	return 2
+}
+
+// openings returns the openings of the first n pairs of games. An opening
+// symmetric to an earlier one, mirrored or with the colours swapped, would
+// have the bots play the same games again, so it is drawn anew, see
+// engine.Position.CanonicalKey.
+func (m match) openings(n int) ([]*engine.Game, error) {
+	openings := make([]*engine.Game, n)
+	seen := make(map[engine.Key]bool)
+	for i := range openings {
+		rng := rand.New(rand.NewPCG(m.seed, uint64(i)))
+		for draw := 1; ; draw++ {
+			g, err := m.opening(rng)
+			if err != nil {
+				return nil, err
+			}
+
+			p := g.Position()
+			key, _ := p.CanonicalKey()
+			if !seen[key] || draw == openingDraws {
+				seen[key] = true
+				openings[i] = g
+				break
+			}
+		}
+	}
+
+	return openings, nil
+}
+
+// opening returns a new game after the random plies of an opening.
+// It plays no move that ends the game.
+func (m match) opening(rng *rand.Rand) (*engine.Game, error) {
+	g := engine.NewGame()
+
+	for range m.openingPlies {
+		var quiet []engine.Move
+		for _, move := range g.AllLegalMoves() {
+			c := g.Clone()
+			if err := c.Play(move); err == nil && c.Status != engine.GameOver {
+				quiet = append(quiet, move)
+			}
+		}
+		if len(quiet) == 0 {
+			break
+		}
+		if err := g.Play(quiet[rng.IntN(len(quiet))]); err != nil {
+			return nil, err
+		}
+	}
+
+	return g, nil
+}
+
+func report(r matchResult, test sprt, elapsed time.Duration) {
+	fmt.Fprintf(os.Stdout, "A: %s\n", cli.A.Name)
+	fmt.Fprintf(os.Stdout, "B: %s\n", cli.B.Name)
+	fmt.Fprintln(os.Stdout)
+
+	t := r.total
+	fmt.Fprintf(os.Stdout, "Games: %d in %v\n", t.games(), elapsed.Round(time.Second))
+	fmt.Fprintf(os.Stdout, "A wins %d, draws %d, loses %d: score %.1f%%\n", t.wins, t.draws, t.losses, 100*t.mean())
+	for _, color := range []engine.Color{engine.White, engine.Black} {
+		s := r.byColor[color]
+		if s.games() > 0 {
+			fmt.Fprintf(os.Stdout, "  as %s: +%d =%d -%d\n", colorNames[color], s.wins, s.draws, s.losses)
+		}
+	}
+
+	diff, margin := t.elo()
+	switch {
+	case math.IsInf(diff, 0):
+		fmt.Fprintf(os.Stdout, "Elo: %+.0f\n", diff)
+	case math.IsInf(margin, 0):
+		fmt.Fprintf(os.Stdout, "Elo: %+.1f, 95%% interval unbounded\n", diff)
+	default:
+		fmt.Fprintf(os.Stdout, "Elo: %+.1f ± %.1f (95%%)\n", diff, margin)
+	}
+
+	v, llr := test.test(t)
+	lower, upper := test.bounds()
+	fmt.Fprintf(os.Stdout, "SPRT elo0=%g elo1=%g alpha=%g beta=%g: LLR %.2f (%.2f, %.2f), %v\n",
+		test.elo0, test.elo1, test.alpha, test.beta, llr, lower, upper, v)
+}
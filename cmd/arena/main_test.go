@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/bot/player"
+	"tic-tac-chec/internal/bot/search"
+)
+
+func TestMatch(t *testing.T) {
+	m := match{
+		bots:         [2]player.Mover{search.New(1), search.New(2)},
+		games:        10,
+		parallel:     3,
+		openingPlies: 2,
+		test:         sprt{elo0: 0, elo1: 10, alpha: 0.05, beta: 0.05},
+	}
+
+	result, err := m.run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.total.games(); got != 10 {
+		t.Fatalf("expected 10 games, got %d", got)
+	}
+	for _, color := range []engine.Color{engine.White, engine.Black} {
+		if got := result.byColor[color].games(); got != 5 {
+			t.Errorf("expected 5 games as %s, got %d", colorNames[color], got)
+		}
+	}
+}
+
+func TestMatchStops(t *testing.T) {
+	m := match{
+		bots:     [2]player.Mover{search.New(1), search.New(4)},
+		games:    400,
+		parallel: 1,
+		// lenient hypotheses, so that the weaker bot loses the test early
+		test: sprt{elo0: 0, elo1: 200, alpha: 0.2, beta: 0.2},
+		stop: true,
+	}
+
+	result, err := m.run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, llr := m.test.test(result.total); v != acceptH0 {
+		t.Fatalf("expected H0 to be accepted, got %v with LLR %.2f", v, llr)
+	}
+	if result.total.games() == m.games {
+		t.Errorf("expected the match to stop early, played all %d games", m.games)
+	}
+}
+
+func TestOpenings(t *testing.T) {
+	m := match{openingPlies: 2, seed: 3}
+
+	first, err := m.openings(200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.openings(200)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[engine.Key]int)
+	for i, g := range first {
+		if len(g.History) != 2 || g.Status == engine.GameOver {
+			t.Fatalf("expected 2 plies of a game under way, got %d plies, status %v", len(g.History), g.Status)
+		}
+		if g.Key() != second[i].Key() {
+			t.Errorf("expected opening %d to be the same for the same seed", i)
+		}
+
+		p := g.Position()
+		key, _ := p.CanonicalKey()
+		if j, ok := seen[key]; ok {
+			t.Errorf("expected distinct openings, %d is symmetric to %d", i, j)
+		}
+		seen[key] = i
+	}
+}
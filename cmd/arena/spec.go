@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tic-tac-chec/internal/bot"
+)
+
+// botSpec is a bot configuration given on the command line as
+// comma-separated key=value pairs:
+//
+//	search=DEPTH      the pure-Go alpha-beta searcher, see package search
+//	model=PATH        an ONNX model, greedy unless it searches, see below
+//	rollouts=N        MCTS judging positions by N random games, no model
+//	sims=N            MCTS simulations per move
+//	think=DURATION    MCTS think time per move, e.g. 500ms
+//	workers=N         MCTS goroutines
+//	temp=F            temperature of the move choice
+//	blunder=F         chance of playing one of the next best moves
+//
+// For example "model=bot/models/bot_hard.onnx,sims=400" or "search=4".
+type botSpec struct {
+	// Name is the spec as given.
+	Name string
+
+	Depth    int
+	Model    string
+	Rollouts int
+	Options  bot.SearchOptions
+}
+
+func (s *botSpec) UnmarshalText(text []byte) error {
+	*s = botSpec{Name: string(text)}
+
+	for pair := range strings.SplitSeq(s.Name, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("bot %q: expected key=value, got %q", s.Name, pair)
+		}
+
+		var err error
+		switch key {
+		case "search":
+			s.Depth, err = strconv.Atoi(value)
+		case "model":
+			s.Model = value
+		case "rollouts":
+			s.Rollouts, err = strconv.Atoi(value)
+		case "sims":
+			s.Options.Simulations, err = strconv.Atoi(value)
+		case "think":
+			s.Options.ThinkTime, err = time.ParseDuration(value)
+		case "workers":
+			s.Options.Workers, err = strconv.Atoi(value)
+		case "temp":
+			s.Options.Temperature, err = strconv.ParseFloat(value, 64)
+		case "blunder":
+			s.Options.BlunderChance, err = strconv.ParseFloat(value, 64)
+		default:
+			return fmt.Errorf("bot %q: unknown key %q", s.Name, key)
+		}
+		if err != nil {
+			return fmt.Errorf("bot %q: %s: %w", s.Name, key, err)
+		}
+	}
+
+	kinds := 0
+	for _, set := range []bool{s.Depth > 0, s.Model != "", s.Rollouts > 0} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("bot %q: expected exactly one of search, model and rollouts", s.Name)
+	}
+	if s.Rollouts > 0 && s.Options.Simulations <= 0 && s.Options.ThinkTime <= 0 {
+		return fmt.Errorf("bot %q: rollouts need sims or think", s.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBotSpec(t *testing.T) {
+	var s botSpec
+	if err := s.UnmarshalText([]byte("model=bot/models/bot_hard.onnx,sims=400,think=500ms,workers=4,temp=0.5,blunder=0.1")); err != nil {
+		t.Fatal(err)
+	}
+	if s.Model != "bot/models/bot_hard.onnx" {
+		t.Errorf("expected the model path, got %q", s.Model)
+	}
+	o := s.Options
+	if o.Simulations != 400 || o.ThinkTime != 500*time.Millisecond || o.Workers != 4 || o.Temperature != 0.5 || o.BlunderChance != 0.1 {
+		t.Errorf("expected the search options of the spec, got %+v", o)
+	}
+
+	if err := s.UnmarshalText([]byte("search=4")); err != nil {
+		t.Fatal(err)
+	}
+	if s.Depth != 4 || s.Model != "" {
+		t.Errorf("expected a depth 4 searcher alone, got %+v", s)
+	}
+}
+
+func TestBotSpecErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"search",
+		"search=deep",
+		"search=4,model=bot.onnx",
+		"sims=400",
+		"rollouts=8",
+		"model=bot.onnx,depth=3",
+	} {
+		var s botSpec
+		if err := s.UnmarshalText([]byte(spec)); err == nil {
+			t.Errorf("%q: expected an error, got %+v", spec, s)
+		}
+	}
+}
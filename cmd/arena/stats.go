@@ -0,0 +1,124 @@
+package main
+
+import (
+	"math"
+)
+
+// score is the tally of a match from the first bot's side.
+type score struct {
+	wins, draws, losses int
+}
+
+func (s score) games() int {
+	return s.wins + s.draws + s.losses
+}
+
+// mean returns the points per game, a win counting 1 and a draw 1/2.
+func (s score) mean() float64 {
+	return (float64(s.wins) + float64(s.draws)/2) / float64(s.games())
+}
+
+// variance returns the variance of the points of a single game.
+func (s score) variance() float64 {
+	m := s.mean()
+	n := float64(s.games())
+	return (float64(s.wins)*(1-m)*(1-m) + float64(s.draws)*(0.5-m)*(0.5-m) + float64(s.losses)*m*m) / n
+}
+
+// elo returns the rating difference that expects the score, and the half
+// width of its 95% confidence interval. A score of 0 or 1 has no finite
+// difference; it is reported as ±Inf with an infinite margin.
+func (s score) elo() (diff, margin float64) {
+	m := s.mean()
+	diff = eloOf(m)
+
+	// the interval of the mean score, mapped to Elo
+	half := 1.96 * math.Sqrt(s.variance()/float64(s.games()))
+	low, high := eloOf(m-half), eloOf(m+half)
+	margin = (high - low) / 2
+	if math.IsNaN(margin) {
+		margin = math.Inf(1)
+	}
+	return diff, margin
+}
+
+// eloOf returns the rating difference whose expected score is m.
+func eloOf(m float64) float64 {
+	switch {
+	case m <= 0:
+		return math.Inf(-1)
+	case m >= 1:
+		return math.Inf(1)
+	}
+	return -400 * math.Log10(1/m-1)
+}
+
+// expectedScore returns the expected score of a rating difference.
+func expectedScore(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// sprt is a sequential probability ratio test of H0, the first bot is
+// elo0 stronger, against H1, it is elo1 stronger, with false positive and
+// false negative rates alpha and beta.
+type sprt struct {
+	elo0, elo1  float64
+	alpha, beta float64
+}
+
+// verdict is the outcome of an SPRT.
+type verdict int
+
+const (
+	undecided verdict = iota
+	acceptH0
+	acceptH1
+)
+
+func (v verdict) String() string {
+	switch v {
+	case acceptH0:
+		return "H0 accepted"
+	case acceptH1:
+		return "H1 accepted"
+	}
+	return "undecided"
+}
+
+// bounds returns the log-likelihood ratios below which H0 and above which
+// H1 is accepted.
+func (t sprt) bounds() (lower, upper float64) {
+	return math.Log(t.beta / (1 - t.alpha)), math.Log((1 - t.beta) / t.alpha)
+}
+
+// llr returns the log-likelihood ratio of H1 to H0 for the score, in the
+// normal approximation of the game results used by chess engine testing:
+// the mean score is taken to follow a normal law of the observed variance.
+func (t sprt) llr(s score) float64 {
+	if s.games() == 0 {
+		return 0
+	}
+	if s.variance() == 0 {
+		// every game ended alike, which would make any difference from the
+		// hypotheses infinitely significant: count one more win and loss
+		s.wins++
+		s.losses++
+	}
+	variance := s.variance()
+
+	s0, s1 := expectedScore(t.elo0), expectedScore(t.elo1)
+	return float64(s.games()) * (s1 - s0) * (2*s.mean() - s0 - s1) / (2 * variance)
+}
+
+// test returns the verdict on the score and its log-likelihood ratio.
+func (t sprt) test(s score) (verdict, float64) {
+	llr := t.llr(s)
+	lower, upper := t.bounds()
+	switch {
+	case llr <= lower:
+		return acceptH0, llr
+	case llr >= upper:
+		return acceptH1, llr
+	}
+	return undecided, llr
+}
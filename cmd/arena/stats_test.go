@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestElo(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		score        score
+		diff, margin float64
+	}{
+		{"even", score{wins: 40, draws: 20, losses: 40}, 0, 61.5},
+		{"ahead", score{wins: 60, draws: 20, losses: 20}, 147.2, 66.0},
+		{"behind", score{wins: 20, draws: 20, losses: 60}, -147.2, 66.0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, margin := tt.score.elo()
+			if math.Abs(diff-tt.diff) > 0.1 || math.Abs(margin-tt.margin) > 0.1 {
+				t.Errorf("expected %+.1f ± %.1f, got %+.1f ± %.1f", tt.diff, tt.margin, diff, margin)
+			}
+		})
+	}
+
+	diff, margin := score{wins: 5}.elo()
+	if !math.IsInf(diff, 1) || !math.IsInf(margin, 1) {
+		t.Errorf("expected a clean sweep to be +Inf ± Inf, got %v ± %v", diff, margin)
+	}
+}
+
+func TestSPRT(t *testing.T) {
+	test := sprt{elo0: 0, elo1: 10, alpha: 0.05, beta: 0.05}
+
+	lower, upper := test.bounds()
+	if math.Abs(lower+2.944) > 0.001 || math.Abs(upper-2.944) > 0.001 {
+		t.Errorf("expected bounds of ±2.944, got %.3f and %.3f", lower, upper)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		score score
+		want  verdict
+	}{
+		{"stronger", score{wins: 600, draws: 200, losses: 400}, acceptH1},
+		{"weaker", score{wins: 400, draws: 200, losses: 600}, acceptH0},
+		{"too few games", score{wins: 6, draws: 2, losses: 4}, undecided},
+		{"every game lost", score{losses: 40}, acceptH0},
+		{"every game drawn", score{draws: 400}, acceptH0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, llr := test.test(tt.score); got != tt.want {
+				t.Errorf("expected %v, got %v with LLR %.2f", tt.want, got, llr)
+			}
+		})
+	}
+}
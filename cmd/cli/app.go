@@ -1,11 +1,14 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"tic-tac-chec/engine"
 	"tic-tac-chec/internal/display"
 	"tic-tac-chec/internal/parse"
+	"tic-tac-chec/internal/record"
+	"time"
 )
 
 type App struct {
@@ -17,7 +20,17 @@ func NewApp(out io.Writer, err io.Writer) *App {
 	return &App{out: out, err: err}
 }
 
-func (a *App) Start(gameState string) (string, error) {
+// Start begins a new game under the given rules (standard if empty),
+// or sets up the position given as FEN, which carries its own rules.
+func (a *App) Start(gameState string, fen string, rules string) (string, error) {
+	if fen != "" && rules != "" {
+		return "", errors.New("--rules can not be combined with --fen, the FEN names its rules")
+	}
+	r, err := engine.ParseRules(rules)
+	if err != nil {
+		return "", err
+	}
+
 	if gameState == "" {
 		path, err := createGameStateFile()
 		if err != nil {
@@ -27,9 +40,14 @@ func (a *App) Start(gameState string) (string, error) {
 		gameState = path
 	}
 
-	game := engine.NewGame()
-	err := writeGameState(game, gameState)
-	if err != nil {
+	game := engine.NewGame(r)
+	if fen != "" {
+		if game, err = engine.ParseFEN(fen); err != nil {
+			return "", err
+		}
+	}
+
+	if err = writeGameState(game, gameState); err != nil {
 		return "", err
 	}
 
@@ -51,7 +69,7 @@ func (a *App) Move(gameState string, pieceArg string, squareArg string) error {
 		return err
 	}
 
-	square, err := parse.Square(squareArg)
+	square, err := parse.SquareOn(squareArg, game.Rules.Size())
 	if err != nil {
 		return err
 	}
@@ -69,3 +87,43 @@ func (a *App) Move(gameState string, pieceArg string, squareArg string) error {
 
 	return nil
 }
+
+func (a *App) Undo(gameState string) error {
+	return a.step(gameState, (*engine.Game).Undo, "Move taken back")
+}
+
+func (a *App) Redo(gameState string) error {
+	return a.step(gameState, (*engine.Game).Redo, "Move replayed")
+}
+
+// Record prints the game so far in game record notation.
+func (a *App) Record(gameState string) error {
+	game, err := restoreGame(gameState)
+	if err != nil {
+		return err
+	}
+
+	return record.Encode(a.out, record.FromGame(game,
+		record.Tag{Name: record.TagDate, Value: time.Now().Format(record.DateFormat)},
+	))
+}
+
+func (a *App) step(gameState string, step func(*engine.Game) error, message string) error {
+	game, err := restoreGame(gameState)
+	if err != nil {
+		return err
+	}
+
+	if err = step(game); err != nil {
+		return err
+	}
+
+	if err = writeGameState(game, gameState); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(a.out, message)
+	display.PrintGame(a.out, game)
+
+	return nil
+}
@@ -28,8 +28,7 @@ func restoreGame(path string) (*engine.Game, error) {
 		return nil, err
 	}
 
-	game := wire.GameFromState(state)
-	return game, nil
+	return wire.GameFromState(state)
 }
 
 func writeGameState(game *engine.Game, path string) error {
@@ -10,14 +10,26 @@ var cli struct {
 	Game string `help:"Path to the current state of the game" short:"g" optional:""`
 	Start     StartCmd `cmd:"" help:"Start a new game"`
 	Move      MoveCmd  `cmd:"" help:"Move a piece"`
+	Undo      UndoCmd  `cmd:"" help:"Take back the last move"`
+	Redo      RedoCmd  `cmd:"" help:"Replay the last move taken back"`
+	Record    RecordCmd `cmd:"" help:"Print the game record (tags and moves)"`
 }
 
 type MoveCmd struct {
-	Piece  string `arg:"" help:"Piece to move: WR, WN, WK, WB, WP, BR, BP, BN, BK, BB"`
-	Square string `arg:"" help:"Square to move to: a1, b2, c3, d4"`
+	Piece  string `arg:"" help:"Piece to move: WR, WN, WK, WB, WP, WQ, BR, BP, BN, BK, BB, BQ"`
+	Square string `arg:"" help:"Square to move to: a1, b2, c3, d4 (e5 on a 5x5 board)"`
 }
 
-type StartCmd struct{}
+type StartCmd struct {
+	FEN   string `name:"fen" help:"Start from a position in FEN notation, e.g. \"4/4/4/4 PRBNprbn w ud 0 0\""`
+	Rules string `name:"rules" help:"Play a variant, e.g. \"5x5-4+queen+discard+keeppawn\": board size, win length, Queen, discarded captures, pawns that keep their direction"`
+}
+
+type UndoCmd struct{}
+
+type RedoCmd struct{}
+
+type RecordCmd struct{}
 
 func main() {
 	app := NewApp(os.Stdout, os.Stderr)
@@ -30,9 +42,15 @@ func main() {
 	var err error
 	switch ctx.Command() {
 	case "start":
-		_, err = app.Start(cli.Game)
+		_, err = app.Start(cli.Game, cli.Start.FEN, cli.Start.Rules)
 	case "move <piece> <square>":
 		err = app.Move(cli.Game, cli.Move.Piece, cli.Move.Square)
+	case "undo":
+		err = app.Undo(cli.Game)
+	case "redo":
+		err = app.Redo(cli.Game)
+	case "record":
+		err = app.Record(cli.Game)
 	default:
 		ctx.Fatalf("unknown command: %s", ctx.Command())
 	}
@@ -41,9 +59,9 @@ func main() {
 }
 
 func (MoveCmd) Help() string {
-	return `White: WP=Pawn  WR=Rook  WN/WK=Knight  WB=Bishop
+	return `White: WP=Pawn  WR=Rook  WN/WK=Knight  WB=Bishop  WQ=Queen (variants only)
 
-Black: BP=Pawn  BR=Rook  BN/BK=Knight  BB=Bishop
+Black: BP=Pawn  BR=Rook  BN/BK=Knight  BB=Bishop  BQ=Queen (variants only)
 
-Square: a1 .. d4`
+Square: a1 .. d4, or a1 .. e5 on a 5x5 board`
 }
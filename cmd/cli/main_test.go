@@ -1,9 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"io"
 	"os"
+	"strings"
 	"testing"
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/record"
 )
 
 func TestGameIsPlayable(t *testing.T) {
@@ -16,7 +21,7 @@ func TestGameIsPlayable(t *testing.T) {
 
 	path := f.Name()
 	app := NewApp(io.Discard, io.Discard)
-	_, err = app.Start(path)
+	_, err = app.Start(path, "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -31,3 +36,138 @@ func TestGameIsPlayable(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestUndoRedo(t *testing.T) {
+	f, err := os.CreateTemp("", "tic-tac-chec-test-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	path := f.Name()
+	app := NewApp(io.Discard, io.Discard)
+	if _, err = app.Start(path, "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.Move(path, "wp", "a1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.Undo(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.Undo(path); err == nil {
+		t.Fatal("expected error when there is nothing to undo")
+	}
+
+	if err = app.Redo(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// white pawn is back on a1, so it's black's turn
+	if err = app.Move(path, "br", "b1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStartFromFEN(t *testing.T) {
+	f, err := os.CreateTemp("", "tic-tac-chec-test-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	path := f.Name()
+	app := NewApp(io.Discard, io.Discard)
+	if _, err = app.Start(path, "R3/4/4/3r PBNpbn w ud 0 2", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.Move(path, "wr", "a1"); err != nil {
+		t.Fatal(err)
+	}
+
+	game, err := restoreGame(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := engine.FormatFEN(game), "4/4/4/R2r PBNpbn b ud 1 3"; got != want {
+		t.Errorf("FEN = %q, want %q", got, want)
+	}
+
+	if _, err = app.Start(path, "not a position", ""); !errors.Is(err, engine.ErrInvalidFEN) {
+		t.Errorf("expected ErrInvalidFEN, got %v", err)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	f, err := os.CreateTemp("", "tic-tac-chec-test-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	path := f.Name()
+	var out bytes.Buffer
+	app := NewApp(&out, io.Discard)
+	if _, err = app.Start(path, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err = app.Move(path, "wn", "b2"); err != nil {
+		t.Fatal(err)
+	}
+	if err = app.Move(path, "bp", "c3"); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	if err = app.Record(path); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := record.Parse(out.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(r.Moves, " "), "N@b2 P@c3"; got != want {
+		t.Errorf("moves = %q, want %q", got, want)
+	}
+	if _, err = record.Replay(r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStartVariant(t *testing.T) {
+	f, err := os.CreateTemp("", "tic-tac-chec-test-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	path := f.Name()
+	app := NewApp(io.Discard, io.Discard)
+	if _, err = app.Start(path, "", "5x5-4+queen"); err != nil {
+		t.Fatal(err)
+	}
+	if err = app.Move(path, "wq", "e5"); err != nil {
+		t.Fatal(err)
+	}
+
+	game, err := restoreGame(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := engine.FormatFEN(game), "4Q/5/5/5/5 PRBNprbnq b ud 0 1 5x5-4+queen"; got != want {
+		t.Errorf("FEN = %q, want %q", got, want)
+	}
+
+	if _, err = app.Start(path, "", "6x6"); !errors.Is(err, engine.ErrInvalidRules) {
+		t.Errorf("expected ErrInvalidRules, got %v", err)
+	}
+}
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"encoding/json"
 	"os"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"tic-tac-chec/engine"
 )
 
+// ignoreKey skips the Zobrist key the engine caches in Game: it is not
+// saved and is computed again on first use.
+var ignoreKey = cmpopts.IgnoreUnexported(engine.Game{})
+
 func TestGameStateRoundTrip(t *testing.T) {
 	game := engine.NewGame()
 	game.Move(engine.WhitePawn, engine.Cell{Row: 0, Col: 0})
@@ -32,9 +38,109 @@ func TestGameStateRoundTrip(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	diff := cmp.Diff(restoredGame, game)
+	diff := cmp.Diff(restoredGame, game, ignoreKey)
 
 	if diff != "" {
 		t.Errorf("restored game does not match original: %s", diff)
 	}
 }
+
+func TestGameStateRoundTripKeepsHistory(t *testing.T) {
+	game := engine.NewGame()
+	game.Move(engine.WhiteBishop, engine.Cell{Row: 1, Col: 1})
+	game.Move(engine.BlackBishop, engine.Cell{Row: 2, Col: 2})
+	game.Move(engine.WhiteBishop, engine.Cell{Row: 2, Col: 2}) // capture
+	game.Move(engine.BlackPawn, engine.Cell{Row: 0, Col: 0})
+	game.Undo()
+
+	file, err := os.CreateTemp("", "*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	if err = writeGameState(game, file.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	restoredGame, err := restoreGame(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(restoredGame, game, ignoreKey); diff != "" {
+		t.Errorf("restored game does not match original: %s", diff)
+	}
+
+	if err = restoredGame.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if !restoredGame.PieceOnBoard(engine.BlackBishop) {
+		t.Error("expected undo of the capture to put the black bishop back")
+	}
+}
+
+func TestRestoreGameWithoutNoProgressLimit(t *testing.T) {
+	game := engine.NewGame()
+	game.Move(engine.WhitePawn, engine.Cell{Row: 0, Col: 0})
+
+	file, err := os.CreateTemp("", "*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	if err := writeGameState(game, file.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	// a state saved before the no-progress rule has no noProgressLimit
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state map[string]any
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	delete(state, "noProgressLimit")
+	if data, err = json.Marshal(state); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file.Name(), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	restoredGame, err := restoreGame(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restoredGame.NoProgressLimit != engine.DefaultNoProgressLimit {
+		t.Errorf("NoProgressLimit = %d, want %d", restoredGame.NoProgressLimit, engine.DefaultNoProgressLimit)
+	}
+}
+
+func TestGameStateRoundTripKeepsDisabledNoProgressLimit(t *testing.T) {
+	game := engine.NewGame()
+	game.NoProgressLimit = 0
+
+	file, err := os.CreateTemp("", "*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	if err := writeGameState(game, file.Name()); err != nil {
+		t.Fatal(err)
+	}
+	restoredGame, err := restoreGame(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restoredGame.NoProgressLimit != 0 {
+		t.Errorf("NoProgressLimit = %d, want 0", restoredGame.NoProgressLimit)
+	}
+}
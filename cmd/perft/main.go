@@ -0,0 +1,57 @@
+// Command perft counts move paths from a position, to check the move
+// generator against the reference counts in engine/testdata/perft.txt.
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"tic-tac-chec/engine"
+
+	"github.com/alecthomas/kong"
+)
+
+var cli struct {
+	FEN    string `name:"fen" help:"Position to start from" default:"4/4/4/4 PRBNprbn w ud 0 0"`
+	Depth  int    `arg:"" help:"Number of moves to look ahead"`
+	Divide bool   `help:"Print the count below each first move"`
+}
+
+func main() {
+	ctx := kong.Parse(&cli,
+		kong.Name("perft"),
+		kong.Description("Count the move paths of a given depth from a position."),
+		kong.UsageOnError(),
+	)
+
+	game, err := engine.ParseFEN(cli.FEN)
+	ctx.FatalIfErrorf(err)
+
+	ctx.FatalIfErrorf(run(game.Position(), cli.Depth, cli.Divide))
+}
+
+func run(position engine.Position, depth int, divide bool) error {
+	if depth < 0 {
+		return fmt.Errorf("depth must not be negative, got %d", depth)
+	}
+
+	start := time.Now()
+
+	var nodes uint64
+	if divide {
+		for _, count := range engine.PerftDivide(position, depth) {
+			fmt.Fprintf(os.Stdout, "%-8v %d\n", count.Move.Name(position.Rules.Size()), count.Nodes)
+			nodes += count.Nodes
+		}
+		fmt.Fprintln(os.Stdout)
+	} else {
+		nodes = engine.Perft(position, depth)
+	}
+
+	elapsed := time.Since(start)
+	fmt.Fprintf(os.Stdout, "Nodes: %d\n", nodes)
+	fmt.Fprintf(os.Stdout, "Time: %v (%.0f nodes/s)\n", elapsed.Round(time.Millisecond), float64(nodes)/elapsed.Seconds())
+
+	return nil
+}
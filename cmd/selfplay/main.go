@@ -0,0 +1,201 @@
+// Command selfplay plays MCTS games of a model against itself with the Go
+// engine and writes the positions as training data for bot/training: the
+// encoded state, the visit distribution of the search and the outcome,
+// in the .npz layout of its ReplayBuffer.
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/bot"
+
+	"github.com/alecthomas/kong"
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+const (
+	inferenceNative      = "native"
+	inferenceONNXRuntime = "onnxruntime"
+)
+
+var cli struct {
+	Output string `arg:"" help:"File to write the examples to, e.g. selfplay.npz" type:"path"`
+
+	Model      string `help:"ONNX model to search with; without one, positions are judged by random rollouts" type:"existingfile"`
+	Inference  string `help:"Backend running the model: ${enum}" enum:"native,onnxruntime" default:"native"`
+	OrtLibPath string `name:"ort-lib" help:"Path to the ONNX Runtime shared library" env:"ORT_LIB_PATH"`
+	Rollouts   int    `help:"Random rollouts per position without a model" default:"8"`
+	Seed       uint64 `help:"Seed of the rollouts"`
+
+	Games       int `help:"Number of games to play" default:"100"`
+	Parallel    int `help:"Games played at once, GOMAXPROCS if 0"`
+	Simulations int `help:"MCTS simulations per move" default:"200"`
+	Workers     int `help:"MCTS goroutines per game" default:"1"`
+
+	SampleMoves      int     `help:"Opening plies whose moves are sampled by visits" default:"8"`
+	FinalTemperature float64 `help:"Temperature of the visits after the opening, 0 for the most visited" default:"0.1"`
+	DirichletAlpha   float64 `help:"Concentration of the root noise, 0 for none" default:"0.3"`
+	DirichletWeight  float64 `help:"Share of the root noise in the priors" default:"0.25"`
+}
+
+func main() {
+	ctx := kong.Parse(&cli,
+		kong.Name("selfplay"),
+		kong.Description("Play MCTS self-play games and write them as training data."),
+		kong.UsageOnError(),
+	)
+
+	eval, closeEval, err := newEvaluator()
+	ctx.FatalIfErrorf(err)
+	defer closeEval()
+
+	model := bot.NewWithEvaluator(eval, bot.SearchOptions{
+		Workers:         cli.Workers,
+		DirichletAlpha:  cli.DirichletAlpha,
+		DirichletWeight: cli.DirichletWeight,
+	})
+	opts := bot.SelfPlayOptions{
+		Simulations:      cli.Simulations,
+		SampleMoves:      cli.SampleMoves,
+		FinalTemperature: cli.FinalTemperature,
+	}
+
+	ctx.FatalIfErrorf(run(model, opts, cli.Games, cli.Parallel, cli.Output))
+}
+
+// newEvaluator returns the evaluator the flags ask for and the function
+// that releases it.
+func newEvaluator() (bot.Evaluator, func(), error) {
+	if cli.Model == "" {
+		return bot.NewRolloutEvaluator(cli.Rollouts, cli.Seed), func() {}, nil
+	}
+
+	if cli.Inference == inferenceNative {
+		batcher, err := bot.NewNativeBatcher(cli.Model, bot.BatchOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return batcher, batcher.Close, nil
+	}
+
+	if cli.OrtLibPath == "" {
+		return nil, nil, fmt.Errorf("--ort-lib or ORT_LIB_PATH is needed with --inference=%s", inferenceONNXRuntime)
+	}
+	ort.SetSharedLibraryPath(cli.OrtLibPath)
+	if err := ort.InitializeEnvironment(); err != nil {
+		return nil, nil, fmt.Errorf("initialize ONNX Runtime: %w", err)
+	}
+
+	batcher, err := bot.NewBatcher(cli.Model, bot.BatchOptions{})
+	if err != nil {
+		ort.DestroyEnvironment()
+		return nil, nil, err
+	}
+	return batcher, func() {
+		batcher.Close()
+		ort.DestroyEnvironment()
+	}, nil
+}
+
+// tally counts the results of the games played so far.
+type tally struct {
+	games, positions int
+	wins             [engine.ColorCount]int
+	draws            int
+}
+
+func (t *tally) add(g *engine.Game, positions int) {
+	t.games++
+	t.positions += positions
+	if g.Winner != nil {
+		t.wins[*g.Winner]++
+	} else {
+		t.draws++
+	}
+}
+
+func (t tally) String() string {
+	return fmt.Sprintf("%d games, %d positions (white %d, black %d, draws %d)",
+		t.games, t.positions, t.wins[engine.White], t.wins[engine.Black], t.draws)
+}
+
+// run plays the games, parallel of them at once, and writes their
+// examples to output once all are done.
+func run(model *bot.Model, opts bot.SelfPlayOptions, games, parallel int, output string) error {
+	if games < 1 {
+		return fmt.Errorf("games must be positive, got %d", games)
+	}
+	if parallel <= 0 {
+		parallel = runtime.GOMAXPROCS(0)
+	}
+
+	// created first, so that a bad path fails before the games are played
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	start := time.Now()
+
+	var (
+		mu       sync.Mutex
+		examples []bot.Example
+		results  tally
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	queue := make(chan struct{}, games)
+	for range games {
+		queue <- struct{}{}
+	}
+	close(queue)
+
+	for range min(parallel, games) {
+		wg.Go(func() {
+			for range queue {
+				played, g, err := model.SelfPlay(opts)
+
+				mu.Lock()
+				failed := firstErr != nil || err != nil
+				if firstErr == nil {
+					firstErr = err
+				}
+				if !failed {
+					examples = append(examples, played...)
+					results.add(g, len(played))
+					fmt.Fprintf(os.Stderr, "\r%v", results)
+				}
+				mu.Unlock()
+
+				if failed {
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+	fmt.Fprintln(os.Stderr)
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	if err := writeNPZ(f, examples); err != nil {
+		return fmt.Errorf("write %s: %w", output, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", output, err)
+	}
+
+	elapsed := time.Since(start)
+	fmt.Fprintf(os.Stdout, "Wrote %d positions of %d games to %s\n", len(examples), results.games, output)
+	fmt.Fprintf(os.Stdout, "Time: %v (%.1f games/s)\n", elapsed.Round(time.Millisecond), float64(results.games)/elapsed.Seconds())
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"tic-tac-chec/internal/bot"
+)
+
+// writeNPZ writes the examples as a compressed NumPy archive laid out like
+// the one ReplayBuffer.save writes in bot/training: states (N,19,4,4),
+// policies (N,320) and values (N,) as float32, and the one-element int64
+// arrays index and size, so that ReplayBuffer.load reads it as is.
+func writeNPZ(w io.Writer, examples []bot.Example) error {
+	n := len(examples)
+
+	states := make([]float32, 0, n*bot.StateSize)
+	policies := make([]float32, 0, n*bot.ActionSpaceSize)
+	values := make([]float32, 0, n)
+	for _, e := range examples {
+		states = append(states, e.State...)
+		policies = append(policies, e.Policy...)
+		values = append(values, e.Value)
+	}
+
+	arrays := []struct {
+		name  string
+		descr string
+		shape []int
+		write func(io.Writer) error
+	}{
+		{"states", "<f4", []int{n, bot.NumChannels, bot.BoardSize, bot.BoardSize}, float32s(states)},
+		{"policies", "<f4", []int{n, bot.ActionSpaceSize}, float32s(policies)},
+		{"values", "<f4", []int{n}, float32s(values)},
+		{"index", "<i8", []int{1}, int64s(int64(n))},
+		{"size", "<i8", []int{1}, int64s(int64(n))},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, a := range arrays {
+		f, err := archive.Create(a.name + ".npy")
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, npyHeader(a.descr, a.shape)); err != nil {
+			return err
+		}
+		if err := a.write(f); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// npyHeader returns the header of a version 1.0 .npy file holding a
+// C-ordered array of the given type and shape. It is padded with spaces
+// so that the data starts on a 64-byte boundary.
+func npyHeader(descr string, shape []int) string {
+	dims := make([]string, len(shape))
+	for i, d := range shape {
+		dims[i] = fmt.Sprint(d)
+	}
+	tuple := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		tuple += ","
+	}
+
+	dict := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", descr, tuple)
+
+	// magic, version and header length come before the dict, a newline after
+	const prefix = 10
+	padding := 63 - (prefix+len(dict))%64
+	dict += strings.Repeat(" ", padding) + "\n"
+
+	var lengths [2]byte
+	binary.LittleEndian.PutUint16(lengths[:], uint16(len(dict)))
+
+	return "\x93NUMPY\x01\x00" + string(lengths[:]) + dict
+}
+
+func float32s(data []float32) func(io.Writer) error {
+	return func(w io.Writer) error {
+		buf := make([]byte, 4*len(data))
+		for i, v := range data {
+			binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+		}
+		_, err := w.Write(buf)
+		return err
+	}
+}
+
+func int64s(data ...int64) func(io.Writer) error {
+	return func(w io.Writer) error {
+		return binary.Write(w, binary.LittleEndian, data)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"tic-tac-chec/internal/bot"
+)
+
+func TestWriteNPZ(t *testing.T) {
+	examples := make([]bot.Example, 3)
+	for i := range examples {
+		examples[i] = bot.Example{
+			State:  make([]float32, bot.StateSize),
+			Policy: make([]float32, bot.ActionSpaceSize),
+			Value:  float32(i - 1),
+		}
+		examples[i].State[i] = 1
+		examples[i].Policy[10*i] = 1
+	}
+
+	var buf bytes.Buffer
+	if err := writeNPZ(&buf, examples); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]struct {
+		header string
+		size   int
+	}{
+		"states.npy":   {"{'descr': '<f4', 'fortran_order': False, 'shape': (3, 19, 4, 4), }", 3 * bot.StateSize * 4},
+		"policies.npy": {"{'descr': '<f4', 'fortran_order': False, 'shape': (3, 320), }", 3 * bot.ActionSpaceSize * 4},
+		"values.npy":   {"{'descr': '<f4', 'fortran_order': False, 'shape': (3,), }", 3 * 4},
+		"index.npy":    {"{'descr': '<i8', 'fortran_order': False, 'shape': (1,), }", 8},
+		"size.npy":     {"{'descr': '<i8', 'fortran_order': False, 'shape': (1,), }", 8},
+	}
+	if len(archive.File) != len(want) {
+		t.Fatalf("expected %d arrays, got %d", len(want), len(archive.File))
+	}
+
+	arrays := make(map[string][]byte)
+	for _, f := range archive.File {
+		w, ok := want[f.Name]
+		if !ok {
+			t.Fatalf("unexpected array %s", f.Name)
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.HasPrefix(data, []byte("\x93NUMPY\x01\x00")) {
+			t.Fatalf("%s: expected the .npy magic, got %q", f.Name, data[:8])
+		}
+		length := int(binary.LittleEndian.Uint16(data[8:10]))
+		if (10+length)%64 != 0 {
+			t.Errorf("%s: expected the data to start on a 64-byte boundary, header is %d bytes", f.Name, 10+length)
+		}
+		if header := strings.TrimRight(string(data[10:10+length]), " \n"); header != w.header {
+			t.Errorf("%s: expected header %s, got %s", f.Name, w.header, header)
+		}
+		if got := len(data) - 10 - length; got != w.size {
+			t.Errorf("%s: expected %d bytes of data, got %d", f.Name, w.size, got)
+		}
+		arrays[f.Name] = data[10+length:]
+	}
+
+	float := func(name string, i int) float32 {
+		return math.Float32frombits(binary.LittleEndian.Uint32(arrays[name][4*i:]))
+	}
+	for i, e := range examples {
+		if float("states.npy", i*bot.StateSize+i) != 1 {
+			t.Errorf("example %d: state not written in order", i)
+		}
+		if float("policies.npy", i*bot.ActionSpaceSize+10*i) != 1 {
+			t.Errorf("example %d: policy not written in order", i)
+		}
+		if got := float("values.npy", i); got != e.Value {
+			t.Errorf("example %d: expected value %v, got %v", i, e.Value, got)
+		}
+	}
+	if size := binary.LittleEndian.Uint64(arrays["size.npy"]); size != 3 {
+		t.Errorf("expected size 3, got %d", size)
+	}
+}
@@ -0,0 +1,63 @@
+// Command solve looks for a forced win of the side to move, to validate
+// puzzles and check positions by hand.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/record"
+	"tic-tac-chec/internal/solver"
+
+	"github.com/alecthomas/kong"
+)
+
+var cli struct {
+	FEN   string `name:"fen" help:"Position to solve" required:""`
+	Plies int    `arg:"" help:"Longest win to look for, in moves of both sides" default:"5"`
+}
+
+var colorNames = [engine.ColorCount]string{"White", "Black"}
+
+func main() {
+	ctx := kong.Parse(&cli,
+		kong.Name("solve"),
+		kong.Description("Look for a forced win of the side to move."),
+		kong.UsageOnError(),
+	)
+
+	game, err := engine.ParseFEN(cli.FEN)
+	ctx.FatalIfErrorf(err)
+
+	ctx.FatalIfErrorf(run(game, cli.Plies))
+}
+
+func run(game *engine.Game, plies int) error {
+	if plies < 1 {
+		return fmt.Errorf("plies must be positive, got %d", plies)
+	}
+
+	start := time.Now()
+	result, err := solver.Solve(game, plies)
+	if err != nil {
+		return err
+	}
+	elapsed := time.Since(start)
+
+	if result.Win {
+		moves := make([]string, len(result.Line))
+		for i, m := range result.Line {
+			moves[i] = record.FormatMove(m, game.Rules.Size(), i == len(result.Line)-1)
+		}
+		fmt.Fprintf(os.Stdout, "%s wins in %d (%d plies): %s\n", colorNames[game.Turn], result.Moves(), result.Plies, strings.Join(moves, " "))
+	} else {
+		fmt.Fprintf(os.Stdout, "No forced win within %d plies\n", plies)
+	}
+	fmt.Fprintf(os.Stdout, "Nodes: %d\n", result.Nodes)
+	fmt.Fprintf(os.Stdout, "Time: %v\n", elapsed.Round(time.Millisecond))
+
+	return nil
+}
@@ -4,15 +4,55 @@ import (
 	"fmt"
 	"os"
 
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/bot/search"
+	"tic-tac-chec/internal/game"
 	"tic-tac-chec/internal/ui"
 
+	"github.com/alecthomas/kong"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+var cli struct {
+	Rules string `name:"rules" help:"Play a variant, e.g. \"5x5-4+queen+discard+keeppawn\""`
+	Bot   int    `name:"bot" help:"Play white against the search bot looking this many plies ahead, e.g. 4"`
+}
+
 func main() {
-	p := tea.NewProgram(ui.InitialModel())
+	ctx := kong.Parse(&cli,
+		kong.Name("tui"),
+		kong.Description("Play Tic Tac Chec locally in the terminal."),
+		kong.UsageOnError(),
+	)
+
+	rules, err := engine.ParseRules(cli.Rules)
+	ctx.FatalIfErrorf(err)
+
+	model := ui.InitialModel(rules)
+	if cli.Bot > 0 {
+		model = botModel(rules, cli.Bot)
+	}
+
+	p := tea.NewProgram(model)
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
 }
+
+// botModel pairs the terminal player, as white, with a search bot in a room.
+func botModel(rules engine.Rules, depth int) ui.Model {
+	commands := make(chan game.Command)
+	player := game.NewPlayer(commands)
+
+	room := game.NewRoom(player, search.New(depth).RunPlayer("bot"), rules)
+	go room.Run()
+
+	model := ui.InitialModel(rules)
+	model.Mode = ui.ModeOnline
+	model.Phase = ui.PhaseWaiting
+	model.Commands = commands
+	model.Updates = player.Updates
+
+	return model
+}
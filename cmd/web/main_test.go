@@ -7,9 +7,11 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"tic-tac-chec/engine"
 	"tic-tac-chec/internal/web/app"
 	"tic-tac-chec/internal/web/clients"
 	"tic-tac-chec/internal/web/config"
+	"tic-tac-chec/internal/web/lobby"
 	"tic-tac-chec/internal/web/room"
 	"tic-tac-chec/internal/web/ws"
 	"time"
@@ -73,6 +75,45 @@ func TestMeRespondsWithClient(t *testing.T) {
 	}
 }
 
+func TestCreateVariantLobby(t *testing.T) {
+	router, app := setupAppServer(t)
+
+	req, err := http.NewRequest("POST", "/api/lobbies?rules=5x5-4%2Bqueen", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if http.StatusCreated != rr.Code {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+
+	var body struct {
+		ID    string `json:"id"`
+		Rules string `json:"rules"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "5x5-4+queen", body.Rules)
+
+	created := app.LobbyRegistry().Find(lobby.LobbyID(body.ID))
+	if created == nil {
+		t.Fatal("lobby not found")
+	}
+	assert.Equal(t, engine.Rules{BoardSize: 5, WinLength: 4, Queen: true}, created.Rules)
+
+	req, err = http.NewRequest("POST", "/api/lobbies?rules=9x9", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
 func TestLobbyPairsClients(t *testing.T) {
 	router, app := setupAppServer(t)
 
@@ -128,7 +169,7 @@ func TestLobbyPairsClients(t *testing.T) {
 func TestLobbyWithIDPairsClients(t *testing.T) {
 	router, app := setupAppServer(t)
 
-	lobby := app.LobbyRegistry().Create()
+	lobby := app.LobbyRegistry().Create(engine.StandardRules)
 
 	server := httptest.NewServer(router)
 	defer server.Close()
@@ -0,0 +1,170 @@
+package engine
+
+import "testing"
+
+// benchmarkFEN is a middlegame position with pieces on the board and in hand.
+const benchmarkFEN = "r1b1/1P2/2N1/B2p Rn w uu 3 9"
+
+func benchmarkGame(b *testing.B) *Game {
+	b.Helper()
+
+	g, err := ParseFEN(benchmarkFEN)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return g
+}
+
+func BenchmarkGameClone(b *testing.B) {
+	g := benchmarkGame(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		g.Clone()
+	}
+}
+
+// BenchmarkGameCloneLongGame clones a game with a long history, which the
+// clone shares instead of copying.
+func BenchmarkGameCloneLongGame(b *testing.B) {
+	g := NewGame()
+	g.NoProgressLimit = 0
+	for len(g.History) < 60 {
+		// the first move that does not end the game
+		next := g
+		for _, move := range g.AllLegalMoves() {
+			c := g.Clone()
+			if err := c.Play(move); err == nil && c.Status != GameOver {
+				next = c
+				break
+			}
+		}
+		if next == g {
+			b.Fatalf("game ends after %d moves", len(g.History))
+		}
+		g = next
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		g.Clone()
+	}
+}
+
+func BenchmarkPositionCopy(b *testing.B) {
+	g := benchmarkGame(b)
+	p := g.Position()
+	var sink Position
+	b.ReportAllocs()
+	for b.Loop() {
+		sink = p
+	}
+	_ = sink
+}
+
+func BenchmarkGameAllLegalMoves(b *testing.B) {
+	g := benchmarkGame(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		g.AllLegalMoves()
+	}
+}
+
+func BenchmarkPositionGenerateMoves(b *testing.B) {
+	g := benchmarkGame(b)
+	p := g.Position()
+	buf := make([]PositionMove, 0, MaxMoves)
+	b.ReportAllocs()
+	for b.Loop() {
+		buf = p.GenerateMoves(buf[:0])
+	}
+}
+
+// BenchmarkBoardLinesWin is the four-in-a-row check as it was done before
+// win masks: walk every line returned by Board.Lines.
+func BenchmarkBoardLinesWin(b *testing.B) {
+	g := benchmarkGame(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, line := range g.Board.Lines() {
+			win := true
+			for _, piece := range line {
+				if piece == nil || piece.Color != White {
+					win = false
+					break
+				}
+			}
+			if win {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkGameHasFourInARow(b *testing.B) {
+	g := benchmarkGame(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		g.hasFourInARow(White)
+	}
+}
+
+func BenchmarkPositionHasFourInARow(b *testing.B) {
+	g := benchmarkGame(b)
+	p := g.Position()
+	b.ReportAllocs()
+	for b.Loop() {
+		p.HasFourInARow(White)
+	}
+}
+
+// BenchmarkGameMoveUndo and BenchmarkPositionMakeUnmake play and take back
+// every legal move of the position.
+func BenchmarkGameMoveUndo(b *testing.B) {
+	g := benchmarkGame(b)
+	moves := g.AllLegalMoves()
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, m := range moves {
+			g.Play(m)
+			g.Undo()
+		}
+	}
+}
+
+func BenchmarkPositionMakeUnmake(b *testing.B) {
+	g := benchmarkGame(b)
+	p := g.Position()
+	moves := p.GenerateMoves(nil)
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, m := range moves {
+			u := p.MakeMove(m)
+			p.UnmakeMove(m, u)
+		}
+	}
+}
+
+// BenchmarkGameClonePlay is how MCTS expands a node: clone the game for
+// every legal move and play the move on the clone.
+func BenchmarkGameClonePlay(b *testing.B) {
+	g := benchmarkGame(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, m := range g.AllLegalMoves() {
+			g.Clone().Play(m)
+		}
+	}
+}
+
+func BenchmarkPositionCopyMake(b *testing.B) {
+	g := benchmarkGame(b)
+	p := g.Position()
+	buf := make([]PositionMove, 0, MaxMoves)
+	b.ReportAllocs()
+	for b.Loop() {
+		buf = p.GenerateMoves(buf[:0])
+		for _, m := range buf {
+			child := p
+			child.MakeMove(m)
+		}
+	}
+}
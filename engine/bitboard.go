@@ -0,0 +1,248 @@
+package engine
+
+import "math/bits"
+
+// SquareCount is the number of squares on the largest board.
+const SquareCount = MaxBoardSize * MaxBoardSize
+
+// Square is a cell as a single index, row by row from the top-left corner
+// of the largest board: Cell{Row: r, Col: c} is Square(r*MaxBoardSize + c),
+// whatever the board size of the rules.
+type Square int8
+
+const (
+	// InHand is the square of a piece in its owner's hand.
+	InHand Square = -1
+	// Discarded is the square of a piece that is not in play: captured under
+	// Rules.DiscardCaptures, or of a kind the rules leave out.
+	Discarded Square = -2
+)
+
+func SquareOf(c Cell) Square {
+	return Square(c.Row*MaxBoardSize + c.Col)
+}
+
+func (s Square) Cell() Cell {
+	return Cell{Row: int(s) / MaxBoardSize, Col: int(s) % MaxBoardSize}
+}
+
+// Bit returns the bitboard with only this square set.
+func (s Square) Bit() Bitboard {
+	return 1 << s
+}
+
+// Name returns the square in board notation for the given board size:
+// a1 is the bottom-left cell.
+func (s Square) Name(size int) string {
+	switch s {
+	case InHand:
+		return "hand"
+	case Discarded:
+		return "discarded"
+	}
+
+	cell := s.Cell()
+	return string([]byte{'a' + byte(cell.Col), '0' + byte(size-cell.Row)})
+}
+
+// Bitboard is a set of squares, bit i standing for Square(i).
+type Bitboard uint32
+
+func (b Bitboard) Has(s Square) bool {
+	return b&s.Bit() != 0
+}
+
+func (b Bitboard) Count() int {
+	return bits.OnesCount32(uint32(b))
+}
+
+// First returns the lowest square in the set. The set must not be empty.
+func (b Bitboard) First() Square {
+	return Square(bits.TrailingZeros32(uint32(b)))
+}
+
+// Last returns the highest square in the set. The set must not be empty.
+func (b Bitboard) Last() Square {
+	return Square(31 - bits.LeadingZeros32(uint32(b)))
+}
+
+// PopFirst removes the lowest square from the set and returns it.
+func (b *Bitboard) PopFirst() Square {
+	s := b.First()
+	*b &= *b - 1
+	return s
+}
+
+// boardTables are precomputed for every board size, indexed by square.
+type boardTables struct {
+	cells Bitboard // every square on the board
+
+	knight [SquareCount]Bitboard
+
+	// rays[d][s] holds the squares from s (exclusive) to the edge
+	// in direction d, see rayDirections.
+	rays [len(rayDirections)][SquareCount]Bitboard
+
+	// pawnPushes and pawnCaptures are indexed by pawnDirectionIndex first.
+	pawnPushes   [2][SquareCount]Bitboard
+	pawnCaptures [2][SquareCount]Bitboard
+
+	// winMasks[n] holds every line of n cells: rows, columns and diagonals.
+	winMasks [MaxBoardSize + 1][]Bitboard
+}
+
+var tables = func() (t [MaxBoardSize + 1]*boardTables) {
+	for size := BoardSize; size <= MaxBoardSize; size++ {
+		t[size] = newBoardTables(Rules{BoardSize: size})
+	}
+	return t
+}()
+
+func tablesFor(r Rules) *boardTables {
+	return tables[r.Size()]
+}
+
+// WinMasks returns every line that wins under the rules.
+func WinMasks(r Rules) []Bitboard {
+	return tablesFor(r).winMasks[r.LineLength()]
+}
+
+// rayDirections are the sliding directions. Rays of the first four run
+// towards higher squares, rays of the last four towards lower ones.
+var rayDirections = [8]direction{
+	{0, 1},   // right
+	{1, -1},  // down-left
+	{1, 0},   // down
+	{1, 1},   // down-right
+	{0, -1},  // left
+	{-1, 1},  // up-right
+	{-1, 0},  // up
+	{-1, -1}, // up-left
+}
+
+var (
+	rookRays   = []int{0, 2, 4, 6}
+	bishopRays = []int{1, 3, 5, 7}
+	queenRays  = []int{0, 1, 2, 3, 4, 5, 6, 7}
+)
+
+func newBoardTables(r Rules) *boardTables {
+	t := &boardTables{}
+	size := r.Size()
+
+	jumps := []direction{{-2, 1}, {-1, 2}, {1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}}
+
+	for row := range size {
+		for col := range size {
+			from := Cell{row, col}
+			s := SquareOf(from)
+			t.cells |= s.Bit()
+
+			for _, jump := range jumps {
+				if to := (Cell{row + jump[0], col + jump[1]}); r.Contains(to) {
+					t.knight[s] |= SquareOf(to).Bit()
+				}
+			}
+
+			for d, dir := range rayDirections {
+				for cell := from; ; {
+					cell = Cell{cell.Row + dir[0], cell.Col + dir[1]}
+					if !r.Contains(cell) {
+						break
+					}
+					t.rays[d][s] |= SquareOf(cell).Bit()
+				}
+			}
+
+			for _, pawnDirection := range []PawnDirection{ToBlackSide, ToWhiteSide} {
+				i := pawnDirectionIndex(pawnDirection)
+				ahead := row + int(pawnDirection)
+
+				if to := (Cell{ahead, col}); r.Contains(to) {
+					t.pawnPushes[i][s] = SquareOf(to).Bit()
+				}
+				for _, c := range []int{col - 1, col + 1} {
+					if to := (Cell{ahead, c}); r.Contains(to) {
+						t.pawnCaptures[i][s] |= SquareOf(to).Bit()
+					}
+				}
+			}
+		}
+	}
+
+	for length := 3; length <= size; length++ {
+		t.winMasks[length] = winMasks(r, length)
+	}
+
+	return t
+}
+
+// winMasks lists the lines of the given length: rows, then columns,
+// then diagonals and anti-diagonals.
+func winMasks(r Rules, length int) []Bitboard {
+	var masks []Bitboard
+
+	line := func(start Cell, dir direction) {
+		var mask Bitboard
+		cell := start
+		for range length {
+			if !r.Contains(cell) {
+				return
+			}
+			mask |= SquareOf(cell).Bit()
+			cell = Cell{cell.Row + dir[0], cell.Col + dir[1]}
+		}
+		masks = append(masks, mask)
+	}
+
+	size := r.Size()
+	for _, dir := range []direction{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+		for row := range size {
+			for col := range size {
+				line(Cell{row, col}, dir)
+			}
+		}
+	}
+
+	return masks
+}
+
+func pawnDirectionIndex(d PawnDirection) int {
+	if d == ToBlackSide {
+		return 0
+	}
+	return 1
+}
+
+// slideAttacks returns the squares reachable along the given rays from s,
+// stopping at (and including) the first occupied square of each ray.
+func (t *boardTables) slideAttacks(s Square, occupied Bitboard, directions []int) Bitboard {
+	var attacks Bitboard
+	for _, d := range directions {
+		ray := t.rays[d][s]
+		attacks |= ray
+
+		blockers := ray & occupied
+		if blockers == 0 {
+			continue
+		}
+
+		nearest := blockers.First()
+		if d >= 4 {
+			nearest = blockers.Last()
+		}
+		attacks &^= t.rays[d][nearest]
+	}
+
+	return attacks
+}
+
+// hasLine reports whether the set contains a winning line under the rules.
+func hasLine(b Bitboard, r Rules) bool {
+	for _, mask := range WinMasks(r) {
+		if b&mask == mask {
+			return true
+		}
+	}
+	return false
+}
@@ -9,13 +9,15 @@ func (b *Board) At(pos Cell) *Piece {
 	return b[pos.Row][pos.Col]
 }
 
+// Find returns the cell of the piece, if it is on the board, scanning the
+// board for it; Game.Find does not need to.
 func (b *Board) Find(piece *Piece) (Cell, bool) {
 	if piece == nil {
 		return Cell{}, false
 	}
 
-	for row := range BoardSize {
-		for col := range BoardSize {
+	for row := range MaxBoardSize {
+		for col := range MaxBoardSize {
 			pos := Cell{row, col}
 			boardPiece := b.At(pos)
 
@@ -28,41 +30,21 @@ func (b *Board) Find(piece *Piece) (Cell, bool) {
 	return Cell{}, false
 }
 
-// Move relocates a piece to the target cell. If the target is occupied,
-// the occupant is silently removed from the board — this implements
-// shogi-style capture: since Board holds pointers into Game.Pieces,
-// a captured piece remains in Pieces and becomes "in hand" (Board.Find
-// returns false for it). This invariant requires that every piece on the
-// board is a pointer obtained from Game.Pieces, never a fresh &Piece{}.
-func (b *Board) move(piece *Piece, to Cell) error {
-	if !to.Valid() {
-		return ErrOutOfBounds
-	}
-
-	from, found := b.Find(piece)
-	if !found {
-		return ErrNotOnBoard
-	}
-
-	b[from.Row][from.Col] = nil
-	b[to.Row][to.Col] = piece
-	return nil
-}
-
 func (b *Board) Clear() {
-	for i := range BoardSize {
-		for j := range BoardSize {
+	for i := range MaxBoardSize {
+		for j := range MaxBoardSize {
 			b[i][j] = nil
 		}
 	}
 }
 
+// Lines returns the rows, columns and diagonals of the standard board.
 func (b *Board) Lines() []Line {
 	var lines []Line
 
 	// rows
 	for r := range BoardSize {
-		lines = append(lines, b[r][:])
+		lines = append(lines, b[r][:BoardSize])
 	}
 
 	// columns
@@ -88,6 +70,8 @@ func (b *Board) Lines() []Line {
 	return lines
 }
 
+// Valid reports whether the cell is on the standard board;
+// Rules.Contains checks it against the board of a variant.
 func (c Cell) Valid() bool {
 	return c.Row >= 0 && c.Row < BoardSize && c.Col >= 0 && c.Col < BoardSize
 }
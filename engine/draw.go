@@ -0,0 +1,45 @@
+package engine
+
+// DefaultNoProgressLimit is the number of consecutive quiet moves (no drop,
+// no capture) after which a new game is drawn.
+const DefaultNoProgressLimit = 50
+
+// RepetitionLimit is the number of times the same position has to occur
+// for the game to be drawn by repetition.
+const RepetitionLimit = 3
+
+// Repetitions returns how many times the current position has occurred,
+// comparing the Zobrist keys in Positions. Move counters are not part of
+// the key.
+func (g *Game) Repetitions() int {
+	current := g.Key()
+
+	count := 0
+	for _, key := range g.Positions {
+		if key == current {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (g *Game) recordPosition() {
+	g.Positions = append(g.Positions, g.Key())
+}
+
+// checkDraw ends the game without a winner when the current position
+// has been repeated too often or nothing happened for too long.
+func (g *Game) checkDraw() bool {
+	if g.Repetitions() >= RepetitionLimit {
+		g.finish(nil, Repetition)
+		return true
+	}
+
+	if g.NoProgressLimit > 0 && g.QuietMoveCount >= g.NoProgressLimit {
+		g.finish(nil, MoveLimit)
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"testing"
+)
+
+// dropRooks drops both rooks in opposite corners.
+func dropRooks(t *testing.T, g *Game) {
+	t.Helper()
+
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 0}))
+	expectNoError(t, g.Move(BlackRook, Cell{3, 3}))
+}
+
+// shuffleRooks moves both rooks one cell along their edges and back.
+func shuffleRooks(t *testing.T, g *Game) {
+	t.Helper()
+
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 1}))
+	expectNoError(t, g.Move(BlackRook, Cell{3, 2}))
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 0}))
+	expectNoError(t, g.Move(BlackRook, Cell{3, 3}))
+}
+
+func TestThreefoldRepetitionIsDraw(t *testing.T) {
+	g := NewGame()
+
+	dropRooks(t, g)
+	shuffleRooks(t, g)
+	expectEqual(t, g.Status, GameStarted)
+	expectEqual(t, g.Repetitions(), 2)
+
+	shuffleRooks(t, g)
+
+	expectEqual(t, g.Status, GameOver)
+	expectEqual(t, g.Termination, Repetition)
+	if g.Winner != nil {
+		t.Errorf("expected no winner, got %v", *g.Winner)
+	}
+
+	err := g.Move(WhiteRook, Cell{0, 1})
+	expectError(t, err, ErrGameOver)
+}
+
+func TestNoProgressLimitIsDraw(t *testing.T) {
+	g := NewGame()
+	g.NoProgressLimit = 4
+
+	dropRooks(t, g)
+
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 1}))
+	expectNoError(t, g.Move(BlackRook, Cell{3, 2}))
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 2}))
+	expectEqual(t, g.Status, GameStarted)
+	expectEqual(t, g.QuietMoveCount, uint(3))
+
+	expectNoError(t, g.Move(BlackRook, Cell{3, 1}))
+
+	expectEqual(t, g.Status, GameOver)
+	expectEqual(t, g.Termination, MoveLimit)
+	if g.Winner != nil {
+		t.Errorf("expected no winner, got %v", *g.Winner)
+	}
+}
+
+func TestNoProgressLimitDisabled(t *testing.T) {
+	g := NewGame()
+	g.NoProgressLimit = 0
+
+	dropRooks(t, g)
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 2}))
+	expectNoError(t, g.Move(BlackRook, Cell{3, 1}))
+
+	expectEqual(t, g.Status, GameStarted)
+}
+
+func TestDropsAndCapturesResetQuietMoveCount(t *testing.T) {
+	g := NewGame()
+
+	dropRooks(t, g)
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 1}))
+	expectEqual(t, g.QuietMoveCount, uint(1))
+
+	expectNoError(t, g.Move(BlackBishop, Cell{2, 2}))
+	expectEqual(t, g.QuietMoveCount, uint(0))
+
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 2}))
+	expectEqual(t, g.QuietMoveCount, uint(1))
+
+	expectNoError(t, g.Move(BlackRook, Cell{0, 3}))
+	expectEqual(t, g.QuietMoveCount, uint(2))
+
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 3})) // capture black rook
+	expectEqual(t, g.QuietMoveCount, uint(0))
+}
+
+func TestPositionKeyDependsOnTurnAndPawnDirections(t *testing.T) {
+	g := NewGame()
+	g.Board[1][1] = g.Piece(WhitePawn)
+	p := g.Position()
+	key := p.Key()
+
+	p.Turn = Black
+	if p.Key() == key {
+		t.Error("expected key to change with turn")
+	}
+
+	p.Turn = White
+	p.PawnDirections[White] = ToWhiteSide
+	if p.Key() == key {
+		t.Error("expected key to change with pawn direction")
+	}
+}
+
+func TestPositionsAreKeyedAfterSetup(t *testing.T) {
+	g := NewGame()
+	g.Board[1][1] = g.Piece(WhitePawn)
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 0}))
+	expectNoError(t, g.Move(BlackRook, Cell{3, 3}))
+
+	p := g.scanPosition()
+	expectEqual(t, g.Positions[2], p.Key())
+	expectEqual(t, g.Repetitions(), 1)
+}
+
+func TestCloneKeepsDrawState(t *testing.T) {
+	g := NewGame()
+	dropRooks(t, g)
+	shuffleRooks(t, g)
+
+	clone := g.Clone()
+	expectEqual(t, clone.Repetitions(), 2)
+	expectEqual(t, clone.QuietMoveCount, g.QuietMoveCount)
+	expectEqual(t, clone.NoProgressLimit, g.NoProgressLimit)
+
+	clone.Move(WhiteRook, Cell{0, 1})
+	expectEqual(t, len(g.Positions), 7)
+}
@@ -0,0 +1,304 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FEN is a one-line text notation for a whole position, modelled on chess FEN.
+// It has six space-separated fields, and an optional seventh for games not
+// played under StandardRules, for example "4/1P2/2n1/4 RBNprb b ud 0 2":
+//
+//  1. Board, from the top rank (row 0) down to rank 1, ranks separated by "/".
+//     White pieces are uppercase, Black lowercase (P, R, B, N, Q);
+//     a digit stands for that many empty cells.
+//  2. Pieces in hand, White's then Black's, or "-" if both hands are empty.
+//  3. Side to move: "w" or "b".
+//  4. Pawn directions, White's then Black's: "u" moves up towards rank 4
+//     (the black side), "d" moves down towards rank 1 (the white side).
+//  5. Quiet moves since the last drop or capture.
+//  6. Moves played so far.
+//  7. Optional: the rules as written by Rules.String. ParseFEN reads a
+//     missing field as StandardRules, and FormatFEN leaves it out for them.
+//
+// Every piece in play appears exactly once, either on the board or in hand.
+// Under Rules.DiscardCaptures, pieces listed in neither were captured.
+const StartFEN = "4/4/4/4 PRBNprbn w ud 0 0"
+
+var ErrInvalidFEN = errors.New("invalid FEN")
+
+// FormatFEN returns the FEN of the game's current position.
+func FormatFEN(g *Game) string {
+	fen := fmt.Sprintf("%s %d %d", g.fenPosition(), g.QuietMoveCount, g.MoveCount)
+	if !g.Rules.Standard() {
+		fen += " " + g.Rules.String()
+	}
+	return fen
+}
+
+// ParseFEN builds a game from a FEN string. The game has no history:
+// the parsed position is its initial position. If a side already has
+// a winning line, the game is over and that side is the winner.
+func ParseFEN(fen string) (*Game, error) {
+	fields := strings.Fields(fen)
+	if len(fields) != 6 && len(fields) != 7 {
+		return nil, fmt.Errorf("%w: expected 6 or 7 fields, got %d", ErrInvalidFEN, len(fields))
+	}
+
+	rules := StandardRules
+	if len(fields) == 7 {
+		var err error
+		if rules, err = ParseRules(fields[6]); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFEN, err)
+		}
+	}
+
+	g := NewGame(rules)
+	g.Positions = nil
+
+	if err := g.parseFENBoard(fields[0]); err != nil {
+		return nil, err
+	}
+	if err := g.checkFENHands(fields[1]); err != nil {
+		return nil, err
+	}
+
+	switch fields[2] {
+	case "w":
+		g.Turn = White
+	case "b":
+		g.Turn = Black
+	default:
+		return nil, fmt.Errorf("%w: unknown side to move %q", ErrInvalidFEN, fields[2])
+	}
+
+	if len(fields[3]) != int(ColorCount) {
+		return nil, fmt.Errorf("%w: pawn directions %q", ErrInvalidFEN, fields[3])
+	}
+	for color := range ColorCount {
+		direction, err := fenPawnDirection(fields[3][color])
+		if err != nil {
+			return nil, err
+		}
+		g.PawnDirections[color] = direction
+	}
+
+	quiet, err := strconv.ParseUint(fields[4], 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: quiet move count %q", ErrInvalidFEN, fields[4])
+	}
+	moves, err := strconv.ParseUint(fields[5], 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: move count %q", ErrInvalidFEN, fields[5])
+	}
+	g.QuietMoveCount = uint(quiet)
+	g.MoveCount = uint(moves)
+
+	if err := g.checkFENWinner(); err != nil {
+		return nil, err
+	}
+	g.recordPosition()
+
+	return g, nil
+}
+
+// fenPosition is the part of the FEN that identifies a position:
+// board, hands, side to move and pawn directions.
+func (g *Game) fenPosition() string {
+	var out strings.Builder
+
+	for row := range g.Rules.Size() {
+		if row > 0 {
+			out.WriteString("/")
+		}
+
+		empty := 0
+		for col := range g.Rules.Size() {
+			piece := g.Board[row][col]
+			if piece == nil {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				out.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			out.WriteString(pieceLetter(piece))
+		}
+		if empty > 0 {
+			out.WriteString(strconv.Itoa(empty))
+		}
+	}
+
+	out.WriteString(" ")
+	hands := 0
+	for color := range ColorCount {
+		for kind := range PieceKindCount {
+			piece := g.Pieces.Get(color, kind)
+			if g.PieceInHand(*piece) {
+				out.WriteString(pieceLetter(piece))
+				hands++
+			}
+		}
+	}
+	if hands == 0 {
+		out.WriteString("-")
+	}
+
+	out.WriteString(" ")
+	out.WriteString(strings.ToLower(g.Turn.String()))
+
+	out.WriteString(" ")
+	for _, direction := range g.PawnDirections {
+		if direction == ToBlackSide {
+			out.WriteString("u")
+		} else {
+			out.WriteString("d")
+		}
+	}
+
+	return out.String()
+}
+
+func (g *Game) parseFENBoard(board string) error {
+	size := g.Rules.Size()
+	ranks := strings.Split(board, "/")
+	if len(ranks) != size {
+		return fmt.Errorf("%w: expected %d ranks, got %d", ErrInvalidFEN, size, len(ranks))
+	}
+
+	for row, rank := range ranks {
+		col := 0
+		for _, r := range rank {
+			if col >= size {
+				return fmt.Errorf("%w: rank %q is too long", ErrInvalidFEN, rank)
+			}
+
+			if r >= '1' && r <= '0'+rune(size) {
+				col += int(r - '0')
+				continue
+			}
+
+			p, err := g.fenPiece(r)
+			if err != nil {
+				return err
+			}
+			if g.PieceOnBoard(p) {
+				return fmt.Errorf("%w: %v is on the board twice", ErrInvalidFEN, p.FriendlyName())
+			}
+			g.Board[row][col] = g.Piece(p)
+			col++
+		}
+
+		if col != size {
+			return fmt.Errorf("%w: rank %q must have %d cells", ErrInvalidFEN, rank, size)
+		}
+	}
+
+	return nil
+}
+
+// checkFENHands verifies that the hand field lists exactly the pieces
+// that are not on the board. Under Rules.DiscardCaptures, the pieces in
+// neither place are marked as discarded.
+func (g *Game) checkFENHands(hands string) error {
+	var inHand [ColorCount][PieceKindCount]bool
+
+	if hands != "-" {
+		for _, r := range hands {
+			p, err := g.fenPiece(r)
+			if err != nil {
+				return err
+			}
+			if inHand[p.Color][p.Kind] {
+				return fmt.Errorf("%w: %v is in hand twice", ErrInvalidFEN, p.FriendlyName())
+			}
+			inHand[p.Color][p.Kind] = true
+		}
+	}
+
+	for color := range ColorCount {
+		for _, kind := range g.Rules.Kinds() {
+			piece := g.Pieces.Get(color, kind)
+			onBoard := g.PieceOnBoard(*piece)
+			switch {
+			case inHand[color][kind] && onBoard:
+				return fmt.Errorf("%w: %v is both on the board and in hand", ErrInvalidFEN, piece.FriendlyName())
+			case !inHand[color][kind] && !onBoard && g.Rules.DiscardCaptures:
+				g.Discarded[color][kind] = true
+			case !inHand[color][kind] && !onBoard:
+				return fmt.Errorf("%w: %v must be either on the board or in hand", ErrInvalidFEN, piece.FriendlyName())
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *Game) checkFENWinner() error {
+	var winners []Color
+	for color := range ColorCount {
+		if g.hasFourInARow(color) {
+			winners = append(winners, color)
+		}
+	}
+
+	switch len(winners) {
+	case 0:
+		return nil
+	case 1:
+		g.win(winners[0])
+		return nil
+	default:
+		return fmt.Errorf("%w: both sides have four in a row", ErrInvalidFEN)
+	}
+}
+
+// pieceLetter returns uppercase letters for White and lowercase for Black.
+func pieceLetter(piece *Piece) string {
+	if piece.Color == White {
+		return piece.Kind.String()
+	}
+	return strings.ToLower(piece.Kind.String())
+}
+
+func (g *Game) fenPiece(r rune) (Piece, error) {
+	color := White
+	if r >= 'a' && r <= 'z' {
+		color = Black
+	}
+
+	var kind PieceKind
+	switch strings.ToUpper(string(r)) {
+	case "P":
+		kind = Pawn
+	case "R":
+		kind = Rook
+	case "B":
+		kind = Bishop
+	case "N":
+		kind = Knight
+	case "Q":
+		kind = Queen
+	default:
+		return Piece{}, fmt.Errorf("%w: unknown piece %q", ErrInvalidFEN, r)
+	}
+
+	if !g.Rules.HasKind(kind) {
+		return Piece{}, fmt.Errorf("%w: %v is not in play under rules %v", ErrInvalidFEN, kind, g.Rules)
+	}
+	return Piece{Color: color, Kind: kind}, nil
+}
+
+func fenPawnDirection(b byte) (PawnDirection, error) {
+	switch b {
+	case 'u':
+		return ToBlackSide, nil
+	case 'd':
+		return ToWhiteSide, nil
+	}
+
+	return 0, fmt.Errorf("%w: unknown pawn direction %q", ErrInvalidFEN, b)
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"testing"
+)
+
+func TestFormatFENNewGame(t *testing.T) {
+	expectEqual(t, FormatFEN(NewGame()), StartFEN)
+}
+
+func TestParseFENStart(t *testing.T) {
+	g := gameFromFEN(t, StartFEN)
+
+	expectSameGame(t, g, NewGame())
+	expectEqual(t, g.Turn, White)
+	expectEqual(t, g.PawnDirections, PawnDirections{ToBlackSide, ToWhiteSide})
+}
+
+func TestFormatFENAfterMoves(t *testing.T) {
+	g := NewGame()
+	expectNoError(t, g.Move(WhitePawn, Cell{1, 0}))
+	expectNoError(t, g.Move(BlackKnight, Cell{2, 2}))
+	expectNoError(t, g.Move(WhitePawn, Cell{0, 0})) // reaches the black edge
+	expectNoError(t, g.Move(BlackBishop, Cell{1, 1}))
+	expectNoError(t, g.Move(WhitePawn, Cell{1, 1})) // captures the black bishop
+
+	expectEqual(t, FormatFEN(g), "4/1P2/2n1/4 RBNprb b dd 0 5")
+}
+
+func TestFENRoundTrip(t *testing.T) {
+	fens := []string{
+		StartFEN,
+		"4/1P2/2n1/4 RBNprb b dd 0 5",
+		"R2b/1p2/2N1/B1rn P w uu 3 12",
+		"rbn1/p3/3P/RBN1 - b ud 7 8",
+	}
+
+	for _, fen := range fens {
+		g := gameFromFEN(t, fen)
+		expectEqual(t, FormatFEN(g), fen)
+	}
+}
+
+func TestParseFENPlaysOn(t *testing.T) {
+	g := NewGame()
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 0}))
+	expectNoError(t, g.Move(BlackRook, Cell{3, 3}))
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 3}))
+
+	parsed := gameFromFEN(t, FormatFEN(g))
+	expectEqual(t, parsed.Key(), g.Key())
+	expectEqual(t, parsed.MoveCount, g.MoveCount)
+	expectEqual(t, len(parsed.History), 0)
+
+	expectNoError(t, g.Move(BlackRook, Cell{0, 3}))
+	expectNoError(t, parsed.Move(BlackRook, Cell{0, 3}))
+	expectEqual(t, FormatFEN(parsed), FormatFEN(g))
+}
+
+func TestParseFENWonPosition(t *testing.T) {
+	g := gameFromFEN(t, "R2b/P2p/B2r/N3 n w ud 0 7")
+
+	expectEqual(t, g.Status, GameOver)
+	expectEqual(t, g.Termination, FourInARow)
+	if g.Winner == nil || *g.Winner != White {
+		t.Errorf("expected White to win, got %v", g.Winner)
+	}
+	expectError(t, g.Move(BlackKnight, Cell{3, 3}), ErrGameOver)
+}
+
+func TestParseFENInvalid(t *testing.T) {
+	fens := []string{
+		"",
+		"4/4/4/4 PRBNprbn w ud 0",
+		"4/4/4 PRBNprbn w ud 0 0",
+		"5/4/4/4 PRBNprbn w ud 0 0",
+		"3/4/4/4 PRBNprbn w ud 0 0",
+		"4/4/4/4 PRBNprb w ud 0 0",
+		"4/4/4/4 PRBNprbnn w ud 0 0",
+		"4/4/4/4 PRBKprbn w ud 0 0",
+		"P3/4/4/4 PRBNprbn w ud 0 0",
+		"PP2/4/4/4 RBNprbn w ud 0 0",
+		"4/4/4/4 PRBNprbn x ud 0 0",
+		"4/4/4/4 PRBNprbn w u 0 0",
+		"4/4/4/4 PRBNprbn w ux 0 0",
+		"4/4/4/4 PRBNprbn w ud -1 0",
+		"4/4/4/4 PRBNprbn w ud 0 x",
+		"RBNP/4/4/rbnp - w ud 0 8",
+	}
+
+	for _, fen := range fens {
+		_, err := ParseFEN(fen)
+		expectError(t, err, ErrInvalidFEN)
+	}
+}
@@ -35,6 +35,7 @@ const (
 	Rook
 	Bishop
 	Knight
+	Queen // only with Rules.Queen
 	PieceKindCount
 )
 
@@ -48,6 +49,8 @@ func (k PieceKind) String() string {
 		return "B"
 	case Knight:
 		return "N" // K is used for King in chess. We don't have King, but anyway
+	case Queen:
+		return "Q"
 	}
 
 	panic("unknown piece kind")
@@ -64,9 +67,14 @@ type Pieces [ColorCount][PieceKindCount]Piece
 
 // --- Board ---
 
+// BoardSize is the side of the standard board. Rules may choose a larger
+// one, up to MaxBoardSize; cells beyond the rules' board size stay empty.
 const BoardSize = 4
 
-type Board [BoardSize][BoardSize]*Piece
+// Board holds pointers into Game.Pieces, never fresh &Piece{} values: a
+// piece taken off the board by a capture remains in Pieces, in its owner's
+// hand (shogi-style capture).
+type Board [MaxBoardSize][MaxBoardSize]*Piece
 type Line []*Piece
 
 type Cell struct {
@@ -77,8 +85,8 @@ type Cell struct {
 type BoardSide int
 
 const (
-	BlackSide BoardSide = 0 // 0 row at the top
-	WhiteSide BoardSide = BoardSize - 1
+	BlackSide BoardSide = 0             // 0 row at the top
+	WhiteSide BoardSide = BoardSize - 1 // on the standard board, see Rules.WhiteSide
 )
 
 // --- Game ---
@@ -100,6 +108,7 @@ const (
 type PawnDirections [ColorCount]PawnDirection
 
 type Game struct {
+	Rules          Rules
 	Board          Board
 	Pieces         Pieces
 	Turn           Color
@@ -107,6 +116,39 @@ type Game struct {
 	Status         GameStatus
 	Winner         *Color
 	MoveCount      uint
+	Termination    Termination
+	// WinningLine holds the cells of the line that won the game, in board
+	// order. It is empty unless Termination is FourInARow.
+	WinningLine []Cell
+
+	// QuietMoveCount counts moves since the last drop or capture.
+	QuietMoveCount uint
+	// NoProgressLimit draws the game after that many quiet moves, 0 disables the rule.
+	NoProgressLimit uint
+	// Positions holds the key of every position reached so far,
+	// starting with the initial one.
+	Positions []Key
+
+	// Discarded marks pieces that were captured under Rules.DiscardCaptures:
+	// they are neither on the board nor in hand.
+	Discarded [ColorCount][PieceKindCount]bool
+
+	// History holds the moves played so far. Undone holds the moves taken
+	// back by Undo, the most recently undone last; any new move clears it.
+	History []Move
+	Undone  []Move
+
+	// key is the Zobrist key of the position once keyed, see Key.
+	key   Key
+	keyed bool
+
+	// pos holds the board and hands as a Position once positioned. Like
+	// key it is built on first use, by a move or a clone, then kept up to
+	// date by Move, Undo and Redo; code that sets Board or Discarded
+	// directly must do so before then. Its Turn and PawnDirections are
+	// those of the game, see Position.
+	pos        Position
+	positioned bool
 }
 
 var (
@@ -114,6 +156,7 @@ var (
 	ErrNotOnBoard  = errors.New("piece is not on the board")
 	ErrNotYourTurn = errors.New("it is not your turn")
 	ErrGameOver    = errors.New("game is over")
+	ErrNotInHand   = errors.New("piece is not in hand")
 )
 
 type IllegalMoveError struct {
@@ -134,24 +177,76 @@ func (e *OccupiedError) Error() string {
 	return fmt.Sprintf("can't place here — occupied by %v", e.OccupiedBy.FriendlyName())
 }
 
-func NewGame() *Game {
-	return &Game{
+// NewGame starts a game with the given rules, or StandardRules if none are
+// given. It panics if the rules are invalid; check them with Rules.Validate.
+func NewGame(rules ...Rules) *Game {
+	r := StandardRules
+	if len(rules) > 0 {
+		r = rules[0].Normalized()
+	}
+	if err := r.Validate(); err != nil {
+		panic(err)
+	}
+
+	g := &Game{
+		Rules:  r,
 		Turn:   White,
 		Pieces: NewPieces(),
 		PawnDirections: PawnDirections{
 			White: ToBlackSide, // white moves up initially
 			Black: ToWhiteSide, // black moves down initially
 		},
-		Status: GameStarted,
-		Winner: nil,
+		Status:          GameStarted,
+		Winner:          nil,
+		NoProgressLimit: DefaultNoProgressLimit,
 	}
+	// not recordPosition: the game is keyed on first use, after callers
+	// are done setting up its board
+	start := g.Position()
+	g.Positions = []Key{start.Key()}
+
+	return g
 }
 
 func (g *Game) Move(selected Piece, cell Cell) error {
+	if err := g.play(selected, cell); err != nil {
+		return err
+	}
+
+	g.Undone = nil
+	return nil
+}
+
+func (g *Game) Piece(p Piece) *Piece {
+	return g.Pieces.Get(p.Color, p.Kind)
+}
+
+// Find returns the cell of the piece, if it is on the board. Unlike
+// Board.Find it looks the piece up in the game's Position.
+func (g *Game) Find(piece Piece) (Cell, bool) {
+	s := g.square(piece)
+	if s < 0 {
+		return Cell{}, false
+	}
+	return s.Cell(), true
+}
+
+func (g *Game) PieceOnBoard(piece Piece) bool {
+	return g.square(piece) >= 0
+}
+
+func (g *Game) PieceInHand(piece Piece) bool {
+	return g.square(piece) == InHand
+}
+
+// --- Private helpers ---
+
+// play applies a move and records it in History.
+func (g *Game) play(selected Piece, cell Cell) error {
 	if g.Status == GameOver {
 		return ErrGameOver
 	}
-	if !cell.Valid() {
+	if !g.Rules.Contains(cell) {
 		return ErrOutOfBounds
 	}
 
@@ -159,134 +254,142 @@ func (g *Game) Move(selected Piece, cell Cell) error {
 		return ErrNotYourTurn
 	}
 
+	p := g.syncPosition()
 	piece := g.Piece(selected)
-	_, onBoard := g.Board.Find(piece)
+	from, to := p.Squares[selected.Color][selected.Kind], SquareOf(cell)
+	onBoard := from >= 0
 
-	var err error
-	if onBoard {
-		err = g.movePiece(piece, cell)
-	} else {
-		err = g.placePiece(piece, cell)
+	switch {
+	case onBoard:
+		if !p.Attacks(selected.Color, selected.Kind).Has(to) {
+			return &IllegalMoveError{Piece: *piece, Target: cell}
+		}
+	case from != InHand:
+		return ErrNotInHand
+	case g.Board.At(cell) != nil:
+		return &OccupiedError{Target: cell, OccupiedBy: *g.Board.At(cell)}
 	}
 
-	if err != nil {
-		return err
+	move := Move{
+		Piece:                *piece,
+		To:                   cell,
+		Drop:                 !onBoard,
+		PawnDirectionsBefore: g.PawnDirections,
+		QuietMoveCountBefore: g.QuietMoveCount,
 	}
+	if onBoard {
+		move.From = from.Cell()
+		g.Board[move.From.Row][move.From.Col] = nil
+	}
+	// a captured piece is no longer on the board, and so back in hand:
+	// Board holds pointers into Pieces
+	if captured := g.Board.At(cell); captured != nil {
+		move.Captured = *captured
+		move.Capture = true
+	}
+	g.Board[cell.Row][cell.Col] = piece
+	p.MakeMove(PositionMove{Kind: selected.Kind, From: from, To: to})
 
+	if move.Capture && g.Rules.DiscardCaptures {
+		g.Discarded[move.Captured.Color][move.Captured.Kind] = true
+	}
 	g.MoveCount++
 
-	if g.checkGameOver() {
-		g.Status = GameOver
+	if move.Drop || move.Capture {
+		g.QuietMoveCount = 0
+	} else {
+		g.QuietMoveCount++
+	}
 
+	won := p.HasFourInARow(g.Turn)
+	if won {
 		// if Turn is ever changed after this point,
 		// Winner will still point to the correct value
-		winner := g.Turn
-		g.Winner = &winner
+		g.win(g.Turn)
 	} else {
 		g.nextTurn()
 	}
 
-	return nil
-}
-
-func (g *Game) Piece(p Piece) *Piece {
-	return g.Pieces.Get(p.Color, p.Kind)
-}
-
-func (g *Game) PieceOnBoard(piece Piece) bool {
-	_, onBoard := g.Board.Find(g.Piece(piece))
-	return onBoard
-}
-
-func (g *Game) PieceInHand(piece Piece) bool {
-	return !g.PieceOnBoard(piece)
-}
-
-// --- Private helpers ---
-
-func (g *Game) movePiece(piece *Piece, cell Cell) error {
-	moves, err := g.pieceMoves(piece)
-	if err != nil {
-		return err
-	}
-
-	if !slices.Contains(moves, cell) {
-		return &IllegalMoveError{Piece: *piece, Target: cell}
+	move.PawnDirectionsAfter = g.PawnDirections
+	g.History = append(g.History, move)
+	if g.keyed {
+		g.key ^= move.keyChange(g.Rules, g.Turn != move.Piece.Color)
 	}
 
-	return g.Board.move(piece, cell)
-}
-
-func (g *Game) placePiece(piece *Piece, cell Cell) error {
-	p := g.Board.At(cell)
-
-	if p != nil {
-		return &OccupiedError{Target: cell, OccupiedBy: *p}
+	g.recordPosition()
+	if !won {
+		g.checkDraw()
 	}
 
-	g.Board[cell.Row][cell.Col] = piece
-
 	return nil
 }
 
+// nextTurn passes the turn to the opponent and turns the pawns around.
 func (g *Game) nextTurn() {
-	if g.Turn == White {
-		g.Turn = Black
-	} else {
-		g.Turn = White
-	}
+	p := g.Position()
+	p.Turn = 1 - p.Turn
+	p.updatePawnDirection(White)
+	p.updatePawnDirection(Black)
 
-	g.maybeTurnPawnDirection(g.Piece(WhitePawn))
-	g.maybeTurnPawnDirection(g.Piece(BlackPawn))
+	g.Turn = p.Turn
+	g.PawnDirections = p.PawnDirections
 }
 
-func (g *Game) checkGameOver() bool {
-	if g.Status == GameOver {
-		return true
+// syncPosition returns the game's Position, building it if the game is not
+// positioned yet, with the game's turn and pawn directions. It is for
+// changing the Position along with the game; Position returns a copy.
+func (g *Game) syncPosition() *Position {
+	if !g.positioned {
+		g.pos = g.scanPosition()
+		g.positioned = true
 	}
+	g.pos.Turn = g.Turn
+	g.pos.PawnDirections = g.PawnDirections
+	return &g.pos
+}
 
-	for _, lines := range g.Board.Lines() {
-		win := true
-
-		for _, cell := range lines {
-			if cell == nil || cell.Color != g.Turn {
-				win = false
-				break
-			}
-		}
-
-		if win {
-			return true
-		}
+// square returns where the piece is, see Position.Squares.
+func (g *Game) square(piece Piece) Square {
+	if g.positioned {
+		return g.pos.Squares[piece.Color][piece.Kind]
 	}
-
-	return false
+	p := g.scanPosition()
+	return p.Squares[piece.Color][piece.Kind]
 }
 
-func (g *Game) maybeTurnPawnDirection(pawn *Piece) {
-	pos, onBoard := g.Board.Find(pawn)
-	if onBoard {
-		if g.PawnDirections[pawn.Color] == ToBlackSide && pos.Row == int(BlackSide) {
-			g.PawnDirections[pawn.Color] = ToWhiteSide
-		}
-
-		if g.PawnDirections[pawn.Color] == ToWhiteSide && pos.Row == int(WhiteSide) {
-			g.PawnDirections[pawn.Color] = ToBlackSide
-		}
-	} else {
-		if pawn.Color == White {
-			g.PawnDirections[White] = ToBlackSide
-		} else {
-			g.PawnDirections[Black] = ToWhiteSide
-		}
+func (g *Game) hasFourInARow(color Color) bool {
+	if g.positioned {
+		return g.pos.HasFourInARow(color)
 	}
+	p := g.scanPosition()
+	return p.HasFourInARow(color)
 }
 
+// Clone returns a copy of the game that can be played on its own.
+// The copy shares the backing arrays of History, Positions and Undone,
+// clipped so that appending to them copies; the game never writes below
+// the length of these slices.
 func (g *Game) Clone() *Game {
-	clone := NewGame()
-	clone.Turn = g.Turn
-	clone.PawnDirections = g.PawnDirections
-	clone.Status = g.Status
+	clone := &Game{
+		Rules:           g.Rules,
+		Discarded:       g.Discarded,
+		Pieces:          NewPieces(),
+		Turn:            g.Turn,
+		PawnDirections:  g.PawnDirections,
+		Status:          g.Status,
+		MoveCount:       g.MoveCount,
+		Termination:     g.Termination,
+		WinningLine:     slices.Clone(g.WinningLine),
+		QuietMoveCount:  g.QuietMoveCount,
+		NoProgressLimit: g.NoProgressLimit,
+		Positions:       slices.Clip(g.Positions),
+		History:         slices.Clip(g.History),
+		Undone:          slices.Clip(g.Undone),
+		key:             g.key,
+		keyed:           g.keyed,
+		pos:             g.Position(),
+		positioned:      true,
+	}
 
 	if g.Winner != nil {
 		winner := *g.Winner
@@ -6,7 +6,7 @@ import (
 
 func TestNewGame(t *testing.T) {
 	g := NewGame()
-	if g.Board != [BoardSize][BoardSize]*Piece{} {
+	if g.Board != (Board{}) {
 		t.Error("New game board is not empty")
 	}
 
@@ -275,6 +275,28 @@ func TestCloneIsIndependent(t *testing.T) {
 	}
 }
 
+func TestCloneHistoryIsIndependent(t *testing.T) {
+	g := NewGame()
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 0}))
+	expectNoError(t, g.Move(BlackRook, Cell{3, 3}))
+
+	// the clone shares the history arrays until either game changes them
+	clone := g.Clone()
+	expectNoError(t, g.Undo())
+	expectNoError(t, g.Move(BlackBishop, Cell{2, 2}))
+	expectNoError(t, clone.Move(WhiteBishop, Cell{1, 1}))
+
+	expectEqual(t, g.History[1].Piece, BlackBishop)
+	expectEqual(t, clone.History[1].Piece, BlackRook)
+	expectEqual(t, clone.History[2].Piece, WhiteBishop)
+
+	replayed := NewGame()
+	expectNoError(t, replayed.Move(WhiteRook, Cell{0, 0}))
+	expectNoError(t, replayed.Move(BlackRook, Cell{3, 3}))
+	expectEqual(t, clone.Positions[2], replayed.Key())
+	expectEqual(t, clone.Position(), clone.scanPosition())
+}
+
 func TestCloneWithWinner(t *testing.T) {
 	g := NewGame()
 	// Set up a won game state manually
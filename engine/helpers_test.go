@@ -61,3 +61,13 @@ func expectBoard(t *testing.T, board Board, expected Board) {
 	}
 	t.Log(board)
 }
+
+func gameFromFEN(t *testing.T, fen string) *Game {
+	t.Helper()
+
+	g, err := ParseFEN(fen)
+	if err != nil {
+		t.Fatalf("ParseFEN(%q): %v", fen, err)
+	}
+	return g
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	ErrNothingToUndo = errors.New("no move to undo")
+	ErrNothingToRedo = errors.New("no move to redo")
+	ErrEndedByPlayer = errors.New("game was ended by a player, not a move")
+)
+
+// Move is a single played move: either a drop of a piece from hand
+// or a move of a piece already on the board.
+// It keeps everything needed to take the move back exactly.
+type Move struct {
+	Piece Piece
+	From  Cell // zero for drops
+	To    Cell
+	Drop  bool
+
+	// Captured is the opponent's piece taken on To, valid if Capture is set.
+	// A captured piece returns to its owner's hand.
+	Capture  bool
+	Captured Piece
+
+	PawnDirectionsBefore PawnDirections
+	PawnDirectionsAfter  PawnDirections
+	QuietMoveCountBefore uint
+}
+
+func (m Move) String() string {
+	switch {
+	case m.Drop:
+		return fmt.Sprintf("%v%v@%v", m.Piece.Color, m.Piece.Kind, m.To)
+	case m.Capture:
+		return fmt.Sprintf("%v%v %vx%v", m.Piece.Color, m.Piece.Kind, m.From, m.To)
+	default:
+		return fmt.Sprintf("%v%v %v-%v", m.Piece.Color, m.Piece.Kind, m.From, m.To)
+	}
+}
+
+// LastMove returns the most recent move, if any.
+func (g *Game) LastMove() (Move, bool) {
+	if len(g.History) == 0 {
+		return Move{}, false
+	}
+
+	return g.History[len(g.History)-1], true
+}
+
+// Undo takes back the last move. The board, hands, turn, pawn directions,
+// counters and game status are restored to what they were before the move.
+// The move can be replayed with Redo until another move is made.
+//
+// Games a player ended by resigning, abandoning them or agreeing to a draw
+// fail with ErrEndedByPlayer, as the last move did not end them: Reopen
+// them first.
+func (g *Game) Undo() error {
+	if g.Termination.byPlayer() {
+		return ErrEndedByPlayer
+	}
+
+	move, ok := g.LastMove()
+	if !ok {
+		return ErrNothingToUndo
+	}
+
+	if g.keyed {
+		g.key ^= move.keyChange(g.Rules, g.Turn != move.Piece.Color)
+	}
+
+	g.Board[move.To.Row][move.To.Col] = nil
+	if move.Capture {
+		g.Board[move.To.Row][move.To.Col] = g.Piece(move.Captured)
+		g.Discarded[move.Captured.Color][move.Captured.Kind] = false
+	}
+	if !move.Drop {
+		g.Board[move.From.Row][move.From.Col] = g.Piece(move.Piece)
+	}
+	if g.positioned {
+		from := InHand
+		if !move.Drop {
+			from = SquareOf(move.From)
+		}
+		g.pos.Turn = 1 - move.Piece.Color
+		g.pos.UnmakeMove(
+			PositionMove{Kind: move.Piece.Kind, From: from, To: SquareOf(move.To)},
+			Unmove{Capture: move.Capture, Captured: move.Captured.Kind, PawnDirections: move.PawnDirectionsBefore},
+		)
+	}
+
+	g.Turn = move.Piece.Color
+	g.PawnDirections = move.PawnDirectionsBefore
+	g.QuietMoveCount = move.QuietMoveCountBefore
+	g.MoveCount--
+	g.Status = GameStarted
+	g.Winner = nil
+	g.Termination = NotTerminated
+	g.WinningLine = nil
+
+	// clipped, as clones may share the arrays, see Clone
+	if len(g.Positions) > 0 {
+		g.Positions = slices.Clip(g.Positions[:len(g.Positions)-1])
+	}
+
+	g.History = slices.Clip(g.History[:len(g.History)-1])
+	g.Undone = append(g.Undone, move)
+
+	return nil
+}
+
+// Redo replays the most recently undone move.
+func (g *Game) Redo() error {
+	if len(g.Undone) == 0 {
+		return ErrNothingToRedo
+	}
+
+	move := g.Undone[len(g.Undone)-1]
+	if err := g.play(move.Piece, move.To); err != nil {
+		return err
+	}
+
+	g.Undone = slices.Clip(g.Undone[:len(g.Undone)-1])
+	return nil
+}
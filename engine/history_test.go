@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"testing"
+)
+
+func expectSameGame(t *testing.T, actual, expected *Game) {
+	t.Helper()
+
+	expectEqual(t, actual.Key(), expected.Key())
+	expectEqual(t, actual.MoveCount, expected.MoveCount)
+	expectEqual(t, actual.QuietMoveCount, expected.QuietMoveCount)
+	expectEqual(t, actual.Status, expected.Status)
+	expectEqual(t, actual.Termination, expected.Termination)
+	expectEqual(t, len(actual.Positions), len(expected.Positions))
+	expectEqual(t, len(actual.History), len(expected.History))
+	// the position kept by the game matches its board
+	expectEqual(t, actual.Position(), actual.scanPosition())
+}
+
+func TestMoveIsRecorded(t *testing.T) {
+	g := NewGame()
+
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 0}))
+	expectNoError(t, g.Move(BlackKnight, Cell{2, 1}))
+	expectNoError(t, g.Move(WhiteRook, Cell{2, 0}))
+
+	expectEqual(t, len(g.History), 3)
+
+	drop := g.History[0]
+	expectEqual(t, drop.Piece, WhiteRook)
+	expectEqual(t, drop.To, Cell{0, 0})
+	expectEqual(t, drop.Drop, true)
+	expectEqual(t, drop.Capture, false)
+
+	move, ok := g.LastMove()
+	expectEqual(t, ok, true)
+	expectEqual(t, move.Piece, WhiteRook)
+	expectEqual(t, move.From, Cell{0, 0})
+	expectEqual(t, move.To, Cell{2, 0})
+	expectEqual(t, move.Drop, false)
+	expectEqual(t, move.Capture, false)
+}
+
+func TestUndoDrop(t *testing.T) {
+	g := NewGame()
+	before := g.Clone()
+
+	expectNoError(t, g.Move(WhiteRook, Cell{1, 1}))
+	expectNoError(t, g.Undo())
+
+	expectSameGame(t, g, before)
+	expectEqual(t, g.Turn, White)
+	expectEqual(t, g.PieceInHand(WhiteRook), true)
+}
+
+func TestUndoCaptureRestoresHands(t *testing.T) {
+	g := NewGame()
+	expectNoError(t, g.Move(WhiteBishop, Cell{1, 1}))
+	expectNoError(t, g.Move(BlackBishop, Cell{2, 2}))
+	before := g.Clone()
+
+	expectNoError(t, g.Move(WhiteBishop, Cell{2, 2}))
+	move, _ := g.LastMove()
+	expectEqual(t, move.Capture, true)
+	expectEqual(t, move.Captured, BlackBishop)
+	expectEqual(t, g.PieceInHand(BlackBishop), true)
+
+	expectNoError(t, g.Undo())
+
+	expectSameGame(t, g, before)
+	expectEqual(t, g.PieceOnBoard(BlackBishop), true)
+	expectEqual(t, g.Board.At(Cell{2, 2}), g.Piece(BlackBishop))
+	expectEqual(t, g.Board.At(Cell{1, 1}), g.Piece(WhiteBishop))
+}
+
+func TestUndoRestoresPawnDirections(t *testing.T) {
+	g := NewGame()
+	expectNoError(t, g.Move(WhitePawn, Cell{1, 0}))
+	expectNoError(t, g.Move(BlackRook, Cell{3, 3}))
+	before := g.Clone()
+
+	expectNoError(t, g.Move(WhitePawn, Cell{0, 0})) // reaches the black edge
+	expectEqual(t, g.PawnDirections[White], ToWhiteSide)
+
+	move, _ := g.LastMove()
+	expectEqual(t, move.PawnDirectionsBefore[White], ToBlackSide)
+	expectEqual(t, move.PawnDirectionsAfter[White], ToWhiteSide)
+
+	expectNoError(t, g.Undo())
+
+	expectSameGame(t, g, before)
+	expectEqual(t, g.PawnDirections[White], ToBlackSide)
+}
+
+func TestUndoWinningMove(t *testing.T) {
+	g := NewGame()
+	g.Move(WhiteRook, Cell{0, 0})
+	g.Move(BlackRook, Cell{0, 3})
+	g.Move(WhitePawn, Cell{1, 0})
+	g.Move(BlackPawn, Cell{1, 3})
+	g.Move(WhiteBishop, Cell{2, 0})
+	g.Move(BlackBishop, Cell{2, 3})
+	before := g.Clone()
+
+	expectNoError(t, g.Move(WhiteKnight, Cell{3, 0}))
+	expectEqual(t, g.Status, GameOver)
+
+	expectNoError(t, g.Undo())
+
+	expectSameGame(t, g, before)
+	if g.Winner != nil {
+		t.Errorf("expected no winner after undo, got %v", *g.Winner)
+	}
+	expectNoError(t, g.Move(WhiteKnight, Cell{3, 3}))
+	expectEqual(t, g.Status, GameStarted)
+}
+
+func TestUndoRedoWholeGame(t *testing.T) {
+	g := NewGame()
+	moves := []struct {
+		piece Piece
+		cell  Cell
+	}{
+		{WhiteBishop, Cell{1, 1}},
+		{BlackBishop, Cell{2, 2}},
+		{WhiteBishop, Cell{2, 2}}, // capture
+		{BlackBishop, Cell{0, 0}}, // drop back
+		{WhitePawn, Cell{1, 1}},
+		{BlackKnight, Cell{3, 3}},
+		{WhitePawn, Cell{0, 1}},
+	}
+
+	snapshots := []*Game{g.Clone()}
+	for _, m := range moves {
+		expectNoError(t, g.Move(m.piece, m.cell))
+		snapshots = append(snapshots, g.Clone())
+	}
+
+	for i := len(moves) - 1; i >= 0; i-- {
+		expectNoError(t, g.Undo())
+		expectSameGame(t, g, snapshots[i])
+	}
+	expectError(t, g.Undo(), ErrNothingToUndo)
+
+	for i := range moves {
+		expectNoError(t, g.Redo())
+		expectSameGame(t, g, snapshots[i+1])
+	}
+	expectError(t, g.Redo(), ErrNothingToRedo)
+}
+
+func TestMoveClearsRedo(t *testing.T) {
+	g := NewGame()
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 0}))
+	expectNoError(t, g.Undo())
+	expectEqual(t, len(g.Undone), 1)
+
+	expectNoError(t, g.Move(WhiteRook, Cell{3, 3}))
+	expectEqual(t, len(g.Undone), 0)
+	expectError(t, g.Redo(), ErrNothingToRedo)
+}
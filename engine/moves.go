@@ -2,155 +2,51 @@ package engine
 
 type direction [2]int
 
-// LegalMoves returns all valid target cells for a piece on the board.
-// Returns nil if the piece is not on the board.
-func (g *Game) LegalMoves(p Piece) []Cell {
-	piece := g.Piece(p)
-	moves, err := g.pieceMoves(piece)
-	if err != nil {
-		return nil
-	}
-	return moves
-}
-
-func (g *Game) pieceMoves(piece *Piece) ([]Cell, error) {
-	switch piece.Kind {
-	case Pawn:
-		return g.pawnMoves(piece)
-	case Rook:
-		return g.rookMoves(piece)
-	case Bishop:
-		return g.bishopMoves(piece)
-	case Knight:
-		return g.knightMoves(piece)
-	default:
-		panic("invalid piece kind")
-	}
-}
-
-func (g *Game) rookMoves(rook *Piece) ([]Cell, error) {
-	left := direction{0, -1}
-	right := direction{0, 1}
-	up := direction{-1, 0}
-	down := direction{1, 0}
-	directions := []direction{right, down, left, up}
-
-	return g.slideMoves(rook, directions)
-}
-
-func (g *Game) bishopMoves(bishop *Piece) ([]Cell, error) {
-	upLeft := direction{-1, -1}
-	upRight := direction{-1, 1}
-	downLeft := direction{1, -1}
-	downRight := direction{1, 1}
-	directions := []direction{upRight, downRight, downLeft, upLeft} // clockwise from top-right
-
-	return g.slideMoves(bishop, directions)
-}
-
-func (g *Game) knightMoves(knight *Piece) ([]Cell, error) {
-	from, onBoard := g.Board.Find(knight)
-	if !onBoard {
-		return nil, ErrNotOnBoard
-	}
-
-	upLeft := direction{-2, -1}
-	upRight := direction{-2, 1}
-	downLeft := direction{2, -1}
-	downRight := direction{2, 1}
-	leftUp := direction{-1, -2}
-	leftDown := direction{1, -2}
-	rightUp := direction{-1, 2}
-	rightDown := direction{1, 2}
-	directions := []direction{upRight, rightUp, rightDown, downRight, downLeft, leftDown, leftUp, upLeft}
+// LegalMoves returns all valid target cells for a piece on the board,
+// row by row. Returns nil if the piece is not on the board.
+func (g *Game) LegalMoves(piece Piece) []Cell {
+	p := g.Position()
 
 	var moves []Cell
-	for _, dir := range directions {
-		cell := Cell{Row: from.Row + dir[0], Col: from.Col + dir[1]}
-
-		allowed, _ := g.canMoveTo(knight, cell)
-		if allowed {
-			moves = append(moves, cell)
-		}
+	for targets := p.Attacks(piece.Color, piece.Kind); targets != 0; {
+		moves = append(moves, targets.PopFirst().Cell())
 	}
-
-	return moves, nil
-}
-
-func (g *Game) pawnMoves(pawn *Piece) ([]Cell, error) {
-	var moves []Cell
-	direction := int(g.PawnDirections[pawn.Color])
-
-	from, onBoard := g.Board.Find(pawn)
-	if !onBoard {
-		return nil, ErrNotOnBoard
-	}
-
-	cell := Cell{Row: from.Row + direction, Col: from.Col}
-	if cell.Valid() && g.Board.At(cell) == nil {
-		moves = append(moves, cell)
-	}
-
-	captureCells := []Cell{
-		{Row: from.Row + direction, Col: from.Col - 1},
-		{Row: from.Row + direction, Col: from.Col + 1},
-	}
-	for _, cell := range captureCells {
-		if !cell.Valid() {
-			continue
-		}
-
-		otherPiece := g.Board.At(cell)
-		if otherPiece != nil && otherPiece.Color != pawn.Color {
-			moves = append(moves, cell)
-		}
-	}
-
-	return moves, nil
+	return moves
 }
 
-func (g *Game) slideMoves(piece *Piece, directions []direction) ([]Cell, error) {
-	var moves []Cell
-
-	from, found := g.Board.Find(piece)
-	if !found {
-		return nil, ErrNotOnBoard
+// AllLegalMoves returns every legal move for the side to move, as generated
+// by Position.GenerateMoves: drops of pieces in hand onto empty cells (by
+// piece kind, then row by row), followed by moves of pieces on the board
+// (by piece kind, then row by row of the target cell).
+// Only Piece, From, To, Drop, Capture and Captured are set; the rest of the
+// Move is filled in when the move is played.
+// Returns nil if the game is over.
+func (g *Game) AllLegalMoves() []Move {
+	if g.Status == GameOver {
+		return nil
 	}
 
-	for _, dir := range directions {
-		cell := from
-		for range BoardSize - 1 {
-			cell = Cell{cell.Row + dir[0], cell.Col + dir[1]}
+	p := g.Position()
+	var buf [MaxMoves]PositionMove
+	generated := p.GenerateMoves(buf[:0])
 
-			allowed, capture := g.canMoveTo(piece, cell)
-			if allowed {
-				moves = append(moves, cell)
-
-				if capture {
-					break
-				}
-			} else {
-				break
+	moves := make([]Move, len(generated))
+	for i, m := range generated {
+		move := Move{Piece: Piece{Color: g.Turn, Kind: m.Kind}, To: m.To.Cell(), Drop: m.Drop()}
+		if !move.Drop {
+			move.From = m.From.Cell()
+			if captured := g.Board.At(move.To); captured != nil {
+				move.Capture = true
+				move.Captured = *captured
 			}
 		}
+		moves[i] = move
 	}
 
-	return moves, nil
+	return moves
 }
 
-func (g *Game) canMoveTo(piece *Piece, cell Cell) (allowed, capture bool) {
-	if !cell.Valid() {
-		return false, false
-	}
-
-	otherPiece := g.Board.At(cell)
-	if otherPiece == nil {
-		return true, false
-	}
-
-	if piece.Color != otherPiece.Color {
-		return true, true // can capture
-	}
-
-	return false, false
+// Play applies a move, typically one returned by AllLegalMoves.
+func (g *Game) Play(m Move) error {
+	return g.Move(m.Piece, m.To)
 }
@@ -9,8 +9,7 @@ func TestRookMoves(t *testing.T) {
 	rook := g.Piece(WhiteRook)
 	g.Board[0][0] = rook
 
-	moves, err := g.rookMoves(rook)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*rook)
 
 	expected := []Cell{
 		{0, 1}, {0, 2}, {0, 3},
@@ -28,8 +27,7 @@ func TestRookMovesWithObstacle(t *testing.T) {
 	g.Board[0][0] = rook
 	g.Board[0][2] = g.Piece(WhitePawn)
 
-	moves, err := g.rookMoves(rook)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*rook)
 
 	expected := []Cell{
 		{0, 1},
@@ -47,8 +45,7 @@ func TestRookMovesWithCapture(t *testing.T) {
 	g.Board[0][0] = rook
 	g.Board[0][2] = g.Piece(BlackPawn)
 
-	moves, err := g.rookMoves(rook)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*rook)
 
 	expected := []Cell{
 		{0, 1}, {0, 2},
@@ -65,15 +62,12 @@ func TestBishopMoves(t *testing.T) {
 	bishop := g.Piece(WhiteBishop)
 	g.Board[2][2] = bishop
 
-	moves, err := g.bishopMoves(bishop)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*bishop)
 
 	expected := []Cell{
-		{1, 3},
-		{3, 3},
-		{3, 1},
-		{1, 1},
 		{0, 0},
+		{1, 1}, {1, 3},
+		{3, 1}, {3, 3},
 	}
 
 	expectCells(t, moves, expected)
@@ -85,13 +79,11 @@ func TestBishopMovesWithObstacle(t *testing.T) {
 	g.Board[2][2] = bishop
 	g.Board[1][1] = g.Piece(WhitePawn)
 
-	moves, err := g.bishopMoves(bishop)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*bishop)
 
 	expected := []Cell{
 		{1, 3},
-		{3, 3},
-		{3, 1},
+		{3, 1}, {3, 3},
 	}
 
 	expectCells(t, moves, expected)
@@ -103,14 +95,11 @@ func TestBishopMovesWithCapture(t *testing.T) {
 	g.Board[2][2] = bishop
 	g.Board[1][1] = g.Piece(BlackPawn)
 
-	moves, err := g.bishopMoves(bishop)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*bishop)
 
 	expected := []Cell{
-		{1, 3},
-		{3, 3},
-		{3, 1},
-		{1, 1},
+		{1, 1}, {1, 3},
+		{3, 1}, {3, 3},
 	}
 
 	expectCells(t, moves, expected)
@@ -121,14 +110,12 @@ func TestKnightMoves(t *testing.T) {
 	knight := g.Piece(WhiteKnight)
 	g.Board[1][1] = knight
 
-	moves, err := g.knightMoves(knight)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*knight)
 
 	expected := []Cell{
 		{0, 3},
 		{2, 3},
-		{3, 2},
-		{3, 0},
+		{3, 0}, {3, 2},
 	}
 
 	expectCells(t, moves, expected)
@@ -140,8 +127,7 @@ func TestKnightMovesWithObstacle(t *testing.T) {
 	g.Board[1][1] = knight
 	g.Board[3][0] = g.Piece(WhitePawn)
 
-	moves, err := g.knightMoves(knight)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*knight)
 
 	expected := []Cell{
 		{0, 3},
@@ -158,14 +144,12 @@ func TestKnightMovesWithCapture(t *testing.T) {
 	g.Board[1][1] = knight
 	g.Board[3][0] = g.Piece(BlackPawn)
 
-	moves, err := g.knightMoves(knight)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*knight)
 
 	expected := []Cell{
 		{0, 3},
 		{2, 3},
-		{3, 2},
-		{3, 0},
+		{3, 0}, {3, 2},
 	}
 
 	expectCells(t, moves, expected)
@@ -177,8 +161,7 @@ func TestPawnMoves(t *testing.T) {
 	g.Board[2][2] = pawn
 	g.PawnDirections[White] = ToBlackSide
 
-	moves, err := g.pawnMoves(pawn)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*pawn)
 
 	expected := []Cell{
 		{1, 2},
@@ -194,8 +177,7 @@ func TestPawnMovesWithObstacle(t *testing.T) {
 	g.Board[1][2] = g.Piece(WhiteRook)
 	g.PawnDirections[White] = ToBlackSide
 
-	moves, err := g.pawnMoves(pawn)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*pawn)
 
 	var expected []Cell
 	expectCells(t, moves, expected)
@@ -209,13 +191,10 @@ func TestPawnMovesWithCapture(t *testing.T) {
 	g.Board[1][3] = g.Piece(BlackKnight)
 	g.PawnDirections[White] = ToBlackSide
 
-	moves, err := g.pawnMoves(pawn)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*pawn)
 
 	expected := []Cell{
-		{1, 2},
-		{1, 1},
-		{1, 3},
+		{1, 1}, {1, 2}, {1, 3},
 	}
 
 	expectCells(t, moves, expected)
@@ -228,13 +207,91 @@ func TestPawnMovesWithCaptureAtTheEdge(t *testing.T) {
 	g.Board[1][1] = g.Piece(BlackPawn)
 	g.PawnDirections[White] = ToBlackSide
 
-	moves, err := g.pawnMoves(pawn)
-	expectNoError(t, err)
+	moves := g.LegalMoves(*pawn)
 
 	expected := []Cell{
-		{1, 0},
-		{1, 1},
+		{1, 0}, {1, 1},
 	}
 
 	expectCells(t, moves, expected)
 }
+
+func TestAllLegalMovesOnEmptyBoard(t *testing.T) {
+	g := NewGame()
+
+	moves := g.AllLegalMoves()
+	expectEqual(t, len(moves), len(StandardRules.Kinds())*BoardSize*BoardSize)
+
+	for _, move := range moves {
+		expectEqual(t, move.Drop, true)
+		expectEqual(t, move.Piece.Color, White)
+	}
+	expectEqual(t, moves[0], Move{Piece: WhitePawn, To: Cell{0, 0}, Drop: true})
+}
+
+func TestAllLegalMovesIncludesDropsAndBoardMoves(t *testing.T) {
+	g := NewGame()
+	g.Board[0][0] = g.Piece(WhiteRook)
+	g.Board[0][2] = g.Piece(BlackPawn)
+	g.Board[3][3] = g.Piece(WhiteKnight)
+
+	moves := g.AllLegalMoves()
+
+	drops := 0
+	for _, move := range moves {
+		if move.Drop {
+			drops++
+			if g.Board.At(move.To) != nil {
+				t.Errorf("drop onto occupied cell %v", move.To)
+			}
+		}
+	}
+	expectEqual(t, drops, 2*13) // pawn and bishop in hand, 13 empty cells
+
+	var rookTargets, knightTargets []Cell
+	for _, move := range moves {
+		switch {
+		case move.Drop:
+		case move.Piece == WhiteRook:
+			expectEqual(t, move.From, Cell{0, 0})
+			rookTargets = append(rookTargets, move.To)
+		case move.Piece == WhiteKnight:
+			expectEqual(t, move.From, Cell{3, 3})
+			knightTargets = append(knightTargets, move.To)
+		default:
+			t.Errorf("unexpected move %v", move)
+		}
+	}
+	expectCells(t, rookTargets, g.LegalMoves(WhiteRook))
+	expectCells(t, knightTargets, g.LegalMoves(WhiteKnight))
+
+	for _, move := range moves {
+		if move.To == (Cell{0, 2}) && !move.Drop {
+			expectEqual(t, move.Capture, true)
+			expectEqual(t, move.Captured, BlackPawn)
+		}
+	}
+}
+
+func TestAllLegalMovesArePlayable(t *testing.T) {
+	g := NewGame()
+	g.Move(WhiteBishop, Cell{1, 1})
+	g.Move(BlackBishop, Cell{2, 2})
+	g.Move(WhitePawn, Cell{2, 1})
+
+	for _, move := range g.AllLegalMoves() {
+		clone := g.Clone()
+		if err := clone.Play(move); err != nil {
+			t.Errorf("legal move %v was rejected: %v", move, err)
+		}
+	}
+}
+
+func TestAllLegalMovesWhenGameIsOver(t *testing.T) {
+	g := NewGame()
+	g.Status = GameOver
+
+	if moves := g.AllLegalMoves(); moves != nil {
+		t.Errorf("expected no moves, got %v", moves)
+	}
+}
@@ -0,0 +1,103 @@
+package engine
+
+import "strings"
+
+// PerftCount is the number of move paths below one first move.
+type PerftCount struct {
+	Move  PositionMove
+	Nodes uint64
+}
+
+// Perft counts the move paths of exactly depth moves from the position.
+// A winning move ends its path: a position with four in a row has no moves,
+// so it only counts as a leaf at the full depth. Draw rules are not applied,
+// since a Position has no history.
+func Perft(p Position, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	if _, won := p.Winner(); won {
+		return 0
+	}
+
+	return perft(&p, depth)
+}
+
+// PerftDivide splits Perft by first move, in GenerateMoves order.
+func PerftDivide(p Position, depth int) []PerftCount {
+	if depth == 0 {
+		return nil
+	}
+	if _, won := p.Winner(); won {
+		return nil
+	}
+
+	var counts []PerftCount
+	for _, m := range p.GenerateMoves(nil) {
+		counts = append(counts, PerftCount{Move: m, Nodes: perftAfter(&p, m, depth-1)})
+	}
+
+	return counts
+}
+
+// perft expects a position without a winner.
+func perft(p *Position, depth int) uint64 {
+	var buf [MaxMoves]PositionMove
+	moves := p.GenerateMoves(buf[:0])
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+
+	var nodes uint64
+	for _, m := range moves {
+		nodes += perftAfter(p, m, depth-1)
+	}
+
+	return nodes
+}
+
+func perftAfter(p *Position, m PositionMove, depth int) uint64 {
+	mover := p.Turn
+	u := p.MakeMove(m)
+
+	var nodes uint64
+	switch {
+	case depth == 0:
+		nodes = 1
+	case p.HasFourInARow(mover):
+		nodes = 0
+	default:
+		nodes = perft(p, depth)
+	}
+
+	p.UnmakeMove(m, u)
+	return nodes
+}
+
+// String returns the square in board notation on the standard board,
+// see Name.
+func (s Square) String() string {
+	return s.Name(BoardSize)
+}
+
+// String writes the move on the standard board, see Name.
+func (m PositionMove) String() string {
+	return m.Name(BoardSize)
+}
+
+// Name writes drops as N@b2 and board moves as Nb3-c2, with squares named
+// for the given board size.
+func (m PositionMove) Name(size int) string {
+	var out strings.Builder
+
+	out.WriteString(m.Kind.String())
+	if m.Drop() {
+		out.WriteString("@")
+	} else {
+		out.WriteString(m.From.Name(size))
+		out.WriteString("-")
+	}
+	out.WriteString(m.To.Name(size))
+
+	return out.String()
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+type perftCase struct {
+	fen   string
+	depth int
+	nodes uint64
+}
+
+func readPerftSuite(t *testing.T) []perftCase {
+	t.Helper()
+
+	f, err := os.Open("testdata/perft.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var cases []perftCase
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ";")
+		fen := strings.TrimSpace(fields[0])
+		for _, field := range fields[1:] {
+			var c perftCase
+			if _, err := fmt.Sscanf(strings.TrimSpace(field), "D%d %d", &c.depth, &c.nodes); err != nil {
+				t.Fatalf("malformed perft entry %q: %v", field, err)
+			}
+			c.fen = fen
+			cases = append(cases, c)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return cases
+}
+
+func TestPerftSuite(t *testing.T) {
+	for _, c := range readPerftSuite(t) {
+		if testing.Short() && c.nodes > 1_000_000 {
+			continue
+		}
+
+		t.Run(fmt.Sprintf("%s/D%d", c.fen, c.depth), func(t *testing.T) {
+			p := gameFromFEN(t, c.fen).Position()
+			expectEqual(t, Perft(p, c.depth), c.nodes)
+		})
+	}
+}
+
+// gamePerft is Perft written with the Game API only, as an independent
+// check of the bitboard move generator.
+func gamePerft(g *Game, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+
+	var nodes uint64
+	for _, m := range g.AllLegalMoves() {
+		if err := g.Play(m); err != nil {
+			panic(err)
+		}
+		if g.Status != GameOver || depth == 1 {
+			nodes += gamePerft(g, depth-1)
+		}
+		if err := g.Undo(); err != nil {
+			panic(err)
+		}
+	}
+
+	return nodes
+}
+
+func TestPerftMatchesGame(t *testing.T) {
+	for _, c := range readPerftSuite(t) {
+		if c.depth > 3 {
+			continue
+		}
+
+		g := gameFromFEN(t, c.fen)
+		g.NoProgressLimit = 0
+		expectEqual(t, gamePerft(g, c.depth), c.nodes)
+	}
+}
+
+func TestPerftDivideAddsUp(t *testing.T) {
+	p := NewPosition()
+
+	var total uint64
+	counts := PerftDivide(p, 2)
+	for _, count := range counts {
+		total += count.Nodes
+	}
+
+	expectEqual(t, len(counts), 64)
+	expectEqual(t, counts[0].Move.String(), "P@a4")
+	expectEqual(t, total, Perft(p, 2))
+}
+
+func TestPerftWonPosition(t *testing.T) {
+	p := gameFromFEN(t, "R2b/P2p/B2r/N3 n w ud 0 7").Position()
+
+	expectEqual(t, Perft(p, 0), uint64(1))
+	expectEqual(t, Perft(p, 1), uint64(0))
+	expectEqual(t, len(PerftDivide(p, 1)), 0)
+}
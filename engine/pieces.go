@@ -7,15 +7,22 @@ var (
 	WhiteRook   = Piece{Color: White, Kind: Rook}
 	WhiteBishop = Piece{Color: White, Kind: Bishop}
 	WhiteKnight = Piece{Color: White, Kind: Knight}
+	WhiteQueen  = Piece{Color: White, Kind: Queen}
 
 	BlackPawn   = Piece{Color: Black, Kind: Pawn}
 	BlackRook   = Piece{Color: Black, Kind: Rook}
 	BlackBishop = Piece{Color: Black, Kind: Bishop}
 	BlackKnight = Piece{Color: Black, Kind: Knight}
+	BlackQueen  = Piece{Color: Black, Kind: Queen}
 )
 
 var colorNames = [ColorCount]string{"White", "Black"}
-var kindNames = [PieceKindCount]string{"Pawn", "Rook", "Bishop", "Knight"}
+var kindNames = [PieceKindCount]string{"Pawn", "Rook", "Bishop", "Knight", "Queen"}
+
+// Name returns the full name of the kind, e.g. "Knight".
+func (k PieceKind) Name() string {
+	return kindNames[k]
+}
 
 func (p *Piece) FriendlyName() string {
 	return colorNames[p.Color] + " " + kindNames[p.Kind]
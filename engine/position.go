@@ -0,0 +1,258 @@
+package engine
+
+// Position is a compact, pointer-free form of a game position built on
+// bitboards. It is meant for search: copying it is cheap, and MakeMove and
+// UnmakeMove update it in place without allocating.
+//
+// A Position knows only the rules, board, hands, side to move and pawn
+// directions. Move counters, history and draw rules stay with Game.
+type Position struct {
+	Rules Rules
+	// Squares holds where each piece is, InHand, or Discarded for pieces
+	// out of play.
+	Squares        [ColorCount][PieceKindCount]Square
+	Occupied       [ColorCount]Bitboard
+	Turn           Color
+	PawnDirections PawnDirections
+}
+
+// PositionMove is a move on a Position: a piece of the side to move goes
+// from From (InHand for a drop) to To.
+type PositionMove struct {
+	Kind PieceKind
+	From Square
+	To   Square
+}
+
+func (m PositionMove) Drop() bool {
+	return m.From == InHand
+}
+
+// Unmove holds what MakeMove changed beyond the move itself,
+// so that UnmakeMove can restore the position.
+type Unmove struct {
+	Capture        bool
+	Captured       PieceKind
+	PawnDirections PawnDirections
+}
+
+// MaxMoves bounds the number of moves in any position under any rules:
+// every piece in hand may be dropped on every empty cell, and board pieces
+// have at most a handful of targets each. It sizes move buffers so that
+// GenerateMoves never has to grow them.
+const MaxMoves = 128
+
+// NewPosition returns the initial position under the given rules, or
+// StandardRules if none are given: empty board, all pieces in hand.
+func NewPosition(rules ...Rules) Position {
+	return NewGame(rules...).Position()
+}
+
+// Position returns the current position of the game.
+func (g *Game) Position() Position {
+	if !g.positioned {
+		return g.scanPosition()
+	}
+	p := g.pos
+	p.Turn = g.Turn
+	p.PawnDirections = g.PawnDirections
+	return p
+}
+
+// scanPosition builds the position of the game from its board.
+func (g *Game) scanPosition() Position {
+	p := Position{Rules: g.Rules, Turn: g.Turn, PawnDirections: g.PawnDirections}
+	for color := range ColorCount {
+		for kind := range PieceKindCount {
+			p.Squares[color][kind] = InHand
+			if !g.Rules.HasKind(kind) || g.Discarded[color][kind] {
+				p.Squares[color][kind] = Discarded
+			}
+		}
+	}
+
+	for row := range g.Rules.Size() {
+		for col := range g.Rules.Size() {
+			if piece := g.Board[row][col]; piece != nil {
+				s := SquareOf(Cell{row, col})
+				p.Squares[piece.Color][piece.Kind] = s
+				p.Occupied[piece.Color] |= s.Bit()
+			}
+		}
+	}
+
+	return p
+}
+
+// PieceAt returns the piece on the square, if any.
+func (p *Position) PieceAt(s Square) (Piece, bool) {
+	for color := range ColorCount {
+		if !p.Occupied[color].Has(s) {
+			continue
+		}
+		for kind := range PieceKindCount {
+			if p.Squares[color][kind] == s {
+				return Piece{Color: color, Kind: kind}, true
+			}
+		}
+	}
+
+	return Piece{}, false
+}
+
+// Attacks returns the squares a piece on the board can move to,
+// including captures. It is empty for a piece off the board.
+func (p *Position) Attacks(color Color, kind PieceKind) Bitboard {
+	s := p.Squares[color][kind]
+	if s < 0 {
+		return 0
+	}
+	t := tablesFor(p.Rules)
+
+	own := p.Occupied[color]
+	opponent := p.Occupied[1-color]
+	occupied := own | opponent
+
+	switch kind {
+	case Pawn:
+		i := pawnDirectionIndex(p.PawnDirections[color])
+		return t.pawnPushes[i][s]&^occupied | t.pawnCaptures[i][s]&opponent
+	case Rook:
+		return t.slideAttacks(s, occupied, rookRays) &^ own
+	case Bishop:
+		return t.slideAttacks(s, occupied, bishopRays) &^ own
+	case Knight:
+		return t.knight[s] &^ own
+	case Queen:
+		return t.slideAttacks(s, occupied, queenRays) &^ own
+	}
+
+	panic("invalid piece kind")
+}
+
+// GenerateMoves appends the legal moves of the side to move to moves and
+// returns the result: drops by piece kind and square, then board moves
+// by piece kind and target square. With a buffer of MaxMoves capacity it
+// does not allocate. It does not check whether the game is already won.
+func (p *Position) GenerateMoves(moves []PositionMove) []PositionMove {
+	empty := tablesFor(p.Rules).cells &^ (p.Occupied[White] | p.Occupied[Black])
+
+	for kind := range PieceKindCount {
+		if p.Squares[p.Turn][kind] != InHand {
+			continue
+		}
+		for targets := empty; targets != 0; {
+			moves = append(moves, PositionMove{Kind: kind, From: InHand, To: targets.PopFirst()})
+		}
+	}
+
+	for kind := range PieceKindCount {
+		from := p.Squares[p.Turn][kind]
+		if from < 0 {
+			continue
+		}
+		for targets := p.Attacks(p.Turn, kind); targets != 0; {
+			moves = append(moves, PositionMove{Kind: kind, From: from, To: targets.PopFirst()})
+		}
+	}
+
+	return moves
+}
+
+// MakeMove plays a legal move: the piece moves or is dropped, a captured
+// piece goes back to its owner's hand (or out of play under
+// Rules.DiscardCaptures), and the turn passes to the opponent
+// with pawn directions updated the way Game does it.
+// It does not check for a win; see Winner.
+func (p *Position) MakeMove(m PositionMove) Unmove {
+	u := Unmove{PawnDirections: p.PawnDirections}
+	opponent := 1 - p.Turn
+
+	if p.Occupied[opponent].Has(m.To) {
+		for kind := range PieceKindCount {
+			if p.Squares[opponent][kind] == m.To {
+				u.Capture = true
+				u.Captured = kind
+				p.Squares[opponent][kind] = InHand
+				if p.Rules.DiscardCaptures {
+					p.Squares[opponent][kind] = Discarded
+				}
+				p.Occupied[opponent] &^= m.To.Bit()
+				break
+			}
+		}
+	}
+
+	if m.From != InHand {
+		p.Occupied[p.Turn] &^= m.From.Bit()
+	}
+	p.Occupied[p.Turn] |= m.To.Bit()
+	p.Squares[p.Turn][m.Kind] = m.To
+
+	p.Turn = opponent
+	p.updatePawnDirection(White)
+	p.updatePawnDirection(Black)
+
+	return u
+}
+
+// UnmakeMove takes back a move made with MakeMove.
+func (p *Position) UnmakeMove(m PositionMove, u Unmove) {
+	p.Turn = 1 - p.Turn
+	p.PawnDirections = u.PawnDirections
+
+	p.Occupied[p.Turn] &^= m.To.Bit()
+	p.Squares[p.Turn][m.Kind] = m.From
+	if m.From != InHand {
+		p.Occupied[p.Turn] |= m.From.Bit()
+	}
+
+	if u.Capture {
+		opponent := 1 - p.Turn
+		p.Squares[opponent][u.Captured] = m.To
+		p.Occupied[opponent] |= m.To.Bit()
+	}
+}
+
+// HasFourInARow reports whether the color has a winning line: a row, column
+// or diagonal of Rules.LineLength pieces, four on the standard board.
+func (p *Position) HasFourInARow(color Color) bool {
+	return hasLine(p.Occupied[color], p.Rules)
+}
+
+// Winner returns the side that has a winning line, if any.
+// After MakeMove, only the side that just moved can have won.
+func (p *Position) Winner() (Color, bool) {
+	for color := range ColorCount {
+		if p.HasFourInARow(color) {
+			return color, true
+		}
+	}
+	return 0, false
+}
+
+// updatePawnDirection turns the color's pawn around at the far edge, and
+// back to its starting direction when it is in hand.
+func (p *Position) updatePawnDirection(color Color) {
+	s := p.Squares[color][Pawn]
+	if s < 0 {
+		if color == White {
+			p.PawnDirections[White] = ToBlackSide
+		} else {
+			p.PawnDirections[Black] = ToWhiteSide
+		}
+		return
+	}
+
+	if p.Rules.KeepPawnDirection {
+		return
+	}
+
+	row := s.Cell().Row
+	if p.PawnDirections[color] == ToBlackSide && row == int(BlackSide) {
+		p.PawnDirections[color] = ToWhiteSide
+	}
+	if p.PawnDirections[color] == ToWhiteSide && row == p.Rules.WhiteSide() {
+		p.PawnDirections[color] = ToBlackSide
+	}
+}
@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func positionMoveOf(m Move) PositionMove {
+	from := InHand
+	if !m.Drop {
+		from = SquareOf(m.From)
+	}
+	return PositionMove{Kind: m.Piece.Kind, From: from, To: SquareOf(m.To)}
+}
+
+func TestSquareCellRoundTrip(t *testing.T) {
+	for s := range Square(SquareCount) {
+		expectEqual(t, SquareOf(s.Cell()), s)
+	}
+	expectEqual(t, SquareOf(Cell{3, 0}), Square(3*MaxBoardSize))
+}
+
+func TestWinMasksMatchBoardLines(t *testing.T) {
+	g := NewGame()
+	lines := g.Board.Lines()
+	expectEqual(t, len(WinMasks(g.Rules)), len(lines))
+
+	for _, mask := range WinMasks(g.Rules) {
+		expectEqual(t, mask.Count(), BoardSize)
+	}
+}
+
+func TestBoardLinesHaveBoardSizeCells(t *testing.T) {
+	g := NewGame()
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 3}))
+
+	lines := g.Board.Lines()
+	expectEqual(t, len(lines), 2*BoardSize+2)
+	for _, line := range lines {
+		expectEqual(t, len(line), BoardSize)
+	}
+	expectEqual(t, lines[0][3], g.Piece(WhiteRook))
+}
+
+func TestWinMasksOfVariants(t *testing.T) {
+	// 5 rows, 5 columns and 2 diagonals
+	expectEqual(t, len(WinMasks(Rules{BoardSize: 5})), 12)
+	// 2 per row and column, 2 long and 2 short diagonals each way
+	expectEqual(t, len(WinMasks(Rules{BoardSize: 5, WinLength: 4})), 28)
+	// 2 per row and column, 4 diagonals each way
+	expectEqual(t, len(WinMasks(Rules{BoardSize: 4, WinLength: 3})), 24)
+
+	for _, mask := range WinMasks(Rules{BoardSize: 5, WinLength: 3}) {
+		expectEqual(t, mask.Count(), 3)
+	}
+}
+
+func TestPositionOfNewGame(t *testing.T) {
+	p := NewPosition()
+
+	expectEqual(t, p.Turn, White)
+	expectEqual(t, p.Occupied[White]|p.Occupied[Black], Bitboard(0))
+	expectEqual(t, len(p.GenerateMoves(nil)), len(StandardRules.Kinds())*BoardSize*BoardSize)
+
+	p = NewPosition(Rules{BoardSize: 5, Queen: true})
+	expectEqual(t, len(p.GenerateMoves(nil)), int(PieceKindCount)*MaxBoardSize*MaxBoardSize)
+}
+
+func TestPositionAttacks(t *testing.T) {
+	g := gameFromFEN(t, "2b1/4/2R1/N1p1 PBrn w ud 0 4")
+	p := g.Position()
+
+	// the rook on c2 stops at the black bishop on c4 and pawn on c1,
+	// the knight on a1 can not jump onto its own rook
+	rook := p.Attacks(White, Rook)
+	for _, cell := range g.LegalMoves(WhiteRook) {
+		expectEqual(t, rook.Has(SquareOf(cell)), true)
+	}
+	expectEqual(t, rook.Count(), 6)
+	expectEqual(t, len(g.LegalMoves(WhiteRook)), 6)
+
+	knight := p.Attacks(White, Knight)
+	expectEqual(t, knight, SquareOf(Cell{1, 1}).Bit())
+
+	expectEqual(t, p.Attacks(White, Pawn), Bitboard(0)) // in hand
+}
+
+// TestPositionMatchesGame plays random games on a Game and a Position side
+// by side and checks that they agree on moves, resulting positions and
+// wins, and that UnmakeMove restores the position exactly.
+func TestPositionMatchesGame(t *testing.T) {
+	for _, rules := range []Rules{
+		StandardRules,
+		{BoardSize: 5, WinLength: 4, Queen: true},
+		{BoardSize: 5, DiscardCaptures: true, KeepPawnDirection: true},
+		{BoardSize: 4, WinLength: 3, Queen: true, DiscardCaptures: true},
+	} {
+		t.Run(rules.String(), func(t *testing.T) {
+			testPositionMatchesGame(t, rules)
+		})
+	}
+}
+
+func testPositionMatchesGame(t *testing.T, rules Rules) {
+	rng := rand.New(rand.NewSource(1))
+
+	for range 200 {
+		g := NewGame(rules)
+		g.NoProgressLimit = 0
+		p := g.Position()
+
+		for range 60 {
+			legal := g.AllLegalMoves()
+			if len(legal) == 0 {
+				break
+			}
+
+			want := map[PositionMove]bool{}
+			for _, m := range legal {
+				want[positionMoveOf(m)] = true
+			}
+			generated := p.GenerateMoves(nil)
+			expectEqual(t, len(generated), len(want))
+			for _, m := range generated {
+				if !want[m] {
+					t.Fatalf("position %s: unexpected move %+v", FormatFEN(g), m)
+				}
+			}
+
+			m := legal[rng.Intn(len(legal))]
+			before := p
+			u := p.MakeMove(positionMoveOf(m))
+
+			after := p
+			p.UnmakeMove(positionMoveOf(m), u)
+			expectEqual(t, p, before)
+			p = after
+
+			expectNoError(t, g.Play(m))
+			actual := g.Position()
+			expectEqual(t, actual, g.scanPosition())
+			expectEqual(t, p.Squares, actual.Squares)
+			expectEqual(t, p.Occupied, actual.Occupied)
+
+			winner, won := p.Winner()
+			expectEqual(t, won, g.Winner != nil)
+			if won {
+				expectEqual(t, winner, *g.Winner)
+				break
+			}
+			expectEqual(t, p, actual)
+		}
+	}
+}
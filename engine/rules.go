@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxBoardSize is the largest board any Rules allow.
+const MaxBoardSize = 5
+
+// Rules configures a variant of the game. The zero value is the standard
+// game: a 4×4 board, four in a row to win, captured pieces return to their
+// owner's hand, pawns reverse at the far edge and there is no Queen.
+type Rules struct {
+	// BoardSize is the side of the board, BoardSize (4) or MaxBoardSize (5).
+	// 0 means BoardSize.
+	BoardSize int
+	// WinLength is how many pieces in a row win, at most the board size.
+	// 0 means the board size.
+	WinLength int
+	// DiscardCaptures takes captured pieces out of the game
+	// instead of returning them to their owner's hand.
+	DiscardCaptures bool
+	// KeepPawnDirection stops pawns from turning around at the far edge.
+	KeepPawnDirection bool
+	// Queen gives each side a Queen, which moves like a rook or a bishop.
+	Queen bool
+}
+
+var ErrInvalidRules = errors.New("invalid rules")
+
+// StandardRules are the rules of the original game.
+var StandardRules = Rules{BoardSize: BoardSize, WinLength: BoardSize}
+
+// Size returns the side of the board.
+func (r Rules) Size() int {
+	if r.BoardSize == 0 {
+		return BoardSize
+	}
+	return r.BoardSize
+}
+
+// LineLength returns how many pieces in a row win.
+func (r Rules) LineLength() int {
+	if r.WinLength == 0 {
+		return r.Size()
+	}
+	return r.WinLength
+}
+
+// Normalized returns the rules with board size and win length filled in.
+func (r Rules) Normalized() Rules {
+	r.BoardSize = r.Size()
+	r.WinLength = r.LineLength()
+	return r
+}
+
+// Standard reports whether these are the rules of the original game.
+func (r Rules) Standard() bool {
+	return r.Normalized() == StandardRules
+}
+
+func (r Rules) Validate() error {
+	size := r.Size()
+	if size < BoardSize || size > MaxBoardSize {
+		return fmt.Errorf("%w: board size must be between %d and %d, got %d", ErrInvalidRules, BoardSize, MaxBoardSize, size)
+	}
+	if length := r.LineLength(); length < 3 || length > size {
+		return fmt.Errorf("%w: win length must be between 3 and %d, got %d", ErrInvalidRules, size, length)
+	}
+	return nil
+}
+
+// HasKind reports whether each side has a piece of this kind.
+func (r Rules) HasKind(kind PieceKind) bool {
+	return kind != Queen || r.Queen
+}
+
+// Kinds returns the piece kinds in play.
+func (r Rules) Kinds() []PieceKind {
+	kinds := make([]PieceKind, 0, PieceKindCount)
+	for kind := range PieceKindCount {
+		if r.HasKind(kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// Contains reports whether the cell is on the board.
+func (r Rules) Contains(c Cell) bool {
+	size := r.Size()
+	return c.Row >= 0 && c.Row < size && c.Col >= 0 && c.Col < size
+}
+
+// WhiteSide returns the row at White's edge of the board, the bottom one.
+// Black's edge is row BlackSide.
+func (r Rules) WhiteSide() int {
+	return r.Size() - 1
+}
+
+// String writes the rules compactly, e.g. "4x4" for the standard game or
+// "5x5-4+queen+discard+keeppawn" for a 5×5 board where four in a row win,
+// with a Queen, captured pieces discarded and pawns keeping their direction.
+// ParseRules reads it back.
+func (r Rules) String() string {
+	var out strings.Builder
+
+	size := r.Size()
+	fmt.Fprintf(&out, "%dx%d", size, size)
+	if length := r.LineLength(); length != size {
+		fmt.Fprintf(&out, "-%d", length)
+	}
+	if r.Queen {
+		out.WriteString("+queen")
+	}
+	if r.DiscardCaptures {
+		out.WriteString("+discard")
+	}
+	if r.KeepPawnDirection {
+		out.WriteString("+keeppawn")
+	}
+
+	return out.String()
+}
+
+// ParseRules reads rules written by Rules.String. The empty string and
+// "standard" stand for StandardRules.
+func ParseRules(s string) (Rules, error) {
+	if s == "" || s == "standard" {
+		return StandardRules, nil
+	}
+
+	parts := strings.Split(s, "+")
+	var r Rules
+
+	board, length, hasLength := strings.Cut(parts[0], "-")
+	rows, cols, ok := strings.Cut(board, "x")
+	if !ok || rows != cols {
+		return Rules{}, fmt.Errorf("%w: board %q", ErrInvalidRules, board)
+	}
+	size, err := strconv.Atoi(rows)
+	if err != nil {
+		return Rules{}, fmt.Errorf("%w: board %q", ErrInvalidRules, board)
+	}
+	r.BoardSize = size
+	r.WinLength = size
+	if hasLength {
+		if r.WinLength, err = strconv.Atoi(length); err != nil {
+			return Rules{}, fmt.Errorf("%w: win length %q", ErrInvalidRules, length)
+		}
+	}
+
+	for _, option := range parts[1:] {
+		switch option {
+		case "queen":
+			r.Queen = true
+		case "discard":
+			r.DiscardCaptures = true
+		case "keeppawn":
+			r.KeepPawnDirection = true
+		default:
+			return Rules{}, fmt.Errorf("%w: unknown option %q", ErrInvalidRules, option)
+		}
+	}
+
+	if err := r.Validate(); err != nil {
+		return Rules{}, err
+	}
+	return r, nil
+}
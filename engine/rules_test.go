@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"testing"
+)
+
+func TestRulesStringRoundTrip(t *testing.T) {
+	for _, rules := range []Rules{
+		StandardRules,
+		{BoardSize: 5, WinLength: 5},
+		{BoardSize: 5, WinLength: 4, Queen: true},
+		{BoardSize: 4, WinLength: 3, DiscardCaptures: true, KeepPawnDirection: true},
+		{BoardSize: 5, WinLength: 3, Queen: true, DiscardCaptures: true, KeepPawnDirection: true},
+	} {
+		parsed, err := ParseRules(rules.String())
+		expectNoError(t, err)
+		expectEqual(t, parsed, rules)
+	}
+
+	expectEqual(t, StandardRules.String(), "4x4")
+	expectEqual(t, Rules{BoardSize: 5, WinLength: 4, Queen: true}.String(), "5x5-4+queen")
+}
+
+func TestRulesZeroValueIsStandard(t *testing.T) {
+	expectEqual(t, Rules{}.Standard(), true)
+	expectEqual(t, Rules{}.Normalized(), StandardRules)
+	expectEqual(t, NewGame(Rules{}).Rules, StandardRules)
+
+	parsed, err := ParseRules("")
+	expectNoError(t, err)
+	expectEqual(t, parsed, StandardRules)
+}
+
+func TestParseRulesInvalid(t *testing.T) {
+	for _, s := range []string{"6x6", "3x3", "5x4", "5x5-6", "4x4-2", "4x4+king", "four"} {
+		_, err := ParseRules(s)
+		expectError(t, err, ErrInvalidRules)
+	}
+}
+
+func TestWinLengthOnLargerBoard(t *testing.T) {
+	g := gameFromFEN(t, "5/5/5/5/RBN2 Pprbn w ud 0 6 5x5-4")
+
+	expectNoError(t, g.Move(WhitePawn, Cell{4, 3}))
+	expectEqual(t, g.Status, GameOver)
+	expectEqual(t, *g.Winner, White)
+	expectEqual(t, g.Termination, FourInARow)
+
+	// five in a row are needed by default on a 5×5 board
+	g = gameFromFEN(t, "5/5/5/5/RBN2 Pprbn w ud 0 6 5x5")
+	expectNoError(t, g.Move(WhitePawn, Cell{4, 3}))
+	expectEqual(t, g.Status, GameStarted)
+}
+
+func TestQueen(t *testing.T) {
+	g := gameFromFEN(t, "5/5/2Q2/5/5 PRBNprbnq b ud 0 1 5x5+queen")
+	expectEqual(t, len(g.LegalMoves(WhiteQueen)), 16)
+
+	g = NewGame()
+	expectError(t, g.Move(WhiteQueen, Cell{0, 0}), ErrNotInHand)
+	expectEqual(t, g.PieceInHand(WhiteQueen), false)
+}
+
+func TestDiscardCaptures(t *testing.T) {
+	g := gameFromFEN(t, "r3/4/4/R3 PBNpbn w ud 0 2 4x4+discard")
+
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 0}))
+	expectEqual(t, g.PieceInHand(BlackRook), false)
+	expectEqual(t, g.Discarded[Black][Rook], true)
+	expectError(t, g.Move(BlackRook, Cell{2, 2}), ErrNotInHand)
+	expectEqual(t, FormatFEN(g), "R3/4/4/4 PBNpbn b ud 0 3 4x4+discard")
+
+	parsed := gameFromFEN(t, FormatFEN(g))
+	expectEqual(t, parsed.Discarded, g.Discarded)
+	expectEqual(t, FormatFEN(g.Snapshot().Game()), FormatFEN(g))
+	expectEqual(t, g.Position(), parsed.Position())
+
+	expectNoError(t, g.Undo())
+	expectEqual(t, g.PieceOnBoard(BlackRook), true)
+	expectEqual(t, g.Discarded[Black][Rook], false)
+}
+
+func TestKeepPawnDirection(t *testing.T) {
+	for _, test := range []struct {
+		fen       string
+		direction PawnDirection
+	}{
+		{"4/4/P3/4 RBNprbn w ud 0 1", ToWhiteSide},
+		{"4/4/P3/4 RBNprbn w ud 0 1 4x4+keeppawn", ToBlackSide},
+	} {
+		g := gameFromFEN(t, test.fen)
+		expectNoError(t, g.Move(WhitePawn, Cell{1, 0}))
+		expectNoError(t, g.Move(BlackRook, Cell{0, 3}))
+		expectNoError(t, g.Move(WhitePawn, Cell{0, 0}))
+		expectEqual(t, g.PawnDirections[White], test.direction)
+	}
+}
@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Snapshot is an immutable copy of a game at one point in time.
+// It shares no memory with the game it was taken from: the board and hands
+// are kept as a Position value and the history is copied. A snapshot can be
+// read and marshalled from any goroutine while the game goes on; use Game
+// to get a playable copy.
+type Snapshot struct {
+	position        Position
+	status          GameStatus
+	winner          Color
+	hasWinner       bool
+	termination     Termination
+	winningLine     []Cell
+	moveCount       uint
+	quietMoveCount  uint
+	noProgressLimit uint
+	positions       []Key
+	history         []Move
+	undone          []Move
+}
+
+// Snapshot returns an immutable copy of the game's current state.
+func (g *Game) Snapshot() Snapshot {
+	s := Snapshot{
+		position:        g.Position(),
+		status:          g.Status,
+		termination:     g.Termination,
+		winningLine:     slices.Clone(g.WinningLine),
+		moveCount:       g.MoveCount,
+		quietMoveCount:  g.QuietMoveCount,
+		noProgressLimit: g.NoProgressLimit,
+		positions:       slices.Clone(g.Positions),
+		history:         slices.Clone(g.History),
+		undone:          slices.Clone(g.Undone),
+	}
+	if g.Winner != nil {
+		s.winner = *g.Winner
+		s.hasWinner = true
+	}
+
+	return s
+}
+
+// Game returns a new game in the snapshot's state. Each call returns
+// a separate game, which the caller may change freely.
+func (s Snapshot) Game() *Game {
+	g := &Game{
+		Rules:           s.position.Rules,
+		Pieces:          NewPieces(),
+		Turn:            s.position.Turn,
+		PawnDirections:  s.position.PawnDirections,
+		Status:          s.status,
+		Winner:          s.Winner(),
+		MoveCount:       s.moveCount,
+		Termination:     s.termination,
+		WinningLine:     slices.Clone(s.winningLine),
+		QuietMoveCount:  s.quietMoveCount,
+		NoProgressLimit: s.noProgressLimit,
+		Positions:       slices.Clone(s.positions),
+		History:         slices.Clone(s.history),
+		Undone:          slices.Clone(s.undone),
+	}
+
+	for color := range ColorCount {
+		for kind := range PieceKindCount {
+			switch square := s.position.Squares[color][kind]; square {
+			case InHand:
+			case Discarded:
+				g.Discarded[color][kind] = g.Rules.HasKind(kind)
+			default:
+				cell := square.Cell()
+				g.Board[cell.Row][cell.Col] = g.Pieces.Get(color, kind)
+			}
+		}
+	}
+	g.pos = s.position
+	g.positioned = true
+
+	return g
+}
+
+func (s Snapshot) Position() Position {
+	return s.position
+}
+
+func (s Snapshot) Rules() Rules {
+	return s.position.Rules
+}
+
+func (s Snapshot) Turn() Color {
+	return s.position.Turn
+}
+
+func (s Snapshot) Status() GameStatus {
+	return s.status
+}
+
+// Winner returns a new pointer to the winner, or nil if there is none,
+// like Game.Winner.
+func (s Snapshot) Winner() *Color {
+	if !s.hasWinner {
+		return nil
+	}
+	winner := s.winner
+	return &winner
+}
+
+func (s Snapshot) Termination() Termination {
+	return s.termination
+}
+
+// WinningLine returns a copy of the cells of the winning line,
+// like Game.WinningLine.
+func (s Snapshot) WinningLine() []Cell {
+	return slices.Clone(s.winningLine)
+}
+
+func (s Snapshot) MoveCount() uint {
+	return s.moveCount
+}
+
+// MarshalJSON encodes the snapshot exactly like the Game it holds,
+// so stored game states can be read back into either type.
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Game())
+}
+
+// UnmarshalJSON reads a game state as written by MarshalJSON. States
+// stored before the no-progress rule keep its default limit, and those
+// stored before Zobrist keys have their positions keyed again.
+func (s *Snapshot) UnmarshalJSON(data []byte) error {
+	stored := struct {
+		*Game
+		Positions []json.RawMessage
+	}{Game: &Game{NoProgressLimit: DefaultNoProgressLimit}}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+
+	g := stored.Game
+	var err error
+	if g.Positions, err = storedKeys(stored.Positions, g.Rules); err != nil {
+		return err
+	}
+
+	*s = g.Snapshot()
+	return nil
+}
+
+// storedKeys reads the keys of stored positions. States stored before
+// Zobrist keys hold each position as its FEN without the move counters.
+func storedKeys(positions []json.RawMessage, rules Rules) ([]Key, error) {
+	if positions == nil {
+		return nil, nil
+	}
+
+	keys := make([]Key, len(positions))
+	for i, position := range positions {
+		var fen string
+		if json.Unmarshal(position, &fen) != nil {
+			if err := json.Unmarshal(position, &keys[i]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		g, err := ParseFEN(fen + " 0 0 " + rules.String())
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = g.Key()
+	}
+	return keys, nil
+}
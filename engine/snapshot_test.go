@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSnapshotIsNotAffectedByLaterMoves(t *testing.T) {
+	g := NewGame()
+	expectNoError(t, g.Move(WhiteRook, Cell{0, 0}))
+	s := g.Snapshot()
+	before := FormatFEN(s.Game())
+
+	expectNoError(t, g.Move(BlackRook, Cell{3, 3}))
+	expectNoError(t, g.Undo())
+	expectNoError(t, g.Move(BlackKnight, Cell{2, 2}))
+
+	expectEqual(t, FormatFEN(s.Game()), before)
+	expectEqual(t, len(s.Game().History), 1)
+	expectEqual(t, s.Turn(), Black)
+	expectEqual(t, s.MoveCount(), uint(1))
+}
+
+func TestSnapshotGameIsIndependent(t *testing.T) {
+	g := NewGame()
+	expectNoError(t, g.Move(WhiteBishop, Cell{1, 1}))
+	s := g.Snapshot()
+
+	copy1 := s.Game()
+	copy2 := s.Game()
+	expectNoError(t, copy1.Move(BlackBishop, Cell{2, 2}))
+
+	expectEqual(t, copy2.PieceInHand(BlackBishop), true)
+	expectEqual(t, FormatFEN(copy2), FormatFEN(g))
+	expectEqual(t, copy2.Board.At(Cell{1, 1}), copy2.Piece(WhiteBishop))
+}
+
+func TestSnapshotOfFinishedGame(t *testing.T) {
+	g := gameFromFEN(t, "R2b/P2p/B2r/N3 n w ud 0 7")
+	s := g.Snapshot()
+
+	expectEqual(t, s.Status(), GameOver)
+	expectEqual(t, s.Termination(), FourInARow)
+	if winner := s.Winner(); winner == nil || *winner != White {
+		t.Errorf("expected White to win, got %v", winner)
+	}
+}
+
+func TestSnapshotJSONMatchesGame(t *testing.T) {
+	g := NewGame()
+	expectNoError(t, g.Move(WhitePawn, Cell{1, 0}))
+	expectNoError(t, g.Move(BlackKnight, Cell{2, 2}))
+
+	gameJSON, err := json.Marshal(g)
+	expectNoError(t, err)
+	snapshotJSON, err := json.Marshal(g.Snapshot())
+	expectNoError(t, err)
+	expectEqual(t, string(snapshotJSON), string(gameJSON))
+
+	var s Snapshot
+	expectNoError(t, json.Unmarshal(gameJSON, &s))
+	restored := s.Game()
+	expectSameGame(t, restored, g)
+	expectEqual(t, restored.Board.At(Cell{1, 0}), restored.Piece(WhitePawn))
+}
+
+func TestSnapshotJSONWithoutNoProgressLimit(t *testing.T) {
+	// a game state as stored before the draw rules
+	const stored = `{"Board":[[{"Color":0,"Kind":1},null,null,null],[null,null,null,null],[null,null,null,null],[null,null,null,{"Color":1,"Kind":2}]],"Pieces":[[{"Color":0,"Kind":0},{"Color":0,"Kind":1},{"Color":0,"Kind":2},{"Color":0,"Kind":3}],[{"Color":1,"Kind":0},{"Color":1,"Kind":1},{"Color":1,"Kind":2},{"Color":1,"Kind":3}]],"Turn":0,"PawnDirections":[-1,1],"Status":0,"Winner":null,"MoveCount":2}`
+
+	var s Snapshot
+	expectNoError(t, json.Unmarshal([]byte(stored), &s))
+	restored := s.Game()
+
+	expectEqual(t, restored.NoProgressLimit, uint(DefaultNoProgressLimit))
+	expectEqual(t, restored.MoveCount, uint(2))
+	expectEqual(t, restored.Board.At(Cell{0, 0}), restored.Piece(WhiteRook))
+	expectEqual(t, restored.Board.At(Cell{3, 3}), restored.Piece(BlackBishop))
+	expectNoError(t, restored.Move(WhiteBishop, Cell{1, 1}))
+
+	// a limit disabled on purpose stays disabled
+	g := NewGame()
+	g.NoProgressLimit = 0
+	data, err := json.Marshal(g.Snapshot())
+	expectNoError(t, err)
+	expectNoError(t, json.Unmarshal(data, &s))
+	expectEqual(t, s.Game().NoProgressLimit, uint(0))
+}
+
+func TestSnapshotJSONWithFENPositions(t *testing.T) {
+	rules, err := ParseRules("5x5")
+	expectNoError(t, err)
+	g := NewGame(rules)
+	fens := []string{g.fenPosition()}
+	for _, move := range []struct {
+		piece Piece
+		cell  Cell
+	}{
+		{WhiteRook, Cell{0, 0}}, {BlackRook, Cell{4, 4}},
+		{WhiteRook, Cell{0, 1}}, {BlackRook, Cell{4, 3}},
+		{WhiteRook, Cell{0, 0}}, {BlackRook, Cell{4, 4}},
+	} {
+		expectNoError(t, g.Move(move.piece, move.cell))
+		fens = append(fens, g.fenPosition())
+	}
+
+	// a game state as stored before Zobrist keys
+	data, err := json.Marshal(g.Snapshot())
+	expectNoError(t, err)
+	var stored map[string]any
+	expectNoError(t, json.Unmarshal(data, &stored))
+	stored["Positions"] = fens
+	data, err = json.Marshal(stored)
+	expectNoError(t, err)
+
+	var s Snapshot
+	expectNoError(t, json.Unmarshal(data, &s))
+	restored := s.Game()
+
+	expectEqual(t, len(restored.Positions), len(g.Positions))
+	for i := range g.Positions {
+		expectEqual(t, restored.Positions[i], g.Positions[i])
+	}
+	expectEqual(t, restored.Repetitions(), 2)
+}
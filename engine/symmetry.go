@@ -0,0 +1,85 @@
+package engine
+
+// Symmetry maps a position onto an equivalent one: the side to move has
+// the same moves, up to the mapping, and the same outcome.
+//
+// Mirror swaps the board left to right. SwapColors swaps the colors and
+// turns the board upside down, so that the pieces of the side to move sit
+// where the opponent's did and pawns keep heading the same way relative to
+// their owner. Both are their own inverse and can be combined.
+type Symmetry uint8
+
+const (
+	Mirror Symmetry = 1 << iota
+	SwapColors
+
+	Identity Symmetry = 0
+)
+
+// Symmetries lists every symmetry, Identity first.
+var Symmetries = [...]Symmetry{Identity, Mirror, SwapColors, Mirror | SwapColors}
+
+// Square maps a square of a board of the given size. InHand and Discarded
+// are left as they are.
+func (s Symmetry) Square(sq Square, size int) Square {
+	if sq < 0 {
+		return sq
+	}
+
+	cell := sq.Cell()
+	if s&Mirror != 0 {
+		cell.Col = size - 1 - cell.Col
+	}
+	if s&SwapColors != 0 {
+		cell.Row = size - 1 - cell.Row
+	}
+	return SquareOf(cell)
+}
+
+// Move maps a move of the side to move in a position of the given board
+// size to the same move in the mapped position.
+func (s Symmetry) Move(m PositionMove, size int) PositionMove {
+	return PositionMove{Kind: m.Kind, From: s.Square(m.From, size), To: s.Square(m.To, size)}
+}
+
+// Position returns the mapped position.
+func (s Symmetry) Position(p Position) Position {
+	size := p.Rules.Size()
+
+	mapped := Position{Rules: p.Rules, Turn: p.Turn, PawnDirections: p.PawnDirections}
+	for color := range ColorCount {
+		to := color
+		if s&SwapColors != 0 {
+			to = 1 - color
+			mapped.PawnDirections[to] = -p.PawnDirections[color]
+		}
+
+		for kind := range PieceKindCount {
+			square := s.Square(p.Squares[color][kind], size)
+			mapped.Squares[to][kind] = square
+			if square >= 0 {
+				mapped.Occupied[to] |= square.Bit()
+			}
+		}
+	}
+	if s&SwapColors != 0 {
+		mapped.Turn = 1 - p.Turn
+	}
+
+	return mapped
+}
+
+// CanonicalKey returns the smallest key among the position's symmetric
+// images, and the symmetry that maps the position to that image.
+// Positions that are symmetric to each other share a canonical key, so
+// tables keyed by it store what is learnt about one for all of them.
+func (p *Position) CanonicalKey() (Key, Symmetry) {
+	best, symmetry := p.Key(), Identity
+	for _, s := range Symmetries[1:] {
+		image := s.Position(*p)
+		if k := image.Key(); k < best {
+			best, symmetry = k, s
+		}
+	}
+	return best, symmetry
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestSymmetries checks on random positions that each symmetry maps the
+// moves of a position onto the moves of its image, keeps wins and the
+// move counts further down the tree, and undoes itself.
+func TestSymmetries(t *testing.T) {
+	for _, rules := range keyRules {
+		t.Run(rules.String(), func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+
+			for range 30 {
+				p := NewPosition(rules)
+				for range rng.Intn(12) {
+					moves := p.GenerateMoves(nil)
+					if _, won := p.Winner(); won || len(moves) == 0 {
+						break
+					}
+					p.MakeMove(moves[rng.Intn(len(moves))])
+				}
+
+				canonical, _ := p.CanonicalKey()
+				for _, s := range Symmetries {
+					testSymmetry(t, p, s, canonical)
+				}
+			}
+		})
+	}
+}
+
+func testSymmetry(t *testing.T, p Position, s Symmetry, canonical Key) {
+	t.Helper()
+
+	size := p.Rules.Size()
+	image := s.Position(p)
+	expectEqual(t, s.Position(image), p)
+
+	imageMoves := map[PositionMove]bool{}
+	for _, m := range image.GenerateMoves(nil) {
+		imageMoves[m] = true
+	}
+	moves := p.GenerateMoves(nil)
+	expectEqual(t, len(moves), len(imageMoves))
+	for _, m := range moves {
+		if !imageMoves[s.Move(m, size)] {
+			t.Errorf("symmetry %d: move %v has no image %v", s, m, s.Move(m, size))
+		}
+	}
+
+	for color := range ColorCount {
+		imageColor := color
+		if s&SwapColors != 0 {
+			imageColor = 1 - color
+		}
+		expectEqual(t, image.HasFourInARow(imageColor), p.HasFourInARow(color))
+	}
+	expectEqual(t, Perft(image, 2), Perft(p, 2))
+
+	key, symmetry := image.CanonicalKey()
+	expectEqual(t, key, canonical)
+	canonicalImage := symmetry.Position(image)
+	expectEqual(t, canonicalImage.Key(), canonical)
+}
+
+func TestSwapColorsMirrorsOpening(t *testing.T) {
+	white := gameFromFEN(t, "4/4/4/R3 PBNprbn b ud 0 1")
+	black := gameFromFEN(t, "r3/4/4/4 PRBNpbn w ud 0 1")
+
+	p, q := white.Position(), black.Position()
+	expectEqual(t, SwapColors.Position(p), q)
+
+	pk, _ := p.CanonicalKey()
+	qk, _ := q.CanonicalKey()
+	expectEqual(t, pk, qk)
+
+	corner := gameFromFEN(t, "4/4/4/3R PBNprbn b ud 0 1").Position()
+	ck, _ := corner.CanonicalKey()
+	expectEqual(t, ck, pk)
+
+	center := gameFromFEN(t, "4/4/1R2/4 PBNprbn b ud 0 1").Position()
+	if k, _ := center.CanonicalKey(); k == pk {
+		t.Error("expected a center drop to differ from a corner drop")
+	}
+}
@@ -0,0 +1,117 @@
+package engine
+
+// Termination tells why a game ended.
+type Termination int
+
+const (
+	NotTerminated Termination = iota
+	FourInARow
+	Repetition
+	MoveLimit
+	Resignation
+	// Timeout is not reached yet: rooms have no game clocks. It is kept so
+	// that the values and their wire and database names stay stable.
+	Timeout
+	Abandonment
+	AgreedDraw
+)
+
+func (t Termination) String() string {
+	switch t {
+	case NotTerminated:
+		return "not terminated"
+	case FourInARow:
+		return "four in a row"
+	case Repetition:
+		return "threefold repetition"
+	case MoveLimit:
+		return "move limit"
+	case Resignation:
+		return "resignation"
+	case Timeout:
+		return "timeout"
+	case Abandonment:
+		return "abandonment"
+	case AgreedDraw:
+		return "agreement"
+	}
+
+	panic("unknown termination")
+}
+
+// byPlayer reports whether a player ended the game rather than a move:
+// by resigning, running out of time, abandoning it or agreeing to a draw.
+func (t Termination) byPlayer() bool {
+	return t == Resignation || t == Timeout || t == Abandonment || t == AgreedDraw
+}
+
+// Resign ends the game: color gives up and the opponent wins.
+func (g *Game) Resign(color Color) error {
+	return g.forfeit(color, Resignation)
+}
+
+// Abandon ends the game: color left it and the opponent wins.
+func (g *Game) Abandon(color Color) error {
+	return g.forfeit(color, Abandonment)
+}
+
+// AgreeDraw ends the game in a draw both players agreed to.
+func (g *Game) AgreeDraw() error {
+	if g.Status == GameOver {
+		return ErrGameOver
+	}
+
+	g.finish(nil, AgreedDraw)
+	return nil
+}
+
+// Reopen takes back the end of a game a player ended, see Undo, so that it
+// can go on or be undone. It leaves games ended by a move alone.
+func (g *Game) Reopen() {
+	if g.Termination.byPlayer() {
+		g.Status = GameStarted
+		g.Winner = nil
+		g.Termination = NotTerminated
+	}
+}
+
+func (g *Game) forfeit(loser Color, termination Termination) error {
+	if g.Status == GameOver {
+		return ErrGameOver
+	}
+
+	winner := 1 - loser
+	g.finish(&winner, termination)
+	return nil
+}
+
+// win ends the game with the winner's line on the board.
+func (g *Game) win(winner Color) {
+	g.finish(&winner, FourInARow)
+	g.WinningLine = g.winningLine(winner)
+}
+
+func (g *Game) finish(winner *Color, termination Termination) {
+	g.Status = GameOver
+	g.Winner = winner
+	g.Termination = termination
+}
+
+// winningLine returns the cells of the color's first complete line
+// in the order of WinMasks, or nil if it has none.
+func (g *Game) winningLine(color Color) []Cell {
+	occupied := g.Position().Occupied[color]
+	for _, mask := range WinMasks(g.Rules) {
+		if occupied&mask != mask {
+			continue
+		}
+
+		var line []Cell
+		for mask != 0 {
+			line = append(line, mask.PopFirst().Cell())
+		}
+		return line
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"testing"
+)
+
+func TestWinningLine(t *testing.T) {
+	g := gameFromFEN(t, "4/4/4/PRB1 Nprbn w ud 0 4")
+
+	expectNoError(t, g.Move(WhiteKnight, Cell{3, 3}))
+
+	expectEqual(t, g.Termination, FourInARow)
+	expectCells(t, g.WinningLine, []Cell{{3, 0}, {3, 1}, {3, 2}, {3, 3}})
+	expectCells(t, g.Snapshot().WinningLine(), g.WinningLine)
+	expectCells(t, g.Snapshot().Game().WinningLine, g.WinningLine)
+
+	g.Reopen()
+	expectEqual(t, g.Termination, FourInARow)
+
+	expectNoError(t, g.Undo())
+	expectCells(t, g.WinningLine, nil)
+}
+
+func TestWinningLineFromFEN(t *testing.T) {
+	g := gameFromFEN(t, "5/1R3/1P3/1B3/1N3 prbn b ud 0 4 5x5-4")
+
+	expectEqual(t, g.Termination, FourInARow)
+	expectCells(t, g.WinningLine, []Cell{{1, 1}, {2, 1}, {3, 1}, {4, 1}})
+}
+
+func TestForfeits(t *testing.T) {
+	tests := []struct {
+		name        string
+		forfeit     func(g *Game) error
+		termination Termination
+	}{
+		{"resignation", func(g *Game) error { return g.Resign(Black) }, Resignation},
+		{"abandonment", func(g *Game) error { return g.Abandon(Black) }, Abandonment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame()
+			expectNoError(t, g.Move(WhiteRook, Cell{0, 0}))
+
+			expectNoError(t, tt.forfeit(g))
+
+			expectEqual(t, g.Status, GameOver)
+			expectEqual(t, g.Termination, tt.termination)
+			if g.Winner == nil || *g.Winner != White {
+				t.Errorf("expected White to win, got %v", g.Winner)
+			}
+			expectCells(t, g.WinningLine, nil)
+
+			expectError(t, g.Move(BlackRook, Cell{3, 3}), ErrGameOver)
+			expectError(t, tt.forfeit(g), ErrGameOver)
+			expectError(t, g.Undo(), ErrEndedByPlayer)
+			expectEqual(t, g.Termination, tt.termination)
+			expectEqual(t, len(g.History), 1)
+
+			g.Reopen()
+			expectEqual(t, g.Status, GameStarted)
+			expectEqual(t, g.Termination, NotTerminated)
+			expectNoError(t, g.Undo())
+		})
+	}
+}
+
+func TestAgreeDraw(t *testing.T) {
+	g := NewGame()
+
+	expectNoError(t, g.AgreeDraw())
+
+	expectEqual(t, g.Status, GameOver)
+	expectEqual(t, g.Termination, AgreedDraw)
+	if g.Winner != nil {
+		t.Errorf("expected no winner, got %v", *g.Winner)
+	}
+	expectError(t, g.AgreeDraw(), ErrGameOver)
+	expectError(t, g.Undo(), ErrEndedByPlayer)
+}
@@ -0,0 +1,120 @@
+package engine
+
+// Threat is a line that a color is one piece short of winning.
+type Threat struct {
+	Color Color
+	// Line holds the cells of the line in board order.
+	Line []Cell
+	// Gap is the cell of the line that Color does not hold yet. It is
+	// either empty or held by an opponent piece, see Capture.
+	Gap     Cell
+	Capture bool
+	// Completions are the moves of Color that fill the gap: drops onto an
+	// empty gap and moves of pieces outside the line onto it. The threat
+	// wins on Color's next turn if there is any.
+	Completions []Move
+	// Defenses are the opponent's moves that stop the threat: drops and
+	// moves onto an empty gap, and captures of the pieces on the line.
+	Defenses []Move
+}
+
+// Attack is a piece on the board that the opponent can capture.
+type Attack struct {
+	Piece Piece
+	Cell  Cell
+	// Attackers are the opponent pieces that can capture it, by kind.
+	Attackers []Piece
+}
+
+// Threats returns the lines that color is one piece short of winning,
+// in the order of WinMasks. It does not depend on whose turn it is:
+// completions and defenses are listed as if each side were to move.
+func (g *Game) Threats(color Color) []Threat {
+	p := g.Position()
+
+	var threats []Threat
+	for _, mask := range WinMasks(p.Rules) {
+		own := p.Occupied[color] & mask
+		if own.Count() != mask.Count()-1 {
+			continue
+		}
+
+		gap := (mask &^ own).First()
+		threat := Threat{
+			Color:   color,
+			Gap:     gap.Cell(),
+			Capture: p.Occupied[1-color].Has(gap),
+		}
+		for line := mask; line != 0; {
+			threat.Line = append(threat.Line, line.PopFirst().Cell())
+		}
+
+		threat.Completions = p.movesOnto(color, gap.Bit(), own)
+		threat.Defenses = p.movesOnto(1-color, gap.Bit()&^p.Occupied[1-color], 0)
+		threat.Defenses = append(threat.Defenses, p.movesOnto(1-color, own, 0)...)
+
+		threats = append(threats, threat)
+	}
+
+	return threats
+}
+
+// AttackedPieces returns the pieces of color on the board that the
+// opponent can capture, by kind.
+func (g *Game) AttackedPieces(color Color) []Attack {
+	p := g.Position()
+
+	var attacks []Attack
+	for kind := range PieceKindCount {
+		s := p.Squares[color][kind]
+		if s < 0 {
+			continue
+		}
+
+		attack := Attack{Piece: Piece{Color: color, Kind: kind}, Cell: s.Cell()}
+		for _, m := range p.movesOnto(1-color, s.Bit(), 0) {
+			attack.Attackers = append(attack.Attackers, m.Piece)
+		}
+		if len(attack.Attackers) > 0 {
+			attacks = append(attacks, attack)
+		}
+	}
+
+	return attacks
+}
+
+// movesOnto returns the moves of color that land on targets: drops onto
+// the empty targets by kind, then board moves by kind. Pieces standing on
+// exclude are left out. Like Attacks it ignores whose turn it is.
+func (p *Position) movesOnto(color Color, targets, exclude Bitboard) []Move {
+	occupied := p.Occupied[White] | p.Occupied[Black]
+
+	var moves []Move
+	for kind := range PieceKindCount {
+		if p.Squares[color][kind] != InHand {
+			continue
+		}
+		for empty := targets &^ occupied; empty != 0; {
+			to := empty.PopFirst()
+			moves = append(moves, Move{Piece: Piece{Color: color, Kind: kind}, To: to.Cell(), Drop: true})
+		}
+	}
+
+	for kind := range PieceKindCount {
+		from := p.Squares[color][kind]
+		if from < 0 || exclude.Has(from) {
+			continue
+		}
+		for reach := p.Attacks(color, kind) & targets; reach != 0; {
+			to := reach.PopFirst()
+			move := Move{Piece: Piece{Color: color, Kind: kind}, From: from.Cell(), To: to.Cell()}
+			if captured, ok := p.PieceAt(to); ok {
+				move.Capture = true
+				move.Captured = captured
+			}
+			moves = append(moves, move)
+		}
+	}
+
+	return moves
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestThreatsWithEmptyGap(t *testing.T) {
+	g := gameFromFEN(t, "4/4/4/PRB1 Nprbn w ud 0 4")
+
+	expected := []Threat{{
+		Color: White,
+		Line:  []Cell{{3, 0}, {3, 1}, {3, 2}, {3, 3}},
+		Gap:   Cell{3, 3},
+		Completions: []Move{
+			{Piece: WhiteKnight, To: Cell{3, 3}, Drop: true},
+		},
+		Defenses: []Move{
+			{Piece: BlackPawn, To: Cell{3, 3}, Drop: true},
+			{Piece: BlackRook, To: Cell{3, 3}, Drop: true},
+			{Piece: BlackBishop, To: Cell{3, 3}, Drop: true},
+			{Piece: BlackKnight, To: Cell{3, 3}, Drop: true},
+		},
+	}}
+	if diff := cmp.Diff(expected, g.Threats(White), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("threats mismatch (-want +got):\n%s", diff)
+	}
+
+	expectEqual(t, len(g.Threats(Black)), 0)
+}
+
+func TestThreatsWithCapturableGap(t *testing.T) {
+	g := gameFromFEN(t, "4/n1N1/4/PRBb pr b ud 0 4")
+
+	expected := []Threat{{
+		Color:   White,
+		Line:    []Cell{{3, 0}, {3, 1}, {3, 2}, {3, 3}},
+		Gap:     Cell{3, 3},
+		Capture: true,
+		Completions: []Move{
+			{Piece: WhiteKnight, From: Cell{1, 2}, To: Cell{3, 3}, Capture: true, Captured: BlackBishop},
+		},
+		Defenses: []Move{
+			{Piece: BlackKnight, From: Cell{1, 0}, To: Cell{3, 1}, Capture: true, Captured: WhiteRook},
+		},
+	}}
+	if diff := cmp.Diff(expected, g.Threats(White), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("threats mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestThreatsOfShorterWinLength(t *testing.T) {
+	g := gameFromFEN(t, "5/5/5/5/PR3 BNprbn w ud 0 4 5x5-3")
+
+	threats := g.Threats(White)
+	expectEqual(t, len(threats), 1)
+	expectEqual(t, threats[0].Gap, Cell{4, 2})
+	expectEqual(t, len(threats[0].Completions), 2)
+}
+
+func TestAttackedPieces(t *testing.T) {
+	g := gameFromFEN(t, "4/n1N1/4/PRBb pr b ud 0 4")
+
+	white := []Attack{
+		{Piece: WhiteRook, Cell: Cell{3, 1}, Attackers: []Piece{BlackKnight}},
+	}
+	if diff := cmp.Diff(white, g.AttackedPieces(White)); diff != "" {
+		t.Errorf("white attacks mismatch (-want +got):\n%s", diff)
+	}
+
+	black := []Attack{
+		{Piece: BlackBishop, Cell: Cell{3, 3}, Attackers: []Piece{WhiteKnight}},
+		{Piece: BlackKnight, Cell: Cell{1, 0}, Attackers: []Piece{WhiteBishop}},
+	}
+	if diff := cmp.Diff(black, g.AttackedPieces(Black)); diff != "" {
+		t.Errorf("black attacks mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,140 @@
+package engine
+
+// Key is a Zobrist hash of a position: the board, the hands, the side to
+// move, the pawn directions and the rules. Move counters are not part of
+// it. Different positions get the same key only by rare accident.
+//
+// Keys are built from a fixed seed, so they are the same in every run and
+// may be stored.
+type Key uint64
+
+var zobrist = func() (z struct {
+	board [ColorCount][PieceKindCount][SquareCount]Key
+	hand  [ColorCount][PieceKindCount]Key
+	// black is added when black is to move.
+	black Key
+	// pawn is added for each color whose pawn moves to the white side.
+	pawn [ColorCount]Key
+	// rules is added for each board size, win length and rule flag.
+	size     [MaxBoardSize + 1]Key
+	length   [MaxBoardSize + 1]Key
+	discard  Key
+	keepPawn Key
+	queen    Key
+}) {
+	// splitmix64, seeded with an arbitrary constant
+	state := uint64(0x7469632d7461632d)
+	next := func() Key {
+		state += 0x9e3779b97f4a7c15
+		x := state
+		x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+		x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+		return Key(x ^ (x >> 31))
+	}
+
+	for color := range ColorCount {
+		for kind := range PieceKindCount {
+			for s := range SquareCount {
+				z.board[color][kind][s] = next()
+			}
+			z.hand[color][kind] = next()
+		}
+		z.pawn[color] = next()
+	}
+	z.black = next()
+	for i := range z.size {
+		z.size[i] = next()
+		z.length[i] = next()
+	}
+	z.discard, z.keepPawn, z.queen = next(), next(), next()
+
+	return z
+}()
+
+// Key returns the Zobrist key of the position.
+func (p *Position) Key() Key {
+	k := rulesKey(p.Rules)
+	for color := range ColorCount {
+		for kind := range PieceKindCount {
+			switch s := p.Squares[color][kind]; s {
+			case Discarded:
+			case InHand:
+				k ^= zobrist.hand[color][kind]
+			default:
+				k ^= zobrist.board[color][kind][s]
+			}
+		}
+		if p.PawnDirections[color] == ToWhiteSide {
+			k ^= zobrist.pawn[color]
+		}
+	}
+	if p.Turn == Black {
+		k ^= zobrist.black
+	}
+
+	return k
+}
+
+func rulesKey(r Rules) Key {
+	r = r.Normalized()
+
+	k := zobrist.size[r.BoardSize] ^ zobrist.length[r.WinLength]
+	if r.DiscardCaptures {
+		k ^= zobrist.discard
+	}
+	if r.KeepPawnDirection {
+		k ^= zobrist.keepPawn
+	}
+	if r.Queen {
+		k ^= zobrist.queen
+	}
+
+	return k
+}
+
+// Key returns the Zobrist key of the current position, see Position.Key.
+// It is computed on first use, then kept up to date by Move, Undo and Redo
+// one move at a time. Code that sets Board or Turn directly must do so
+// before asking for the key.
+func (g *Game) Key() Key {
+	if !g.keyed {
+		p := g.Position()
+		g.key = p.Key()
+		g.keyed = true
+	}
+	return g.key
+}
+
+// keyChange returns what a move changes in the key: it is added to the key
+// when the move is played and again, which removes it, when it is undone.
+// turned tells whether the turn passed to the opponent, which it does not
+// after a winning move.
+func (m Move) keyChange(r Rules, turned bool) Key {
+	color, kind := m.Piece.Color, m.Piece.Kind
+
+	k := zobrist.board[color][kind][SquareOf(m.To)]
+	if m.Drop {
+		k ^= zobrist.hand[color][kind]
+	} else {
+		k ^= zobrist.board[color][kind][SquareOf(m.From)]
+	}
+
+	if m.Capture {
+		captured := m.Captured
+		k ^= zobrist.board[captured.Color][captured.Kind][SquareOf(m.To)]
+		if !r.DiscardCaptures {
+			k ^= zobrist.hand[captured.Color][captured.Kind]
+		}
+	}
+
+	if turned {
+		k ^= zobrist.black
+	}
+	for color := range ColorCount {
+		if m.PawnDirectionsBefore[color] != m.PawnDirectionsAfter[color] {
+			k ^= zobrist.pawn[color]
+		}
+	}
+
+	return k
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var keyRules = []Rules{
+	StandardRules,
+	{BoardSize: 5, WinLength: 4, Queen: true},
+	{BoardSize: 5, DiscardCaptures: true, KeepPawnDirection: true},
+	{BoardSize: 4, WinLength: 3, Queen: true, DiscardCaptures: true},
+}
+
+// TestGameKeyIsIncremental plays random games and checks that the key kept
+// up to date by Move, Undo and Redo matches the key of the position.
+func TestGameKeyIsIncremental(t *testing.T) {
+	for _, rules := range keyRules {
+		t.Run(rules.String(), func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+
+			for range 100 {
+				g := NewGame(rules)
+				g.NoProgressLimit = 0
+				keys := []Key{g.Key()}
+
+				for g.Status != GameOver && len(keys) < 60 {
+					legal := g.AllLegalMoves()
+					if len(legal) == 0 {
+						break
+					}
+					expectNoError(t, g.Play(legal[rng.Intn(len(legal))]))
+
+					p := g.Position()
+					expectEqual(t, g.Key(), p.Key())
+					keys = append(keys, g.Key())
+				}
+
+				for i := len(keys) - 2; i >= 0; i-- {
+					expectNoError(t, g.Undo())
+					expectEqual(t, g.Key(), keys[i])
+				}
+				expectNoError(t, g.Redo())
+				expectEqual(t, g.Key(), keys[1])
+				expectEqual(t, g.Clone().Key(), keys[1])
+			}
+		})
+	}
+}
+
+func TestKeyTranspositions(t *testing.T) {
+	a := NewGame()
+	expectNoError(t, a.Move(WhiteRook, Cell{0, 0}))
+	expectNoError(t, a.Move(BlackRook, Cell{3, 3}))
+	expectNoError(t, a.Move(WhiteBishop, Cell{0, 1}))
+
+	b := NewGame()
+	expectNoError(t, b.Move(WhiteBishop, Cell{0, 1}))
+	expectNoError(t, b.Move(BlackRook, Cell{3, 3}))
+	expectNoError(t, b.Move(WhiteRook, Cell{0, 0}))
+
+	expectEqual(t, a.Key(), b.Key())
+
+	p := a.Position()
+	p.Turn = White
+	if p.Key() == a.Key() {
+		t.Error("expected the side to move to change the key")
+	}
+
+	variant := gameFromFEN(t, FormatFEN(a)+" 4x4-3")
+	if variant.Key() == a.Key() {
+		t.Error("expected the rules to change the key")
+	}
+}
+
+// TestKeyIsStable pins the key of the initial position: keys may be
+// stored, so they must not change between versions.
+func TestKeyIsStable(t *testing.T) {
+	expectEqual(t, NewGame().Key(), Key(0x3707a29c71e2083a))
+}
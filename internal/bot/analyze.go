@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"cmp"
+	"slices"
+	"tic-tac-chec/engine"
+	"time"
+)
+
+// defaultAnalysisSimulations is the budget of an analysis by a model that
+// does not search when it plays.
+const defaultAnalysisSimulations = 400
+
+// Budget limits an analysis: the search stops after Simulations
+// simulations or once Time has passed, whichever comes first. The zero
+// Budget searches as the model does when it plays.
+type Budget struct {
+	Simulations int
+	Time        time.Duration
+}
+
+// Analysis is what MCTS found out about a position.
+type Analysis struct {
+	// Value is the estimated outcome for the side to move, from -1 for a
+	// loss to +1 for a win: the mean value of all simulations.
+	Value float32
+	// Visits is the number of simulations run.
+	Visits int
+	// Candidates are the moves searched, the most visited first.
+	Candidates []Candidate
+}
+
+// Candidate is a move of the side to move and its search statistics.
+type Candidate struct {
+	Move engine.Move
+	// Visits is the number of simulations that started with the move.
+	Visits int
+	// Value is the mean value of those simulations for the side to move,
+	// 0 if there were none.
+	Value float32
+	// Prior is the probability the evaluator's policy gave the move.
+	Prior float32
+	// PV is the principal variation: the move, then the most visited
+	// reply at every ply as far as the search went.
+	PV []engine.Move
+}
+
+// Analyze searches the position of g within the budget and returns the
+// top candidate moves, all of them if top is 0 or less. It does not change
+// g.
+func (m *Model) Analyze(g *engine.Game, budget Budget, top int) (Analysis, error) {
+	if !g.Rules.Standard() {
+		return Analysis{}, ErrUnsupportedRules
+	}
+
+	if budget == (Budget{}) {
+		budget = Budget{Simulations: m.opts.Simulations, Time: m.opts.Budget(g, 0)}
+		if budget == (Budget{}) {
+			budget.Simulations = defaultAnalysisSimulations
+		}
+	}
+
+	root := &node{game: g.Clone()}
+	opts := SearchOptions{Simulations: budget.Simulations, Workers: m.opts.Workers}
+	if err := search(m, root, opts, budget.Time); err != nil {
+		return Analysis{}, err
+	}
+
+	analysis := Analysis{Value: root.meanValue(), Visits: root.visitCount}
+
+	children := slices.Clone(root.children)
+	slices.SortStableFunc(children, func(a, b *node) int {
+		return cmp.Or(cmp.Compare(b.visitCount, a.visitCount), cmp.Compare(b.prior, a.prior))
+	})
+	if top > 0 && len(children) > top {
+		children = children[:top]
+	}
+
+	for _, child := range children {
+		if child.game == nil {
+			// never expanded, the search did not need its game
+			game, err := child.play()
+			if err != nil {
+				return Analysis{}, err
+			}
+			child.game = game
+		}
+		analysis.Candidates = append(analysis.Candidates, Candidate{
+			Move:   child.move(),
+			Visits: child.visitCount,
+			// the child's value is its mover's opponent's
+			Value: -child.meanValue(),
+			Prior: child.prior,
+			PV:    child.principalVariation(),
+		})
+	}
+
+	return analysis, nil
+}
+
+// meanValue returns the mean backpropagated value, for the side to move at
+// n, or 0 if n was not visited.
+func (n *node) meanValue() float32 {
+	if n.visitCount == 0 {
+		return 0
+	}
+	return n.totalValue / float32(n.visitCount)
+}
+
+// move returns the move that led to n.
+func (n *node) move() engine.Move {
+	return n.game.History[len(n.game.History)-1]
+}
+
+// principalVariation returns the move that led to n and the most visited
+// line of play below it.
+func (n *node) principalVariation() []engine.Move {
+	pv := []engine.Move{n.move()}
+	for next := n.mostVisited(); next != nil && next.visitCount > 0; next = next.mostVisited() {
+		pv = append(pv, next.move())
+	}
+	return pv
+}
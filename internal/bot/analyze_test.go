@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"testing"
+	"tic-tac-chec/engine"
+)
+
+func TestAnalyzeFindsWin(t *testing.T) {
+	g, err := engine.ParseFEN("PRB1/4/4/1brp Nn w ud 0 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewWithEvaluator(NewRolloutEvaluator(4, 1), SearchOptions{Workers: 2})
+
+	analysis, err := m.Analyze(g, Budget{Simulations: 300}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if analysis.Visits != 300 {
+		t.Errorf("expected 300 visits, got %d", analysis.Visits)
+	}
+	if len(analysis.Candidates) != 3 {
+		t.Fatalf("expected 3 candidates, got %d", len(analysis.Candidates))
+	}
+	for i := 1; i < len(analysis.Candidates); i++ {
+		if analysis.Candidates[i].Visits > analysis.Candidates[i-1].Visits {
+			t.Errorf("expected candidates by visits, got %d after %d", analysis.Candidates[i].Visits, analysis.Candidates[i-1].Visits)
+		}
+	}
+
+	best := analysis.Candidates[0]
+	if best.Move.Piece != engine.WhiteKnight || best.Move.To != (engine.Cell{Row: 0, Col: 3}) {
+		t.Errorf("expected the knight drop on d4 first, got %v", best.Move)
+	}
+	if best.Value != 1 || len(best.PV) != 1 {
+		t.Errorf("expected a won line of one move, got value %v and %d moves", best.Value, len(best.PV))
+	}
+	if analysis.Value <= 0 {
+		t.Errorf("expected a winning root value, got %v", analysis.Value)
+	}
+}
+
+func TestAnalyzePrincipalVariation(t *testing.T) {
+	g := engine.NewGame()
+	m := NewWithEvaluator(NewRolloutEvaluator(2, 1), SearchOptions{})
+
+	analysis, err := m.Analyze(g, Budget{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if analysis.Visits != defaultAnalysisSimulations {
+		t.Errorf("expected the default budget of a greedy model, got %d visits", analysis.Visits)
+	}
+	if len(analysis.Candidates) != len(g.AllLegalMoves()) {
+		t.Errorf("expected every legal move, got %d", len(analysis.Candidates))
+	}
+	if len(g.History) != 0 {
+		t.Error("expected Analyze to leave the game alone")
+	}
+
+	prior := float32(0)
+	for _, c := range analysis.Candidates {
+		prior += c.Prior
+
+		if c.PV[0] != c.Move {
+			t.Fatalf("expected the PV to start with %v, got %v", c.Move, c.PV[0])
+		}
+		line := g.Clone()
+		for _, move := range c.PV {
+			if err := line.Play(move); err != nil {
+				t.Fatalf("PV %v: %v", c.PV, err)
+			}
+		}
+	}
+	if prior < 0.999 || prior > 1.001 {
+		t.Errorf("expected the priors to add up to 1, got %v", prior)
+	}
+}
+
+func TestAnalyzeGameOver(t *testing.T) {
+	g, err := engine.ParseFEN("PRBN/4/4/1brp n b ud 0 8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewWithEvaluator(NewRolloutEvaluator(0, 1), SearchOptions{})
+
+	if _, err := m.Analyze(g, Budget{Simulations: 10}, 0); err == nil {
+		t.Error("expected an error for a finished game")
+	}
+}
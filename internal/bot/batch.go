@@ -0,0 +1,262 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"tic-tac-chec/internal/bot/native"
+	"time"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// ErrBatcherClosed is returned for requests made after Batcher.Close.
+var ErrBatcherClosed = errors.New("bot: batcher is closed")
+
+const (
+	DefaultMaxBatch    = 64
+	DefaultBatchWindow = 2 * time.Millisecond
+)
+
+// BatchOptions tunes a Batcher. Zero values pick the defaults.
+type BatchOptions struct {
+	// MaxBatch is the most states run at once.
+	MaxBatch int
+	// Window is the longest a request waits for others to join its batch.
+	Window time.Duration
+}
+
+// runFunc runs the network on n states laid out one after another and
+// returns n*ActionSpaceSize logits and n values.
+type runFunc func(states []float32, n int) (logits, values []float32, err error)
+
+// Batcher runs inference for many goroutines at once: every room's bot and
+// every MCTS worker submits its states here, and the batcher runs them
+// through one session as a single (N,19,4,4) tensor.
+//
+// A batch is run when it is full, when its window has passed, or as soon
+// as every active search is waiting on it, see Join. A lone search
+// therefore never waits for the window.
+type Batcher struct {
+	run      runFunc
+	session  *ort.DynamicAdvancedSession
+	maxBatch int
+	window   time.Duration
+
+	requests chan *Future
+	quit     chan struct{}
+	closing  sync.Once
+	done     chan struct{}
+
+	// active counts the searches that currently submit requests.
+	active atomic.Int64
+}
+
+// Future is the pending result of a submitted state.
+type Future struct {
+	state  []float32
+	done   chan struct{}
+	logits []float32
+	value  float32
+	err    error
+}
+
+// Wait blocks until the result is ready and returns the action logits
+// (ActionSpaceSize floats) and the state value.
+func (f *Future) Wait() ([]float32, float32, error) {
+	<-f.done
+	return f.logits, f.value, f.err
+}
+
+func (f *Future) resolve(logits []float32, value float32, err error) {
+	f.logits, f.value, f.err = logits, value, err
+	close(f.done)
+}
+
+// NewBatcher loads the ONNX model from the given path and starts batching
+// requests to it. Call Close when done.
+func NewBatcher(modelPath string, opts BatchOptions) (*Batcher, error) {
+	session, err := ort.NewDynamicAdvancedSession(
+		modelPath,
+		[]string{"state"},
+		[]string{"action_logits", "state_value"},
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("bot: load model: %w", err)
+	}
+
+	b := newBatcher(func(states []float32, n int) ([]float32, []float32, error) {
+		return runSession(session, states, n)
+	}, opts)
+	b.session = session
+
+	return b, nil
+}
+
+// NewNativeBatcher loads the ONNX model from the given path into a network
+// evaluated in pure Go, see package native, and starts batching requests to
+// it. It needs no ONNX Runtime. Call Close when done.
+func NewNativeBatcher(modelPath string, opts BatchOptions) (*Batcher, error) {
+	net, err := native.Load(modelPath)
+	if err != nil {
+		return nil, fmt.Errorf("bot: load model: %w", err)
+	}
+	if net.SampleSize() != StateSize {
+		return nil, fmt.Errorf("bot: load model: states of %d floats, want %d", net.SampleSize(), StateSize)
+	}
+
+	return newBatcher(net.Run, opts), nil
+}
+
+func newBatcher(run runFunc, opts BatchOptions) *Batcher {
+	if opts.MaxBatch <= 0 {
+		opts.MaxBatch = DefaultMaxBatch
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultBatchWindow
+	}
+
+	b := &Batcher{
+		run:      run,
+		maxBatch: opts.MaxBatch,
+		window:   opts.Window,
+		requests: make(chan *Future),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go b.loop()
+
+	return b
+}
+
+// Join marks the start of a search that will submit requests, and returns
+// the function that marks its end. Batches are run as soon as every joined
+// search waits on one.
+func (b *Batcher) Join() (leave func()) {
+	b.active.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { b.active.Add(-1) })
+	}
+}
+
+// Submit queues a state (StateSize floats) for the next batch. The state
+// must not change until the future is resolved.
+func (b *Batcher) Submit(state []float32) *Future {
+	f := &Future{state: state, done: make(chan struct{})}
+	if len(state) != StateSize {
+		f.resolve(nil, 0, fmt.Errorf("bot: state has %d floats, want %d", len(state), StateSize))
+		return f
+	}
+
+	select {
+	case b.requests <- f:
+	case <-b.quit:
+		f.resolve(nil, 0, ErrBatcherClosed)
+	}
+	return f
+}
+
+// Infer submits a state and waits for its result.
+func (b *Batcher) Infer(state []float32) ([]float32, float32, error) {
+	return b.Submit(state).Wait()
+}
+
+// Close stops the batcher once the batch being collected has run, and
+// destroys its session. Later requests fail with ErrBatcherClosed.
+func (b *Batcher) Close() {
+	b.closing.Do(func() {
+		close(b.quit)
+		<-b.done
+		if b.session != nil {
+			b.session.Destroy()
+		}
+	})
+}
+
+func (b *Batcher) loop() {
+	defer close(b.done)
+
+	batch := make([]*Future, 0, b.maxBatch)
+	for {
+		select {
+		case f := <-b.requests:
+			batch = append(batch[:0], f)
+		case <-b.quit:
+			return
+		}
+
+		b.collect(&batch)
+		b.flush(batch)
+	}
+}
+
+// collect adds requests to the batch until it is full, every active search
+// is waiting on it, or the window has passed.
+func (b *Batcher) collect(batch *[]*Future) {
+	timer := time.NewTimer(b.window)
+	defer timer.Stop()
+
+	for len(*batch) < b.maxBatch && int64(len(*batch)) < b.active.Load() {
+		select {
+		case f := <-b.requests:
+			*batch = append(*batch, f)
+		case <-timer.C:
+			return
+		case <-b.quit:
+			return
+		}
+	}
+}
+
+func (b *Batcher) flush(batch []*Future) {
+	states := make([]float32, 0, len(batch)*StateSize)
+	for _, f := range batch {
+		states = append(states, f.state...)
+	}
+
+	logits, values, err := b.run(states, len(batch))
+	for i, f := range batch {
+		if err != nil {
+			f.resolve(nil, 0, err)
+			continue
+		}
+		f.resolve(logits[i*ActionSpaceSize:(i+1)*ActionSpaceSize:(i+1)*ActionSpaceSize], values[i], nil)
+	}
+}
+
+// runSession runs the network once on a batch of n states.
+func runSession(session *ort.DynamicAdvancedSession, states []float32, n int) ([]float32, []float32, error) {
+	input, err := ort.NewTensor(ort.Shape{int64(n), NumChannels, BoardSize, BoardSize}, states)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bot: create input tensor: %w", err)
+	}
+	defer input.Destroy()
+
+	output, err := ort.NewEmptyTensor[float32](ort.Shape{int64(n), ActionSpaceSize})
+	if err != nil {
+		return nil, nil, fmt.Errorf("bot: create output tensor: %w", err)
+	}
+	defer output.Destroy()
+
+	valueOutput, err := ort.NewEmptyTensor[float32](ort.Shape{int64(n), 1})
+	if err != nil {
+		return nil, nil, fmt.Errorf("bot: create value tensor: %w", err)
+	}
+	defer valueOutput.Destroy()
+
+	err = session.Run([]ort.ArbitraryTensor{input}, []ort.ArbitraryTensor{output, valueOutput})
+	if err != nil {
+		return nil, nil, fmt.Errorf("bot: run inference: %w", err)
+	}
+
+	// the tensors are destroyed on return, so their data is copied out
+	logits := make([]float32, n*ActionSpaceSize)
+	copy(logits, output.GetData())
+	values := make([]float32, n)
+	copy(values, valueOutput.GetData())
+
+	return logits, values, nil
+}
@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+// echoRun is a fake network: the logits of a state start with its first
+// float and its value is its second. It records the size of every batch.
+type echoRun struct {
+	mu      sync.Mutex
+	batches []int
+}
+
+func (e *echoRun) run(states []float32, n int) ([]float32, []float32, error) {
+	e.mu.Lock()
+	e.batches = append(e.batches, n)
+	e.mu.Unlock()
+
+	logits := make([]float32, n*ActionSpaceSize)
+	values := make([]float32, n)
+	for i := range n {
+		logits[i*ActionSpaceSize] = states[i*StateSize]
+		values[i] = states[i*StateSize+1]
+	}
+	return logits, values, nil
+}
+
+func (e *echoRun) sizes() []int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.batches)
+}
+
+func testState(i int) []float32 {
+	state := make([]float32, StateSize)
+	state[0], state[1] = float32(i), float32(-i)
+	return state
+}
+
+// submitAll joins n searches to the batcher, has each submit one state and
+// checks that every search gets its own result back.
+func submitAll(t *testing.T, b *Batcher, n int) {
+	t.Helper()
+
+	leaves := make([]func(), n)
+	for i := range n {
+		leaves[i] = b.Join()
+	}
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			defer leaves[i]()
+
+			logits, value, err := b.Infer(testState(i))
+			if err != nil {
+				t.Errorf("request %d: %v", i, err)
+				return
+			}
+			if len(logits) != ActionSpaceSize || logits[0] != float32(i) || value != float32(-i) {
+				t.Errorf("request %d got the result of another request: %v, %v", i, logits[0], value)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestBatcherCoalescesActiveSearches(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	echo := &echoRun{}
+	b := newBatcher(echo.run, BatchOptions{Window: time.Hour})
+	defer b.Close()
+
+	submitAll(t, b, 8)
+
+	if sizes := echo.sizes(); !slices.Equal(sizes, []int{8}) {
+		t.Errorf("expected one batch of 8, got %v", sizes)
+	}
+}
+
+func TestBatcherRespectsMaxBatch(t *testing.T) {
+	echo := &echoRun{}
+	b := newBatcher(echo.run, BatchOptions{MaxBatch: 3, Window: time.Millisecond})
+	defer b.Close()
+
+	submitAll(t, b, 7)
+
+	total := 0
+	for _, size := range echo.sizes() {
+		if size > 3 {
+			t.Errorf("expected batches of at most 3, got %d", size)
+		}
+		total += size
+	}
+	if total != 7 {
+		t.Errorf("expected 7 states run, got %d", total)
+	}
+}
+
+func TestBatcherLoneRequestDoesNotWait(t *testing.T) {
+	echo := &echoRun{}
+	b := newBatcher(echo.run, BatchOptions{Window: time.Hour})
+	defer b.Close()
+
+	leave := b.Join()
+	defer leave()
+
+	if _, _, err := b.Infer(testState(1)); err != nil {
+		t.Fatal(err)
+	}
+	// unjoined callers are not waited for either
+	if _, _, err := b.Infer(testState(2)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBatcherFlushesAfterWindow(t *testing.T) {
+	echo := &echoRun{}
+	window := 20 * time.Millisecond
+	b := newBatcher(echo.run, BatchOptions{Window: window})
+	defer b.Close()
+
+	// the second search never submits, so the batch waits for the window
+	defer b.Join()()
+	defer b.Join()()
+
+	start := time.Now()
+	if _, _, err := b.Infer(testState(1)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < window {
+		t.Errorf("expected the batch to wait %v for the idle search, ran after %v", window, elapsed)
+	}
+}
+
+func TestBatcherErrors(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	failure := errors.New("no network")
+	b := newBatcher(func([]float32, int) ([]float32, []float32, error) {
+		return nil, nil, failure
+	}, BatchOptions{})
+
+	if _, _, err := b.Infer(testState(1)); !errors.Is(err, failure) {
+		t.Errorf("expected the run error, got %v", err)
+	}
+	if _, _, err := b.Infer(make([]float32, 3)); err == nil {
+		t.Error("expected an error for a short state")
+	}
+
+	b.Close()
+	b.Close()
+	if _, _, err := b.Infer(testState(1)); !errors.Is(err, ErrBatcherClosed) {
+		t.Errorf("expected ErrBatcherClosed, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package bot
+
+import (
+	"sync/atomic"
+	"tic-tac-chec/engine"
+	"time"
+)
+
+const (
+	// clockShare is the share of the opponent's remaining clock the bot
+	// thinks at most.
+	clockShare = 10
+	// minThinkTime keeps a budget cut by the phase or a short clock from
+	// reaching 0, which would mean no limit.
+	minThinkTime = 10 * time.Millisecond
+	// earlyStopInterval is how many simulations pass between the checks
+	// whether the search can stop early.
+	earlyStopInterval = 8
+)
+
+// Budget returns how long to think about the move in g, 0 for no time
+// limit if opts.ThinkTime is 0.
+//
+// The budget follows the game phase: half of ThinkTime on the empty board,
+// where the first drops hardly matter, growing with every piece dropped to
+// all of it once every piece is on the board and the game turns on moves
+// alone. It is also at most a tenth of the opponent's remaining clock, if
+// it is known (> 0), so that a bot does not keep an opponent who is short
+// of time waiting. Rooms have no game clocks yet, so their bots pass 0.
+func (opts SearchOptions) Budget(g *engine.Game, opponentClock time.Duration) time.Duration {
+	if opts.ThinkTime <= 0 {
+		return 0
+	}
+
+	onBoard, inPlay := pieceCounts(g)
+	budget := opts.ThinkTime * time.Duration(inPlay+onBoard) / time.Duration(2*max(inPlay, 1))
+	if opponentClock > 0 {
+		budget = min(budget, opponentClock/clockShare)
+	}
+
+	return max(budget, minThinkTime)
+}
+
+// pieceCounts returns the number of pieces on the board and the number
+// of pieces in play, on the board or in hand.
+func pieceCounts(g *engine.Game) (onBoard, inPlay int) {
+	p := g.Position()
+	for color := range engine.ColorCount {
+		for _, square := range p.Squares[color] {
+			switch {
+			case square >= 0:
+				onBoard++
+				inPlay++
+			case square == engine.InHand:
+				inPlay++
+			}
+		}
+	}
+	return onBoard, inPlay
+}
+
+// searchLimits decides when the workers of a search stop.
+type searchLimits struct {
+	simulations int64
+	start       time.Time
+	deadline    time.Time
+	earlyStop   bool
+
+	started atomic.Int64
+	stopped atomic.Bool
+}
+
+func newSearchLimits(opts SearchOptions, budget time.Duration) *searchLimits {
+	l := &searchLimits{
+		simulations: int64(opts.Simulations),
+		start:       time.Now(),
+		earlyStop:   opts.EarlyStop,
+	}
+	if budget > 0 {
+		l.deadline = l.start.Add(budget)
+	}
+	return l
+}
+
+// next reports whether another simulation may start, and counts it.
+func (l *searchLimits) next() bool {
+	if l.stopped.Load() {
+		return false
+	}
+
+	n := l.started.Add(1)
+	if l.simulations > 0 && n > l.simulations {
+		return false
+	}
+	// the first simulation runs regardless, so that a fresh root has children
+	if n > 1 && !l.deadline.IsZero() && !time.Now().Before(l.deadline) {
+		return false
+	}
+	return true
+}
+
+func (l *searchLimits) stop() {
+	l.stopped.Store(true)
+}
+
+// checkEarlyStop stops the search once the most visited child of root
+// leads the runner-up by more visits than the simulations left could
+// give it.
+func (l *searchLimits) checkEarlyStop(root *node) {
+	if !l.earlyStop || l.started.Load()%earlyStopInterval != 0 {
+		return
+	}
+
+	best, second := root.topVisits()
+	if best-second > l.remaining() {
+		l.stop()
+	}
+}
+
+// remaining estimates how many simulations the search has left: those up
+// to the simulation limit, and as many as fit before the deadline at the
+// rate of the simulations so far.
+func (l *searchLimits) remaining() int64 {
+	n := l.started.Load()
+
+	left := int64(-1)
+	if l.simulations > 0 {
+		left = max(l.simulations-n, 0)
+	}
+	if !l.deadline.IsZero() {
+		elapsed := time.Since(l.start)
+		rate := float64(n) / max(elapsed.Seconds(), 1e-6)
+		fit := int64(rate * max(time.Until(l.deadline).Seconds(), 0))
+		if left < 0 || fit < left {
+			left = fit
+		}
+	}
+	return max(left, 0)
+}
+
+// topVisits returns the visit counts of the two most visited children.
+func (n *node) topVisits() (best, second int64) {
+	n.mu.Lock()
+	children := n.children
+	n.mu.Unlock()
+
+	for _, child := range children {
+		child.mu.Lock()
+		visits := int64(child.visitCount)
+		child.mu.Unlock()
+
+		switch {
+		case visits > best:
+			best, second = visits, best
+		case visits > second:
+			second = visits
+		}
+	}
+	return best, second
+}
@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"testing"
+	"tic-tac-chec/engine"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	opening := engine.NewGame()
+	middlegame, err := engine.ParseFEN("PRB1/4/4/1brp Nn w ud 0 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	endgame, err := engine.ParseFEN("PRB1/3N/n3/1brp - w ud 0 9")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		thinkTime     time.Duration
+		game          *engine.Game
+		opponentClock time.Duration
+		want          time.Duration
+	}{
+		{"no think time", 0, middlegame, time.Minute, 0},
+		{"opening", time.Second, opening, 0, 500 * time.Millisecond},
+		{"middlegame", time.Second, middlegame, 0, 875 * time.Millisecond},
+		{"endgame", time.Second, endgame, 0, time.Second},
+		{"tiny think time", time.Nanosecond, opening, 0, minThinkTime},
+		{"plenty of clock", time.Second, endgame, time.Minute, time.Second},
+		{"short clock", time.Second, endgame, 5 * time.Second, 500 * time.Millisecond},
+		{"no clock left", time.Second, endgame, time.Millisecond, minThinkTime},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := SearchOptions{ThinkTime: tt.thinkTime}
+			if got := opts.Budget(tt.game, tt.opponentClock); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMCTSThinkTime(t *testing.T) {
+	g, err := engine.ParseFEN("PRB1/4/4/1brp Nn w ud 0 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := fakeModel(t, SearchOptions{ThinkTime: 50 * time.Millisecond, Workers: 2})
+	root := &node{game: g.Clone()}
+
+	budget := m.opts.Budget(g, 0)
+	start := time.Now()
+	if _, _, err := runMCTS(m, root, g, m.opts, budget); err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+
+	if elapsed < budget || elapsed > time.Second {
+		t.Errorf("expected the search to take about %v, took %v", budget, elapsed)
+	}
+	if root.visitCount < 2 {
+		t.Errorf("expected the search to run until the deadline, got %d visits", root.visitCount)
+	}
+}
+
+func TestMCTSEarlyStop(t *testing.T) {
+	g, err := engine.ParseFEN("PRB1/4/4/1brp Nn w ud 0 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := fakeModel(t, SearchOptions{Simulations: 2000, EarlyStop: true})
+	root := &node{game: g.Clone()}
+
+	piece, cell, err := runMCTS(m, root, g, m.opts, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if piece != engine.WhiteKnight || cell != (engine.Cell{Row: 0, Col: 3}) {
+		t.Errorf("expected the knight drop on d4, got %v to %v", piece, cell)
+	}
+	if root.visitCount >= 2000 {
+		t.Errorf("expected the search to stop early, got %d visits", root.visitCount)
+	}
+}
@@ -33,3 +33,30 @@ func DecodeAction(action int, color engine.Color) (piece engine.Piece, src, dst
 	isDrop = false
 	return
 }
+
+// EncodeAction converts an engine.Move to its action index (0-319),
+// the inverse of DecodeAction.
+func EncodeAction(move engine.Move) int {
+	dstIdx := move.To.Row*engine.BoardSize + move.To.Col
+	if move.Drop {
+		return int(move.Piece.Kind)*16 + dstIdx
+	}
+
+	srcIdx := move.From.Row*engine.BoardSize + move.From.Col
+	return 64 + srcIdx*16 + dstIdx
+}
+
+// EncodePositionMove converts an engine.PositionMove of the standard board
+// to its action index.
+func EncodePositionMove(move engine.PositionMove) int {
+	dstIdx := cellIndex(move.To.Cell())
+	if move.Drop() {
+		return int(move.Kind)*16 + dstIdx
+	}
+
+	return 64 + cellIndex(move.From.Cell())*16 + dstIdx
+}
+
+func cellIndex(cell engine.Cell) int {
+	return cell.Row*engine.BoardSize + cell.Col
+}
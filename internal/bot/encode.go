@@ -53,7 +53,7 @@ func (e *StateEncoder) Encode(g *engine.Game) []float32 {
 	// ch - index in pieceOrder, 0-7
 	for ch, p := range pieceOrder {
 		if piece := g.Piece(p); piece != nil {
-			cell, ok := g.Board.Find(piece)
+			cell, ok := g.Find(*piece)
 			if ok { // piece is on board
 				e.setChannelBit(ch, cell.Row, cell.Col, 1.0)
 			} else { // piece is in hand
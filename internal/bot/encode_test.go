@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"testing"
+	"tic-tac-chec/engine"
+)
+
+func TestDecodeDropAction(t *testing.T) {
+	// Action 0 = Pawn drop at (0,0)
+	piece, _, dst, isDrop := DecodeAction(0, engine.White)
+	if !isDrop {
+		t.Fatal("expected drop action")
+	}
+	if piece.Kind != engine.Pawn || piece.Color != engine.White {
+		t.Fatalf("expected White Pawn, got %v", piece)
+	}
+	if dst.Row != 0 || dst.Col != 0 {
+		t.Fatalf("expected (0,0), got %v", dst)
+	}
+
+	// Action 63 = Knight drop at (3,3)
+	piece, _, dst, isDrop = DecodeAction(63, engine.Black)
+	if !isDrop {
+		t.Fatal("expected drop action")
+	}
+	if piece.Kind != engine.Knight || piece.Color != engine.Black {
+		t.Fatalf("expected Black Knight, got %v", piece)
+	}
+	if dst.Row != 3 || dst.Col != 3 {
+		t.Fatalf("expected (3,3), got %v", dst)
+	}
+}
+
+func TestDecodeMoveAction(t *testing.T) {
+	// Action 64 = move from (0,0) to (0,0) — self-move, always masked
+	_, src, dst, isDrop := DecodeAction(64, engine.White)
+	if isDrop {
+		t.Fatal("expected move action")
+	}
+	if src.Row != 0 || src.Col != 0 {
+		t.Fatalf("expected src (0,0), got %v", src)
+	}
+	if dst.Row != 0 || dst.Col != 0 {
+		t.Fatalf("expected dst (0,0), got %v", dst)
+	}
+
+	// Action 319 = move from (3,3) to (3,3)
+	_, src, dst, isDrop = DecodeAction(319, engine.White)
+	if isDrop {
+		t.Fatal("expected move action")
+	}
+	if src.Row != 3 || src.Col != 3 {
+		t.Fatalf("expected src (3,3), got %v", src)
+	}
+	if dst.Row != 3 || dst.Col != 3 {
+		t.Fatalf("expected dst (3,3), got %v", dst)
+	}
+}
+
+func TestEncodeActionRoundTrip(t *testing.T) {
+	g := engine.NewGame()
+	g.Move(engine.WhiteBishop, engine.Cell{Row: 1, Col: 1})
+	g.Move(engine.BlackRook, engine.Cell{Row: 2, Col: 2})
+
+	for _, move := range g.AllLegalMoves() {
+		action := EncodeAction(move)
+		piece, src, dst, isDrop := DecodeAction(action, g.Turn)
+
+		if isDrop != move.Drop || dst != move.To {
+			t.Fatalf("action %d decoded to drop=%v dst=%v, want %v", action, isDrop, dst, move)
+		}
+		if isDrop && piece != move.Piece {
+			t.Fatalf("action %d decoded to piece %v, want %v", action, piece, move.Piece)
+		}
+		if !isDrop && src != move.From {
+			t.Fatalf("action %d decoded to src %v, want %v", action, src, move.From)
+		}
+	}
+}
+
+func TestLegalActionsMatchAllLegalMoves(t *testing.T) {
+	g := engine.NewGame()
+	g.Move(engine.WhiteBishop, engine.Cell{Row: 1, Col: 1})
+	g.Move(engine.BlackRook, engine.Cell{Row: 2, Col: 2})
+	g.Move(engine.WhitePawn, engine.Cell{Row: 3, Col: 2})
+
+	want := map[int]bool{}
+	for _, move := range g.AllLegalMoves() {
+		want[EncodeAction(move)] = true
+	}
+
+	got := legalActions(g)
+	if len(got) != len(want) {
+		t.Fatalf("got %d legal actions, want %d", len(got), len(want))
+	}
+	for _, action := range got {
+		if !want[action] {
+			t.Errorf("unexpected legal action %d", action)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"math/rand/v2"
+	"sync"
+	"tic-tac-chec/engine"
+)
+
+// Evaluator judges positions for MCTS and greedy selection: the policy
+// logits of every action (ActionSpaceSize floats, indexed like
+// EncodeAction) and the value of the position for the side to move, from
+// -1 for a loss to +1 for a win. It is called from several goroutines at
+// once.
+//
+// Batcher evaluates with the ONNX network; RolloutEvaluator needs no
+// network at all.
+type Evaluator interface {
+	Evaluate(g *engine.Game) (logits []float32, value float32, err error)
+}
+
+// joiner is an Evaluator that wants to know when searches start and end,
+// see Batcher.Join.
+type joiner interface {
+	Join() (leave func())
+}
+
+// join joins the evaluator if it is a joiner and returns the function that
+// leaves it.
+func join(e Evaluator) (leave func()) {
+	if j, ok := e.(joiner); ok {
+		return j.Join()
+	}
+	return func() {}
+}
+
+// Evaluate runs the network on the game's state.
+func (b *Batcher) Evaluate(g *engine.Game) ([]float32, float32, error) {
+	return b.Infer(NewStateEncoder().Encode(g))
+}
+
+// maxRolloutPlies ends a rollout that has not finished as a draw.
+const maxRolloutPlies = 100
+
+// RolloutEvaluator gives every action the same prior and values a position
+// by playing random games from it, so that MCTS and the bots run without
+// ONNX Runtime, in tests for instance. With no rollouts every value is 0.
+type RolloutEvaluator struct {
+	rollouts int
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewRolloutEvaluator creates an evaluator that averages the given number of
+// random games per position. The same seed gives the same values.
+func NewRolloutEvaluator(rollouts int, seed uint64) *RolloutEvaluator {
+	return &RolloutEvaluator{
+		rollouts: rollouts,
+		rng:      rand.New(rand.NewPCG(seed, seed)),
+	}
+}
+
+// Evaluate returns uniform logits and the average result of the rollouts.
+func (e *RolloutEvaluator) Evaluate(g *engine.Game) ([]float32, float32, error) {
+	logits := make([]float32, ActionSpaceSize)
+	if e.rollouts == 0 {
+		return logits, 0, nil
+	}
+
+	// each evaluation draws its own generator, so that workers do not
+	// wait on each other for random numbers
+	e.mu.Lock()
+	rng := rand.New(rand.NewPCG(e.rng.Uint64(), e.rng.Uint64()))
+	e.mu.Unlock()
+
+	position := g.Position()
+	total := float32(0)
+	for range e.rollouts {
+		total += rollout(position, rng)
+	}
+	return logits, total / float32(e.rollouts), nil
+}
+
+// rollout plays random moves from the position until a line is completed,
+// nobody can move or maxRolloutPlies have been played, and returns the
+// result for the side to move in p.
+func rollout(p engine.Position, rng *rand.Rand) float32 {
+	side := p.Turn
+	var buf [engine.MaxMoves]engine.PositionMove
+
+	for range maxRolloutPlies {
+		moves := p.GenerateMoves(buf[:0])
+		if len(moves) == 0 {
+			return 0
+		}
+
+		mover := p.Turn
+		p.MakeMove(moves[rng.IntN(len(moves))])
+		if p.HasFourInARow(mover) {
+			if mover == side {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
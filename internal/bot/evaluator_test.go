@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"testing"
+	"tic-tac-chec/engine"
+)
+
+func TestUniformEvaluator(t *testing.T) {
+	logits, value, err := NewRolloutEvaluator(0, 1).Evaluate(engine.NewGame())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logits) != ActionSpaceSize {
+		t.Fatalf("expected %d logits, got %d", ActionSpaceSize, len(logits))
+	}
+	for action, logit := range logits {
+		if logit != 0 {
+			t.Fatalf("expected uniform logits, action %d has %v", action, logit)
+		}
+	}
+	if value != 0 {
+		t.Errorf("expected value 0 without rollouts, got %v", value)
+	}
+}
+
+func TestRolloutEvaluatorIsSeeded(t *testing.T) {
+	g, err := engine.ParseFEN("PRB1/4/4/1brp Nn w ud 0 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, first, err := NewRolloutEvaluator(64, 7).Evaluate(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, second, err := NewRolloutEvaluator(64, 7).Evaluate(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first != second {
+		t.Errorf("expected the same value for the same seed, got %v and %v", first, second)
+	}
+	if first < -1 || first > 1 {
+		t.Errorf("expected a value in [-1, 1], got %v", first)
+	}
+}
+
+func TestGreedyWithEvaluator(t *testing.T) {
+	m := NewWithEvaluator(NewRolloutEvaluator(0, 1), SearchOptions{})
+
+	g := engine.NewGame()
+	piece, cell, err := m.SelectAction(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Move(piece, cell); err != nil {
+		t.Errorf("expected a legal move, got %v to %v: %v", piece, cell, err)
+	}
+}
+
+func TestRolloutMCTSPlaysFullGame(t *testing.T) {
+	m := NewWithEvaluator(NewRolloutEvaluator(4, 1), SearchOptions{Simulations: 30, Workers: 2})
+
+	g := engine.NewGame()
+	for i := 0; i < 100 && g.Status != engine.GameOver; i++ {
+		piece, cell, err := m.SelectAction(g)
+		if err != nil {
+			t.Fatalf("move %d: %v", i, err)
+		}
+		if err := g.Move(piece, cell); err != nil {
+			t.Fatalf("move %d: illegal move %v to %v: %v", i, piece, cell, err)
+		}
+	}
+}
+
+func TestRolloutMCTSBlocksOpponentWin(t *testing.T) {
+	// black completes the second row with the knight unless white takes d3
+	g, err := engine.ParseFEN("3R/prb1/4/PB2 Nn w ud 0 6")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewWithEvaluator(NewRolloutEvaluator(8, 1), SearchOptions{Simulations: 2000, Workers: 4})
+	piece, cell, err := m.SelectAction(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cell != (engine.Cell{Row: 1, Col: 3}) {
+		t.Errorf("expected a block on d3, got %v to %v", piece, cell)
+	}
+}
@@ -3,164 +3,319 @@ package bot
 import (
 	"fmt"
 	"math"
+	"sync"
 	"tic-tac-chec/engine"
+	"time"
 )
 
 const defaultCPUCT = 1.4
 
+// node is a position of the search tree. Workers search the tree at the
+// same time, so everything that changes after the node is created is
+// guarded by mu. A worker may lock a node's children while holding its
+// lock, never the other way round.
 type node struct {
-	game          *engine.Game
-	parent        *node
-	children      []*node
-	action        int     // action index (0-319) that led here from parent
-	prior         float32 // policy network prior probability
+	// game is the position at the node. Children get it when they are
+	// first expanded, so that the many that never are cost no game.
+	game   *engine.Game
+	parent *node
+	action int // action index (0-319) that led here from parent
+
+	mu       sync.Mutex
+	prior    float32 // policy network prior probability, with root noise
+	children []*node
+	// expanding is set while a worker expands the node and closed when it
+	// is done, for the workers that reach the node meanwhile to wait on.
+	expanding     chan struct{}
 	visitCount    int
 	totalValue    float32 // sum of backpropagated values
+	virtualLoss   int     // simulations in flight through the node
 	isTerminal    bool
 	terminalValue float32 // +1/-1/0 if terminal
+	noised        bool    // whether root noise was added to the children's priors
 }
 
 // ucbScore computes the PUCT score for child selection.
 // Q + cPUCT * prior * sqrt(parentVisits) / (1 + childVisits)
-func (n *node) ucbScore(cPUCT float32) float32 {
-	if n.visitCount == 0 {
+// Simulations in flight count as visits that lost, so that workers spread
+// over the tree instead of all following the same path. n.mu must be held.
+func (n *node) ucbScore(cPUCT float32, parentVisits int) float32 {
+	visits := n.visitCount + n.virtualLoss
+	if visits == 0 {
 		return float32(math.Inf(1))
 	}
-	q := -n.totalValue / float32(n.visitCount) // negamax: parent wants moves good for itself, not the child's player
-	exploration := cPUCT * n.prior * float32(math.Sqrt(float64(n.parent.visitCount))) / float32(1+n.visitCount)
+	q := -(n.totalValue + float32(n.virtualLoss)) / float32(visits) // negamax: parent wants moves good for itself, not the child's player
+	exploration := cPUCT * n.prior * float32(math.Sqrt(float64(parentVisits))) / float32(1+visits)
 	return q + exploration
 }
 
 // mctsSelectAction runs MCTS and returns the best action as (Piece, Cell).
-func mctsSelectAction(b *Model, g *engine.Game, numSimulations int) (engine.Piece, engine.Cell, error) {
+func mctsSelectAction(b *Model, g *engine.Game, opts SearchOptions, budget time.Duration) (engine.Piece, engine.Cell, error) {
 	root := &node{game: g.Clone()}
+	return runMCTS(b, root, g, opts, budget)
+}
 
-	if root.game.Status == engine.GameOver {
-		return engine.Piece{}, engine.Cell{}, fmt.Errorf("model: game is already over")
+// runMCTS searches from root, see search, and returns the most visited
+// action, or the one opts.pick chooses by visits.
+func runMCTS(b *Model, root *node, g *engine.Game, opts SearchOptions, budget time.Duration) (engine.Piece, engine.Cell, error) {
+	if err := search(b, root, opts, budget); err != nil {
+		return engine.Piece{}, engine.Cell{}, err
 	}
 
-	// Expand root node first
-	value, err := expand(b, root)
-	if err != nil {
-		return engine.Piece{}, engine.Cell{}, fmt.Errorf("model: expand root: %w", err)
+	if len(root.children) == 0 {
+		return engine.Piece{}, engine.Cell{}, fmt.Errorf("model: no children after MCTS")
 	}
-	backpropagate(root, value)
 
-	for i := 0; i < numSimulations-1; i++ {
-		leaf := selectLeaf(root)
+	visits := make([]float64, len(root.children))
+	for i, child := range root.children {
+		visits[i] = float64(child.visitCount)
+	}
+	bestChild := root.children[opts.pick(visits)]
 
-		if leaf.isTerminal {
-			// terminalValue=1.0 means parent's player won (the one who moved into this state).
-			// From the leaf's perspective, that's a loss (-1.0).
-			backpropagate(leaf, -leaf.terminalValue)
-		} else {
-			value, err := expand(b, leaf)
-			if err != nil {
-				return engine.Piece{}, engine.Cell{}, fmt.Errorf("model: expand leaf: %w", err)
-			}
-			backpropagate(leaf, value)
-		}
+	return decodeActionToMove(bestChild.action, g)
+}
+
+// search runs simulations from root, which may already have statistics
+// from earlier searches, spread over opts.Workers goroutines. It stops
+// after opts.Simulations simulations or once budget has passed, whichever
+// comes first; zero means no limit, and at least one of them must be set.
+// The first simulation from a fresh root expands it, and always runs.
+func search(b *Model, root *node, opts SearchOptions, budget time.Duration) error {
+	if root.game.Status == engine.GameOver {
+		return fmt.Errorf("model: game is already over")
 	}
 
-	// Pick best child by visit count
-	var bestChild *node
-	for _, child := range root.children {
-		if bestChild == nil || child.visitCount > bestChild.visitCount {
-			bestChild = child
+	limits := newSearchLimits(opts, budget)
+
+	// the root is expanded before the workers start, to have noise added
+	if opts.DirichletAlpha > 0 && len(root.children) == 0 && limits.next() {
+		if err := simulate(b, root); err != nil {
+			return err
 		}
 	}
+	opts.addRootNoise(root)
 
-	if bestChild == nil {
-		return engine.Piece{}, engine.Cell{}, fmt.Errorf("model: no children after MCTS")
+	var (
+		errOnce sync.Once
+		err     error
+		wg      sync.WaitGroup
+	)
+	for range max(opts.Workers, 1) {
+		wg.Go(func() {
+			// every worker joins, so that a batch is run as soon as all of
+			// them wait on it
+			leave := join(b.eval)
+			defer leave()
+
+			for limits.next() {
+				if simErr := simulate(b, root); simErr != nil {
+					errOnce.Do(func() { err = simErr })
+					limits.stop()
+					return
+				}
+				limits.checkEarlyStop(root)
+			}
+		})
 	}
+	wg.Wait()
 
-	return decodeActionToMove(bestChild.action, g)
+	return err
 }
 
-func selectLeaf(n *node) *node {
-	if len(n.children) == 0 {
-		return n
-	}
-
-	highestUcb := float32(-1e9)
+// mostVisited returns the child with the most visits, the first of them on
+// a tie, or nil if n has no children. It must not run during a search.
+func (n *node) mostVisited() *node {
 	var best *node
 	for _, child := range n.children {
-		ucb := child.ucbScore(defaultCPUCT)
-		if ucb > highestUcb {
-			highestUcb = ucb
+		if best == nil || child.visitCount > best.visitCount {
 			best = child
 		}
 	}
+	return best
+}
+
+// simulate runs one simulation: it selects a leaf, expands it unless the
+// game is over there, and backpropagates its value.
+func simulate(b *Model, root *node) error {
+	leaf, expandLeaf := selectLeaf(root)
+
+	if !expandLeaf {
+		// terminalValue=1.0 means parent's player won (the one who moved into this state).
+		// From the leaf's perspective, that's a loss (-1.0).
+		backpropagate(leaf, -leaf.terminalValue)
+		return nil
+	}
+
+	value, err := expand(b, leaf)
+	if err != nil {
+		revertVirtualLoss(leaf)
+		if leaf == root {
+			return fmt.Errorf("model: expand root: %w", err)
+		}
+		return fmt.Errorf("model: expand leaf: %w", err)
+	}
+	backpropagate(leaf, value)
+	return nil
+}
+
+// selectLeaf walks down from n by PUCT score, adding a virtual loss to every
+// node on the way, to a terminal node or to a node that is not expanded yet.
+// It reports whether the caller must expand the leaf, in which case the leaf
+// is marked as expanding. A node another worker is expanding is waited for,
+// then walked past.
+func selectLeaf(n *node) (*node, bool) {
+	for {
+		n.mu.Lock()
+
+		if len(n.children) == 0 && !n.isTerminal {
+			if n.expanding == nil {
+				n.virtualLoss++
+				n.expanding = make(chan struct{})
+				n.mu.Unlock()
+				return n, true
+			}
+
+			expanding := n.expanding
+			n.mu.Unlock()
+			<-expanding
+			continue
+		}
+
+		n.virtualLoss++
+		if n.isTerminal {
+			n.mu.Unlock()
+			return n, false
+		}
+
+		parentVisits := n.visitCount + n.virtualLoss
+		highestUcb := float32(-1e9)
+		var best *node
+		for _, child := range n.children {
+			child.mu.Lock()
+			ucb := child.ucbScore(defaultCPUCT, parentVisits)
+			child.mu.Unlock()
+			if ucb > highestUcb {
+				highestUcb = ucb
+				best = child
+			}
+		}
+		n.mu.Unlock()
 
-	return selectLeaf(best)
+		n = best
+	}
 }
 
-// expand creates child nodes for all legal actions from the given node.
-// Calls the neural network to get policy priors and value estimate.
+// expand creates child nodes for all legal actions from the given node,
+// which selectLeaf marked as expanding, after playing the node's action if
+// it has no game yet. A node where the game is over becomes terminal.
+// Calls the evaluator to get policy priors and value estimate.
 // Returns the value estimate (from the node's player-to-move perspective)
 // for the caller to backpropagate.
 func expand(b *Model, n *node) (float32, error) {
-	state := NewStateEncoder().Encode(n.game)
-	logits, value, err := b.InferWithValue(state)
+	var (
+		children []*node
+		value    float32
+		err      error
+	)
+	if n.game == nil {
+		// no other worker reads the game before the node is expanded
+		n.game, err = n.play()
+	}
+	if err == nil && n.game.Status != engine.GameOver {
+		children, value, err = newChildren(b, n)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// on failure the node is left unexpanded, for another worker to try
+	close(n.expanding)
+	n.expanding = nil
 	if err != nil {
 		return 0, err
 	}
 
-	legal := legalActions(n.game)
-	if len(legal) == 0 {
+	if n.game.Status == engine.GameOver {
+		n.isTerminal = true
+		if n.game.Winner != nil {
+			n.terminalValue = 1.0 // the player who moved just won
+		} else {
+			n.terminalValue = 0 // draw by repetition or move limit
+		}
+		return -n.terminalValue, nil
+	}
+
+	if len(children) == 0 {
 		n.isTerminal = true
 		n.terminalValue = 0
 		return 0, nil
 	}
+	n.children = children
 
-	priors := maskedSoftmax(logits, legal)
+	return value, nil
+}
 
-	for i, action := range legal {
-		child := &node{
-			game:   n.game.Clone(),
-			parent: n,
-			action: action,
-			prior:  priors[i],
-		}
+// newChildren evaluates n and returns its children, none if it has no
+// legal action, and its value estimate.
+func newChildren(b *Model, n *node) ([]*node, float32, error) {
+	logits, value, err := b.eval.Evaluate(n.game)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		piece, src, dst, isDrop := DecodeAction(action, child.game.Turn)
-		var moveErr error
-		if isDrop {
-			moveErr = child.game.Move(piece, dst)
-		} else {
-			boardPiece := child.game.Board[src.Row][src.Col]
-			if boardPiece != nil {
-				moveErr = child.game.Move(*boardPiece, dst)
-			} else {
-				return 0, fmt.Errorf("model: no piece at %d,%d", src.Row, src.Col)
-			}
-		}
+	legal := legalActions(n.game)
+	if len(legal) == 0 {
+		return nil, 0, nil
+	}
 
-		if moveErr != nil {
-			return 0, fmt.Errorf("model: applying action %d: %w", action, moveErr)
-		}
+	priors := maskedSoftmax(logits, legal)
 
-		if child.game.Status == engine.GameOver {
-			child.isTerminal = true
-			child.terminalValue = 1.0 // the player who moved just won
-		}
+	children := make([]*node, len(legal))
+	for i, action := range legal {
+		children[i] = &node{parent: n, action: action, prior: priors[i]}
+	}
 
-		n.children = append(n.children, child)
+	return children, value, nil
+}
+
+// play returns the game after the node's action, played on a clone of its
+// parent's game.
+func (n *node) play() (*engine.Game, error) {
+	piece, cell, err := decodeActionToMove(n.action, n.parent.game)
+	if err != nil {
+		return nil, err
 	}
 
-	return value, nil
+	g := n.parent.game.Clone()
+	if err := g.Move(piece, cell); err != nil {
+		return nil, fmt.Errorf("model: applying action %d: %w", n.action, err)
+	}
+	return g, nil
 }
 
-// backpropagate updates visit counts and values from leaf to root.
+// backpropagate updates visit counts and values from leaf to root, and
+// takes back the virtual loss selectLeaf added.
 // value is from the perspective of the player at the given node.
 func backpropagate(n *node, value float32) {
 	for current := n; current != nil; current = current.parent {
+		current.mu.Lock()
 		current.visitCount++
 		current.totalValue += value
+		current.virtualLoss--
+		current.mu.Unlock()
 		value = -value // flip perspective at each level
 	}
 }
 
+// revertVirtualLoss takes back the virtual loss of a failed simulation.
+func revertVirtualLoss(n *node) {
+	for current := n; current != nil; current = current.parent {
+		current.mu.Lock()
+		current.virtualLoss--
+		current.mu.Unlock()
+	}
+}
+
 // maskedSoftmax computes softmax over only the legal action indices.
 // Returns probabilities in the same order as the legal slice.
 func maskedSoftmax(logits []float32, legal []int) []float32 {
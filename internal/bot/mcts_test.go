@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+	"tic-tac-chec/engine"
+)
+
+func TestParallelMCTS(t *testing.T) {
+	m := fakeModel(t, SearchOptions{Simulations: 400, Workers: 8})
+	g := engine.NewGame()
+	root := &node{game: g.Clone()}
+
+	if _, _, err := runMCTS(m, root, g, m.opts, 0); err != nil {
+		t.Fatal(err)
+	}
+	if root.visitCount != 400 {
+		t.Errorf("expected 400 visits, got %d", root.visitCount)
+	}
+	checkStatistics(t, root)
+}
+
+func TestParallelMCTSFindsWinningMove(t *testing.T) {
+	// white completes the top row with the knight
+	g, err := engine.ParseFEN("PRB1/4/4/1brp Nn w ud 0 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := fakeModel(t, SearchOptions{Simulations: 200, Workers: 4})
+	piece, cell, err := m.SelectAction(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if piece != engine.WhiteKnight || cell != (engine.Cell{Row: 0, Col: 3}) {
+		t.Errorf("expected the knight drop on d4, got %v to %v", piece, cell)
+	}
+}
+
+func TestParallelMCTSError(t *testing.T) {
+	failure := errors.New("no network")
+	batcher := newBatcher(func([]float32, int) ([]float32, []float32, error) {
+		return nil, nil, failure
+	}, BatchOptions{})
+	defer batcher.Close()
+
+	m := NewWithEvaluator(batcher, SearchOptions{Simulations: 100, Workers: 4})
+	g := engine.NewGame()
+	root := &node{game: g.Clone()}
+
+	if _, _, err := runMCTS(m, root, g, m.opts, 0); !errors.Is(err, failure) {
+		t.Fatalf("expected the network error, got %v", err)
+	}
+	if root.virtualLoss != 0 || root.expanding != nil || len(root.children) != 0 {
+		t.Error("expected the failed search to leave the root as it was")
+	}
+}
+
+// checkStatistics checks that a finished search left no virtual loss and
+// that every expanded node was visited once by its own expansion and once
+// for every visit of its children.
+func checkStatistics(t *testing.T, n *node) {
+	t.Helper()
+
+	if n.virtualLoss != 0 {
+		t.Errorf("action %d: %d virtual losses left", n.action, n.virtualLoss)
+	}
+	if len(n.children) == 0 {
+		return
+	}
+
+	visits := 1
+	for _, child := range n.children {
+		visits += child.visitCount
+		checkStatistics(t, child)
+	}
+	if n.visitCount != visits {
+		t.Errorf("action %d: %d visits, its children account for %d", n.action, n.visitCount, visits)
+	}
+}
+
+// BenchmarkMCTS searches a middlegame position with uniform priors, so that
+// the tree and the engine are measured rather than the evaluator.
+func BenchmarkMCTS(b *testing.B) {
+	g, err := engine.ParseFEN("r1b1/1P2/2N1/B2p Rn w uu 3 9")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for range 20 {
+		// a history, as games have when the bot moves
+		moves := g.AllLegalMoves()
+		c := g.Clone()
+		if err := c.Play(moves[0]); err != nil || c.Status == engine.GameOver {
+			break
+		}
+		g = c
+	}
+
+	m := NewWithEvaluator(NewRolloutEvaluator(0, 1), SearchOptions{Simulations: 800})
+	b.ReportAllocs()
+	for b.Loop() {
+		root := &node{game: g.Clone()}
+		if err := search(m, root, m.opts, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
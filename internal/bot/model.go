@@ -1,25 +1,64 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
-	"math"
-	"math/rand"
 	"os"
 	"strconv"
 	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/bot/player"
 	"tic-tac-chec/internal/game"
 	"time"
-
-	ort "github.com/yalue/onnxruntime_go"
-	"go.opentelemetry.io/contrib/bridges/otelslog"
 )
 
-var logger = otelslog.NewLogger("tic-tac-chec/internal/bot")
+// ErrUnsupportedRules is returned for games that are not played under
+// engine.StandardRules: the network only knows the 4x4 game.
+var ErrUnsupportedRules = errors.New("bot: only the standard rules are supported")
 
-// Model plays Tic Tac Chec using an ONNX neural network model.
+// Model plays Tic Tac Chec with MCTS or greedy selection over an
+// Evaluator, usually the ONNX neural network model.
 type Model struct {
-	session     *ort.DynamicAdvancedSession
-	simulations int
+	eval Evaluator
+	// owned is the batcher the model created, which Destroy closes.
+	owned *Batcher
+	opts  SearchOptions
+}
+
+// SearchOptions tunes the MCTS of a Model.
+type SearchOptions struct {
+	// Simulations is the most MCTS simulations per move, 0 for no limit.
+	// Without ThinkTime either, the bot picks the action with the highest
+	// logit without searching.
+	Simulations int
+	// Workers is the number of goroutines that run the simulations,
+	// 1 if zero.
+	Workers int
+	// ThinkTime is how long the MCTS thinks about a move, 0 for no limit,
+	// see Budget.
+	ThinkTime time.Duration
+	// EarlyStop ends the search as soon as the most visited move cannot be
+	// overtaken in the simulations or time left.
+	EarlyStop bool
+
+	// Temperature, when above 0, samples the move instead of playing the
+	// most visited one, or the most likely one without a search: with
+	// probabilities following visits or priors at 1, sharper below,
+	// flatter above.
+	Temperature float64
+	// DirichletAlpha and DirichletWeight mix Dirichlet noise of that
+	// concentration into that share of the root priors; 0 disables it.
+	DirichletAlpha  float64
+	DirichletWeight float64
+	// BlunderChance is the probability of playing one of the BlunderDepth
+	// moves ranked after the best one, picked at random, instead of
+	// choosing as above. A BlunderDepth of 0 counts as 1.
+	BlunderChance float64
+	BlunderDepth  int
+}
+
+// searches reports whether the options call for MCTS rather than argmax.
+func (opts SearchOptions) searches() bool {
+	return opts.Simulations > 0 || opts.ThinkTime > 0
 }
 
 // New creates a Bot that loads the ONNX model from the given path.
@@ -31,128 +70,67 @@ type Model struct {
 // and ort.DestroyEnvironment() when done.
 // TODO: pass store.Bot
 func New(modelPath string, simulations int) (*Model, error) {
-	session, err := ort.NewDynamicAdvancedSession(
-		modelPath,
-		[]string{"state"},
-		[]string{"action_logits", "state_value"},
-		nil,
-	)
+	batcher, err := NewBatcher(modelPath, BatchOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("bot: load model: %w", err)
+		return nil, err
 	}
 
-	bot := &Model{
-		session:     session,
-		simulations: simulations,
-	}
+	bot := NewWithEvaluator(batcher, SearchOptions{Simulations: simulations})
+	bot.owned = batcher
 
 	return bot, nil
 }
 
-// Infer runs the model on a game state and returns action logits (320 floats).
-func (m *Model) Infer(state []float32) ([]float32, error) {
-	inputShape := ort.Shape{1, NumChannels, BoardSize, BoardSize}
-	input, err := ort.NewTensor(inputShape, state)
-	if err != nil {
-		return nil, fmt.Errorf("bot: create input tensor: %w", err)
-	}
-	defer input.Destroy()
-
-	outputShape := ort.Shape{1, ActionSpaceSize}
-	output, err := ort.NewEmptyTensor[float32](outputShape)
-	if err != nil {
-		return nil, fmt.Errorf("bot: create output tensor: %w", err)
-	}
-	defer output.Destroy()
-
-	// We need a second output for state_value even though we don't use it
-	valueShape := ort.Shape{1, 1}
-	valueOutput, err := ort.NewEmptyTensor[float32](valueShape)
-	if err != nil {
-		return nil, fmt.Errorf("bot: create value tensor: %w", err)
-	}
-	defer valueOutput.Destroy()
-
-	err = m.session.Run([]ort.ArbitraryTensor{input}, []ort.ArbitraryTensor{output, valueOutput})
-	if err != nil {
-		return nil, fmt.Errorf("bot: run inference: %w", err)
+// NewWithEvaluator creates a Bot that judges positions with the given
+// evaluator. Bots of several difficulties using the same model share its
+// Batcher, so that their requests are batched together. Destroy leaves the
+// evaluator open.
+func NewWithEvaluator(eval Evaluator, opts SearchOptions) *Model {
+	return &Model{
+		eval: eval,
+		opts: opts,
 	}
-
-	logits := make([]float32, ActionSpaceSize)
-	copy(logits, output.GetData())
-	return logits, nil
-}
-
-// InferWithValue runs the model and returns both action logits (320 floats)
-// and the state value estimate (single float).
-func (m *Model) InferWithValue(state []float32) ([]float32, float32, error) {
-	inputShape := ort.Shape{1, NumChannels, BoardSize, BoardSize}
-	input, err := ort.NewTensor(inputShape, state)
-	if err != nil {
-		return nil, 0, fmt.Errorf("bot: create input tensor: %w", err)
-	}
-	defer input.Destroy()
-
-	outputShape := ort.Shape{1, ActionSpaceSize}
-	output, err := ort.NewEmptyTensor[float32](outputShape)
-	if err != nil {
-		return nil, 0, fmt.Errorf("bot: create output tensor: %w", err)
-	}
-	defer output.Destroy()
-
-	valueShape := ort.Shape{1, 1}
-	valueOutput, err := ort.NewEmptyTensor[float32](valueShape)
-	if err != nil {
-		return nil, 0, fmt.Errorf("bot: create value tensor: %w", err)
-	}
-	defer valueOutput.Destroy()
-
-	err = m.session.Run([]ort.ArbitraryTensor{input}, []ort.ArbitraryTensor{output, valueOutput})
-	if err != nil {
-		return nil, 0, fmt.Errorf("bot: run inference: %w", err)
-	}
-
-	logits := make([]float32, ActionSpaceSize)
-	copy(logits, output.GetData())
-	value := valueOutput.GetData()[0]
-	return logits, value, nil
 }
 
 // SelectAction picks the best legal action for the current position.
-// If simulations > 0, uses MCTS; otherwise uses greedy argmax.
+// If simulations or a think time are set, uses MCTS; otherwise uses
+// greedy argmax.
 func (m *Model) SelectAction(g *engine.Game) (engine.Piece, engine.Cell, error) {
-	if m.simulations > 0 {
+	if !g.Rules.Standard() {
+		return engine.Piece{}, engine.Cell{}, ErrUnsupportedRules
+	}
+
+	if m.opts.searches() {
 		return m.selectActionMCTS(g)
 	}
 	return m.selectActionArgmax(g)
 }
 
 // selectActionArgmax picks the best legal action given logits and a game state.
-// Applies action masking: illegal actions get -inf, then picks argmax.
+// Applies action masking: illegal actions get -inf, then picks argmax, or
+// samples or blunders as the options say.
 func (m *Model) selectActionArgmax(g *engine.Game) (engine.Piece, engine.Cell, error) {
-	state := NewStateEncoder().Encode(g)
+	leave := join(m.eval)
+	defer leave()
 
-	logits, err := m.Infer(state)
+	logits, _, err := m.eval.Evaluate(g)
 	if err != nil {
 		return engine.Piece{}, engine.Cell{}, err
 	}
 
 	// Build legal action set
 	legal := legalActions(g)
-
-	// Mask illegal actions and find argmax
-	bestAction := -1
-	bestScore := float32(math.Inf(-1))
-	for _, action := range legal {
-		if logits[action] > bestScore {
-			bestScore = logits[action]
-			bestAction = action
-		}
+	if len(legal) == 0 {
+		return engine.Piece{}, engine.Cell{}, fmt.Errorf("bot: no legal actions")
 	}
 
-	if bestAction == -1 {
-		return engine.Piece{}, engine.Cell{}, fmt.Errorf("bot: no legal actions")
+	// Mask illegal actions and pick by policy, the argmax unless the
+	// options add randomness
+	priors := make([]float64, len(legal))
+	for i, p := range maskedSoftmax(logits, legal) {
+		priors[i] = float64(p)
 	}
+	bestAction := legal[m.opts.pick(priors)]
 
 	piece, src, dst, isDrop := DecodeAction(bestAction, g.Turn)
 	if isDrop {
@@ -170,97 +148,41 @@ func (m *Model) selectActionArgmax(g *engine.Game) (engine.Piece, engine.Cell, e
 
 // selectActionMCTS delegates to mctsSelectAction (defined in mcts.go).
 func (m *Model) selectActionMCTS(g *engine.Game) (engine.Piece, engine.Cell, error) {
-	return mctsSelectAction(m, g, m.simulations)
+	return mctsSelectAction(m, g, m.opts, m.opts.Budget(g, 0))
 }
 
 // legalActions returns valid action indices for the current player.
+// It generates moves on the game's bitboard position, which avoids
+// the allocations of engine.Game.AllLegalMoves.
 func legalActions(g *engine.Game) []int {
-	var actions []int
-
-	// Drop actions (0-63)
-	for kindIdx := range int(engine.PieceKindCount) {
-		piece := engine.Piece{Color: g.Turn, Kind: engine.PieceKind(kindIdx)}
-		if !g.PieceInHand(piece) {
-			continue
-		}
-		for row := range BoardSize {
-			for col := range BoardSize {
-				if g.Board[row][col] == nil {
-					actions = append(actions, kindIdx*16+row*4+col)
-				}
-			}
-		}
+	if g.Status == engine.GameOver {
+		return nil
 	}
 
-	// Move actions (64-319)
-	for row := range BoardSize {
-		for col := range BoardSize {
-			p := g.Board[row][col]
-			if p == nil || p.Color != g.Turn {
-				continue
-			}
+	position := g.Position()
+	var buf [engine.MaxMoves]engine.PositionMove
+	moves := position.GenerateMoves(buf[:0])
 
-			moves := g.LegalMoves(*p)
-			srcIdx := row*BoardSize + col
-			for _, target := range moves {
-				dstIdx := target.Row*BoardSize + target.Col
-				actions = append(actions, 64+srcIdx*16+dstIdx)
-			}
-		}
+	actions := make([]int, len(moves))
+	for i, move := range moves {
+		actions[i] = EncodePositionMove(move)
 	}
 
 	return actions
 }
 
-// RunPlayer creates a game.Player backed by the bot and starts a goroutine
-// that listens for game events and responds with moves.
+// RunPlayer creates a game.Player backed by the bot, see player.Run.
+// With MCTS each player keeps its own search tree between moves.
 func (m *Model) RunPlayer(playerID string) game.Player {
-	commands := make(chan game.Command, 2)
-	player := game.NewPlayerWithID(commands, playerID)
-
-	go m.playLoop(&player, commands)
-
-	return player
-}
-
-// playLoop is the bot's event loop goroutine. It listens for game events
-// and responds with moves when it's the bot's turn.
-func (m *Model) playLoop(player *game.Player, commands chan game.Command) {
-	botColor := engine.White
-	botPlayerID := player.ID
-
-	for event := range player.Updates {
-		switch e := event.(type) {
-		case game.PairedEvent:
-			botColor = e.Color
-		case game.SnapshotEvent:
-			if e.Game.Status == engine.GameOver {
-				time.Sleep(500 * time.Millisecond)
-				emoji := game.ReactionEmojis[rand.Intn(len(game.ReactionEmojis))]
-				commands <- game.ReactionCommand{PlayerID: botPlayerID, Reaction: emoji}
-				commands <- game.RematchCommand{PlayerID: botPlayerID}
-				continue
-			}
-
-			if e.Game.Turn == botColor {
-				piece, cell, err := m.SelectAction(&e.Game)
-				if err != nil {
-					logger.Error("bot.select_action_failed", "err", err)
-					continue
-				}
-				commands <- game.MoveCommand{Piece: piece, To: cell}
-			}
-		case game.RematchRequestedEvent:
-			commands <- game.RematchCommand{PlayerID: botPlayerID}
-		default:
-			// ignore
-		}
+	if m.opts.searches() {
+		return player.Run(newTreePlayer(m), playerID)
 	}
+	return player.Run(m, playerID)
 }
 
 func (m *Model) Destroy() {
-	if m.session != nil {
-		m.session.Destroy()
+	if m.owned != nil {
+		m.owned.Close()
 	}
 }
 
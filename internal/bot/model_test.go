@@ -3,16 +3,22 @@
 package bot
 
 import (
+	"encoding/json"
+	"flag"
+	"math"
 	"os"
 	"path/filepath"
 	"testing"
 	"tic-tac-chec/engine"
+	"time"
 
 	ort "github.com/yalue/onnxruntime_go"
 )
 
 var testModelPath string
 
+var updateGolden = flag.Bool("update-golden", false, "write the onnxruntime outputs of the exported models to native/testdata")
+
 func TestMain(m *testing.M) {
 	// Find project root (internal/model -> ../../)
 	root := filepath.Join("..", "..")
@@ -28,68 +34,16 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
-func TestDecodeDropAction(t *testing.T) {
-	// Action 0 = Pawn drop at (0,0)
-	piece, _, dst, isDrop := DecodeAction(0, engine.White)
-	if !isDrop {
-		t.Fatal("expected drop action")
-	}
-	if piece.Kind != engine.Pawn || piece.Color != engine.White {
-		t.Fatalf("expected White Pawn, got %v", piece)
-	}
-	if dst.Row != 0 || dst.Col != 0 {
-		t.Fatalf("expected (0,0), got %v", dst)
-	}
-
-	// Action 63 = Knight drop at (3,3)
-	piece, _, dst, isDrop = DecodeAction(63, engine.Black)
-	if !isDrop {
-		t.Fatal("expected drop action")
-	}
-	if piece.Kind != engine.Knight || piece.Color != engine.Black {
-		t.Fatalf("expected Black Knight, got %v", piece)
-	}
-	if dst.Row != 3 || dst.Col != 3 {
-		t.Fatalf("expected (3,3), got %v", dst)
-	}
-}
-
-func TestDecodeMoveAction(t *testing.T) {
-	// Action 64 = move from (0,0) to (0,0) — self-move, always masked
-	_, src, dst, isDrop := DecodeAction(64, engine.White)
-	if isDrop {
-		t.Fatal("expected move action")
-	}
-	if src.Row != 0 || src.Col != 0 {
-		t.Fatalf("expected src (0,0), got %v", src)
-	}
-	if dst.Row != 0 || dst.Col != 0 {
-		t.Fatalf("expected dst (0,0), got %v", dst)
-	}
-
-	// Action 319 = move from (3,3) to (3,3)
-	_, src, dst, isDrop = DecodeAction(319, engine.White)
-	if isDrop {
-		t.Fatal("expected move action")
-	}
-	if src.Row != 3 || src.Col != 3 {
-		t.Fatalf("expected src (3,3), got %v", src)
-	}
-	if dst.Row != 3 || dst.Col != 3 {
-		t.Fatalf("expected dst (3,3), got %v", dst)
-	}
-}
-
 func TestInferWithZeros(t *testing.T) {
-	model, err := New(testModelPath, 0)
+	batcher, err := NewBatcher(testModelPath, BatchOptions{})
 	if err != nil {
-		t.Fatalf("failed to create model: %v", err)
+		t.Fatalf("failed to create batcher: %v", err)
 	}
-	defer model.Destroy()
+	defer batcher.Close()
 
 	// All-zero state (not a real game state, but tests the inference pipeline)
 	state := make([]float32, StateSize)
-	logits, err := model.Infer(state)
+	logits, _, err := batcher.Infer(state)
 	if err != nil {
 		t.Fatalf("inference failed: %v", err)
 	}
@@ -111,6 +65,66 @@ func TestInferWithZeros(t *testing.T) {
 	}
 }
 
+// TestBatchedInferenceMatchesSingle runs several positions as one batch and
+// checks each result against running the position alone.
+func TestBatchedInferenceMatchesSingle(t *testing.T) {
+	batcher, err := NewBatcher(testModelPath, BatchOptions{Window: time.Second})
+	if err != nil {
+		t.Fatalf("failed to create batcher: %v", err)
+	}
+	defer batcher.Close()
+
+	g := engine.NewGame()
+	var states [][]float32
+	for _, move := range []struct {
+		piece engine.Piece
+		cell  engine.Cell
+	}{
+		{engine.WhiteRook, engine.Cell{Row: 0, Col: 0}},
+		{engine.BlackBishop, engine.Cell{Row: 3, Col: 3}},
+		{engine.WhiteKnight, engine.Cell{Row: 1, Col: 2}},
+		{engine.BlackPawn, engine.Cell{Row: 2, Col: 1}},
+	} {
+		if err := g.Move(move.piece, move.cell); err != nil {
+			t.Fatal(err)
+		}
+		states = append(states, NewStateEncoder().Encode(g))
+	}
+
+	futures := make([]*Future, len(states))
+	leaves := make([]func(), len(states))
+	for i := range states {
+		leaves[i] = batcher.Join()
+	}
+	for i, state := range states {
+		futures[i] = batcher.Submit(state)
+	}
+	for _, leave := range leaves {
+		leave()
+	}
+
+	for i, state := range states {
+		logits, value, err := futures[i].Wait()
+		if err != nil {
+			t.Fatalf("batched inference failed: %v", err)
+		}
+		want, wantValue, err := batcher.Infer(state)
+		if err != nil {
+			t.Fatalf("inference failed: %v", err)
+		}
+
+		if math.Abs(float64(value-wantValue)) > 1e-4 {
+			t.Errorf("state %d: batched value %v, alone %v", i, value, wantValue)
+		}
+		for a := range logits {
+			if math.Abs(float64(logits[a]-want[a])) > 1e-4 {
+				t.Errorf("state %d: batched logit %d is %v, alone %v", i, a, logits[a], want[a])
+				break
+			}
+		}
+	}
+}
+
 func TestSelectAction(t *testing.T) {
 	model, err := New(testModelPath, 0)
 	if err != nil {
@@ -235,3 +249,91 @@ func TestMCTSFindsWinningMove(t *testing.T) {
 // 		t.Errorf("MCTS should block at (1,3), got piece=%v cell=%v", piece, cell)
 // 	}
 // }
+
+// TestNativeMatchesONNXRuntime checks the pure-Go network against ONNX
+// Runtime, the reference, on every exported model and positions from a
+// game. With -update-golden it also writes the reference outputs to
+// native/testdata, for the native tests to check against without ONNX
+// Runtime.
+func TestNativeMatchesONNXRuntime(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(filepath.Dir(testModelPath), "*.onnx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := engine.NewGame()
+	states := [][]float32{NewStateEncoder().Encode(g)}
+	for len(states) < 12 && g.Status != engine.GameOver {
+		if err := g.Play(g.AllLegalMoves()[len(states)%3]); err != nil {
+			t.Fatal(err)
+		}
+		states = append(states, NewStateEncoder().Encode(g))
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			ortBatcher, err := NewBatcher(path, BatchOptions{})
+			if err != nil {
+				t.Fatalf("failed to create batcher: %v", err)
+			}
+			defer ortBatcher.Close()
+			nativeBatcher, err := NewNativeBatcher(path, BatchOptions{})
+			if err != nil {
+				t.Fatalf("failed to create native batcher: %v", err)
+			}
+			defer nativeBatcher.Close()
+
+			golden := nativeGolden{States: states}
+			for i, state := range states {
+				want, wantValue, err := ortBatcher.Infer(state)
+				if err != nil {
+					t.Fatalf("inference failed: %v", err)
+				}
+				golden.Logits = append(golden.Logits, want)
+				golden.Values = append(golden.Values, wantValue)
+				logits, value, err := nativeBatcher.Infer(state)
+				if err != nil {
+					t.Fatalf("native inference failed: %v", err)
+				}
+
+				if math.Abs(float64(value-wantValue)) > 1e-3 {
+					t.Errorf("state %d: native value %v, onnxruntime %v", i, value, wantValue)
+				}
+				for a := range logits {
+					if math.Abs(float64(logits[a]-want[a])) > 1e-3 {
+						t.Errorf("state %d: native logit %d is %v, onnxruntime %v", i, a, logits[a], want[a])
+						break
+					}
+				}
+			}
+
+			if *updateGolden {
+				writeNativeGolden(t, filepath.Base(path), golden)
+			}
+		})
+	}
+}
+
+// nativeGolden is the format of native/testdata/<model>.json, read by
+// TestBotModelsMatchGolden.
+type nativeGolden struct {
+	States [][]float32 `json:"states"`
+	Logits [][]float32 `json:"logits"`
+	Values []float32   `json:"values"`
+}
+
+func writeNativeGolden(t *testing.T, model string, golden nativeGolden) {
+	t.Helper()
+
+	data, err := json.Marshal(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join("native", "testdata", model+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package native runs the bot's policy/value network in pure Go, without
+// ONNX Runtime and its shared library. It reads the ONNX files exported by
+// bot/training/export.py and evaluates the subset of operators they use:
+// convolutions, residual additions, ReLU and the fully connected heads.
+package native
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+const (
+	// InputName is the graph input the states are fed to.
+	InputName = "state"
+	// LogitsName and ValueName are the graph outputs of the policy and
+	// value heads.
+	LogitsName = "action_logits"
+	ValueName  = "state_value"
+)
+
+// ErrUnsupported is returned for models that use operators or attributes
+// outside the supported subset.
+var ErrUnsupported = errors.New("native: unsupported model")
+
+// Network is a loaded policy/value network. It is safe for concurrent use.
+type Network struct {
+	graph *graph
+	// sampleDims is the shape of one state, the input without its batch
+	// dimension.
+	sampleDims []int
+	sampleSize int
+}
+
+// Load reads the ONNX model at path, and its external data files, and
+// checks that every operator it uses is supported.
+func Load(path string) (*Network, error) {
+	g, err := loadONNX(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var input *valueInfo
+	for i, in := range g.inputs {
+		if in.name == InputName {
+			input = &g.inputs[i]
+		}
+	}
+	if input == nil || len(input.dims) < 2 {
+		return nil, fmt.Errorf("%w: no batched input %q", ErrUnsupported, InputName)
+	}
+	for _, name := range []string{LogitsName, ValueName} {
+		if !slices.ContainsFunc(g.outputs, func(out valueInfo) bool { return out.name == name }) {
+			return nil, fmt.Errorf("%w: no output %q", ErrUnsupported, name)
+		}
+	}
+
+	for _, n := range g.nodes {
+		if _, ok := operators[n.opType]; !ok {
+			return nil, fmt.Errorf("%w: operator %s", ErrUnsupported, n.opType)
+		}
+	}
+
+	net := &Network{graph: g, sampleDims: input.dims[1:], sampleSize: 1}
+	for _, dim := range net.sampleDims {
+		if dim <= 0 {
+			return nil, fmt.Errorf("%w: input dimensions %v", ErrUnsupported, input.dims)
+		}
+		net.sampleSize *= dim
+	}
+
+	return net, nil
+}
+
+// SampleSize is the number of floats of one state.
+func (net *Network) SampleSize() int {
+	return net.sampleSize
+}
+
+// Run evaluates n states laid out one after another and returns the
+// logits of all of them, one after another, and their values. The states
+// are split among the CPUs.
+func (net *Network) Run(states []float32, n int) (logits, values []float32, err error) {
+	if len(states) != n*net.sampleSize {
+		return nil, nil, fmt.Errorf("native: %d floats for %d states of %d", len(states), n, net.sampleSize)
+	}
+
+	chunks := min(n, runtime.GOMAXPROCS(0))
+	if chunks == 0 {
+		return nil, nil, nil
+	}
+
+	type result struct {
+		logits, values []float32
+		err            error
+	}
+	results := make([]result, chunks)
+
+	var wg sync.WaitGroup
+	for i := range chunks {
+		wg.Go(func() {
+			from, to := i*n/chunks, (i+1)*n/chunks
+			input := &tensor{
+				dims: append([]int{to - from}, net.sampleDims...),
+				data: states[from*net.sampleSize : to*net.sampleSize],
+			}
+			r := &results[i]
+			r.logits, r.values, r.err = net.forward(input)
+		})
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		if r.err != nil {
+			return nil, nil, r.err
+		}
+		logits = append(logits, r.logits...)
+		values = append(values, r.values...)
+	}
+	return logits, values, nil
+}
+
+// forward runs the graph on one batch.
+func (net *Network) forward(input *tensor) ([]float32, []float32, error) {
+	values := map[string]*tensor{InputName: input}
+	lookup := func(name string) *tensor {
+		if t, ok := values[name]; ok {
+			return t
+		}
+		return net.graph.initializers[name]
+	}
+
+	for _, n := range net.graph.nodes {
+		inputs := make([]*tensor, len(n.inputs))
+		for i, name := range n.inputs {
+			// optional inputs are left out with an empty name
+			if name == "" {
+				continue
+			}
+			if inputs[i] = lookup(name); inputs[i] == nil {
+				return nil, nil, fmt.Errorf("native: %s reads unknown value %q", n.opType, name)
+			}
+		}
+
+		out, err := operators[n.opType](n, inputs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("native: %s: %w", n.opType, err)
+		}
+		values[n.outputs[0]] = out
+	}
+
+	logits, value := values[LogitsName], values[ValueName]
+	if logits == nil || value == nil {
+		return nil, nil, fmt.Errorf("native: the graph does not compute its outputs")
+	}
+	return logits.data, value.data, nil
+}
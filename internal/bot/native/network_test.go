@@ -0,0 +1,400 @@
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The test model is a small version of the bot's network: a convolution,
+// one residual block and the two heads, on 3×3 boards of 2 channels.
+const (
+	testChannels = 2
+	testFilters  = 3
+	testSize     = 3
+	testActions  = 5
+	testCells    = testSize * testSize
+)
+
+type testWeights struct {
+	conv, conv1, conv2             []float32 // filters × in × 3 × 3
+	convBias, conv1Bias, conv2Bias []float32
+	actor, critic                  []float32 // out × filters*cells
+	actorBias, criticBias          []float32
+}
+
+func randomWeights(rng *rand.Rand) testWeights {
+	random := func(n int) []float32 {
+		values := make([]float32, n)
+		for i := range values {
+			values[i] = rng.Float32()*2 - 1
+		}
+		return values
+	}
+
+	return testWeights{
+		conv:       random(testFilters * testChannels * 9),
+		conv1:      random(testFilters * testFilters * 9),
+		conv2:      random(testFilters * testFilters * 9),
+		convBias:   random(testFilters),
+		conv1Bias:  random(testFilters),
+		conv2Bias:  random(testFilters),
+		actor:      random(testActions * testFilters * testCells),
+		critic:     random(testFilters * testCells),
+		actorBias:  random(testActions),
+		criticBias: random(1),
+	}
+}
+
+// writeTestModel encodes the network as export.py does, with the actor
+// weights in an external data file, and returns the model's path.
+func writeTestModel(t *testing.T, w testWeights) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.onnx.data"), floatBytes(w.actor), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var g []byte
+	conv := func(in, weight, bias, out string) {
+		g = message(g, graphNode, node("Conv", []string{in, weight, bias}, out,
+			ints("pads", 1, 1, 1, 1), ints("strides", 1, 1), ints("dilations", 1, 1), intAttr("group", 1)))
+	}
+	conv(InputName, "conv.weight", "conv.bias", "c0")
+	g = message(g, graphNode, node("Relu", []string{"c0"}, "r0"))
+	conv("r0", "conv1.weight", "conv1.bias", "c1")
+	g = message(g, graphNode, node("Relu", []string{"c1"}, "r1"))
+	conv("r1", "conv2.weight", "conv2.bias", "c2")
+	g = message(g, graphNode, node("Add", []string{"c2", "r0"}, "a2"))
+	g = message(g, graphNode, node("Relu", []string{"a2"}, "r2"))
+	g = message(g, graphNode, node("Shape", []string{InputName}, "batch", intAttr("start", 0), intAttr("end", 1)))
+	g = message(g, graphNode, node("Concat", []string{"batch", "rest"}, "shape", intAttr("axis", 0)))
+	g = message(g, graphNode, node("Reshape", []string{"r2", "shape"}, "flat", intAttr("allowzero", 1)))
+	g = message(g, graphNode, node("Gemm", []string{"flat", "actor.weight", "actor.bias"}, LogitsName, intAttr("transB", 1)))
+	g = message(g, graphNode, node("Gemm", []string{"flat", "critic.weight", "critic.bias"}, ValueName, intAttr("transB", 1)))
+
+	g = message(g, graphInitializer, floatTensor("conv.weight", w.conv, testFilters, testChannels, 3, 3))
+	g = message(g, graphInitializer, floatTensor("conv1.weight", w.conv1, testFilters, testFilters, 3, 3))
+	g = message(g, graphInitializer, floatTensor("conv2.weight", w.conv2, testFilters, testFilters, 3, 3))
+	g = message(g, graphInitializer, floatTensor("conv.bias", w.convBias, testFilters))
+	g = message(g, graphInitializer, floatTensor("conv1.bias", w.conv1Bias, testFilters))
+	g = message(g, graphInitializer, floatTensor("conv2.bias", w.conv2Bias, testFilters))
+	g = message(g, graphInitializer, externalTensor("actor.weight", "model.onnx.data", testActions, testFilters*testCells))
+	g = message(g, graphInitializer, floatTensor("actor.bias", w.actorBias, testActions))
+	g = message(g, graphInitializer, floatTensor("critic.weight", w.critic, 1, testFilters*testCells))
+	g = message(g, graphInitializer, floatTensor("critic.bias", w.criticBias, 1))
+	g = message(g, graphInitializer, int64Tensor("rest", -1))
+
+	g = message(g, graphInput, valueInfoMessage(InputName, -1, testChannels, testSize, testSize))
+	g = message(g, graphOutput, valueInfoMessage(LogitsName, -1, testActions))
+	g = message(g, graphOutput, valueInfoMessage(ValueName, -1, 1))
+
+	path := filepath.Join(dir, "model.onnx")
+	if err := os.WriteFile(path, message(nil, modelGraph, g), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func message(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func str(b []byte, num protowire.Number, s string) []byte {
+	return message(b, num, []byte(s))
+}
+
+func varint(b []byte, num protowire.Number, v int64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+func node(op string, inputs []string, output string, attrs ...[]byte) []byte {
+	var b []byte
+	for _, in := range inputs {
+		b = str(b, nodeInput, in)
+	}
+	b = str(b, nodeOutput, output)
+	b = str(b, nodeOpType, op)
+	for _, a := range attrs {
+		b = message(b, nodeAttribute, a)
+	}
+	return b
+}
+
+func intAttr(name string, v int64) []byte {
+	return varint(str(nil, attributeName, name), attributeI, v)
+}
+
+// ints encodes a packed ints attribute.
+func ints(name string, values ...int64) []byte {
+	var packed []byte
+	for _, v := range values {
+		packed = protowire.AppendVarint(packed, uint64(v))
+	}
+	return message(str(nil, attributeName, name), attributeInts, packed)
+}
+
+func floatBytes(values []float32) []byte {
+	var b []byte
+	for _, v := range values {
+		b = protowire.AppendFixed32(b, math.Float32bits(v))
+	}
+	return b
+}
+
+func tensorHeader(name string, dataType int64, dims ...int) []byte {
+	var b []byte
+	for _, dim := range dims {
+		b = varint(b, tensorDims, int64(dim))
+	}
+	b = varint(b, tensorDataType, dataType)
+	return str(b, tensorName, name)
+}
+
+// floatTensor stores the values as raw data, as the exporter does.
+func floatTensor(name string, values []float32, dims ...int) []byte {
+	return message(tensorHeader(name, dataTypeFloat, dims...), tensorRawData, floatBytes(values))
+}
+
+func int64Tensor(name string, values ...int64) []byte {
+	b := tensorHeader(name, dataTypeInt64, len(values))
+	for _, v := range values {
+		b = varint(b, tensorInt64Data, v)
+	}
+	return b
+}
+
+func externalTensor(name, location string, dims ...int) []byte {
+	b := tensorHeader(name, dataTypeFloat, dims...)
+	return message(b, tensorExternalData, str(str(nil, entryKey, "location"), entryValue, location))
+}
+
+func valueInfoMessage(name string, dims ...int) []byte {
+	var shape []byte
+	for _, dim := range dims {
+		var d []byte
+		if dim >= 0 {
+			d = varint(d, dimValue, int64(dim))
+		} else {
+			d = str(d, 2, "batch")
+		}
+		shape = message(shape, shapeDim, d)
+	}
+	tensorType := message(varint(nil, 1, dataTypeFloat), tensorShape, shape)
+	return message(str(nil, valueInfoName, name), valueInfoType, message(nil, typeTensor, tensorType))
+}
+
+// reference evaluates the test network on one state the slow, obvious way.
+func reference(w testWeights, state []float32) ([]float32, float32) {
+	convolve := func(in []float32, channels int, weight, bias []float32) []float32 {
+		out := make([]float32, testFilters*testCells)
+		for m := range testFilters {
+			for y := range testSize {
+				for x := range testSize {
+					sum := bias[m]
+					for c := range channels {
+						for ky := range 3 {
+							for kx := range 3 {
+								iy, ix := y+ky-1, x+kx-1
+								if iy >= 0 && iy < testSize && ix >= 0 && ix < testSize {
+									sum += weight[((m*channels+c)*3+ky)*3+kx] * in[(c*testSize+iy)*testSize+ix]
+								}
+							}
+						}
+					}
+					out[(m*testSize+y)*testSize+x] = sum
+				}
+			}
+		}
+		return out
+	}
+	relu := func(values []float32) []float32 {
+		for i, v := range values {
+			values[i] = max(v, 0)
+		}
+		return values
+	}
+
+	r0 := relu(convolve(state, testChannels, w.conv, w.convBias))
+	r1 := relu(convolve(r0, testFilters, w.conv1, w.conv1Bias))
+	r2 := convolve(r1, testFilters, w.conv2, w.conv2Bias)
+	for i := range r2 {
+		r2[i] += r0[i]
+	}
+	relu(r2)
+
+	logits := make([]float32, testActions)
+	for a := range testActions {
+		logits[a] = w.actorBias[a]
+		for i, v := range r2 {
+			logits[a] += w.actor[a*len(r2)+i] * v
+		}
+	}
+	value := w.criticBias[0]
+	for i, v := range r2 {
+		value += w.critic[i] * v
+	}
+	return logits, value
+}
+
+func expectClose(t *testing.T, what string, got, want float32) {
+	t.Helper()
+	if math.Abs(float64(got-want)) > 1e-4 {
+		t.Errorf("%s: got %v, want %v", what, got, want)
+	}
+}
+
+func TestRunMatchesReference(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	w := randomWeights(rng)
+
+	net, err := Load(writeTestModel(t, w))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if net.SampleSize() != testChannels*testCells {
+		t.Fatalf("expected states of %d floats, got %d", testChannels*testCells, net.SampleSize())
+	}
+
+	const n = 5
+	states := make([]float32, n*net.SampleSize())
+	for i := range states {
+		states[i] = float32(rng.IntN(2))
+	}
+
+	logits, values, err := net.Run(states, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logits) != n*testActions || len(values) != n {
+		t.Fatalf("expected %d logits and %d values, got %d and %d", n*testActions, n, len(logits), len(values))
+	}
+
+	for i := range n {
+		wantLogits, wantValue := reference(w, states[i*net.SampleSize():][:net.SampleSize()])
+		for a, want := range wantLogits {
+			expectClose(t, "logit", logits[i*testActions+a], want)
+		}
+		expectClose(t, "value", values[i], wantValue)
+	}
+}
+
+func TestLoadUnsupported(t *testing.T) {
+	var g []byte
+	g = message(g, graphNode, node("Softmax", []string{InputName}, LogitsName))
+	g = message(g, graphInput, valueInfoMessage(InputName, -1, 4))
+	g = message(g, graphOutput, valueInfoMessage(LogitsName, -1, 4))
+	g = message(g, graphOutput, valueInfoMessage(ValueName, -1, 1))
+
+	path := filepath.Join(t.TempDir(), "model.onnx")
+	if err := os.WriteFile(path, message(nil, modelGraph, g), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+// TestLoadBotModels checks that every exported model of the bots loads and
+// gives finite results of the bot's shapes.
+func TestLoadBotModels(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "..", "bot", "models", "*.onnx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skip("no exported models")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			net, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logits, values, err := net.Run(make([]float32, 2*net.SampleSize()), 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(logits) != 2*320 || len(values) != 2 {
+				t.Fatalf("expected 640 logits and 2 values, got %d and %d", len(logits), len(values))
+			}
+			for _, v := range append(logits, values...) {
+				if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+					t.Fatalf("expected finite results, got %v", v)
+				}
+			}
+		})
+	}
+}
+
+// golden holds the outputs ONNX Runtime gives for states on an exported
+// model, see TestNativeMatchesONNXRuntime in package bot.
+type golden struct {
+	States [][]float32 `json:"states"`
+	Logits [][]float32 `json:"logits"`
+	Values []float32   `json:"values"`
+}
+
+// TestBotModelsMatchGolden checks every exported model against the outputs
+// ONNX Runtime gave for it, kept in testdata/<model>.json.
+func TestBotModelsMatchGolden(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.onnx.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skip("no golden outputs, capture them with: go test -tags model_test -run TestNativeMatchesONNXRuntime ./internal/bot -update-golden")
+	}
+
+	for _, path := range paths {
+		model := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(model, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want golden
+			if err := json.Unmarshal(data, &want); err != nil {
+				t.Fatal(err)
+			}
+
+			net, err := Load(filepath.Join("..", "..", "..", "bot", "models", model))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			n := len(want.States)
+			logits, values, err := net.Run(slices.Concat(want.States...), n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actions := len(logits) / n
+
+			for i := range n {
+				if math.Abs(float64(values[i]-want.Values[i])) > 1e-3 {
+					t.Errorf("state %d: value %v, onnxruntime %v", i, values[i], want.Values[i])
+				}
+				for a, wantLogit := range want.Logits[i] {
+					if math.Abs(float64(logits[i*actions+a]-wantLogit)) > 1e-3 {
+						t.Errorf("state %d: logit %d is %v, onnxruntime %v", i, a, logits[i*actions+a], wantLogit)
+						break
+					}
+				}
+			}
+		})
+	}
+}
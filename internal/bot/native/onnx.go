@@ -0,0 +1,436 @@
+package native
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The subset of the ONNX protobuf schema the loader reads. Field numbers
+// are those of onnx.proto.
+const (
+	modelGraph = 7
+
+	graphNode        = 1
+	graphInitializer = 5
+	graphInput       = 11
+	graphOutput      = 12
+
+	nodeInput     = 1
+	nodeOutput    = 2
+	nodeOpType    = 4
+	nodeAttribute = 5
+
+	attributeName   = 1
+	attributeF      = 2
+	attributeI      = 3
+	attributeS      = 4
+	attributeT      = 5
+	attributeFloats = 7
+	attributeInts   = 8
+
+	tensorDims         = 1
+	tensorDataType     = 2
+	tensorFloatData    = 4
+	tensorInt64Data    = 7
+	tensorName         = 8
+	tensorRawData      = 9
+	tensorExternalData = 13
+
+	entryKey   = 1
+	entryValue = 2
+
+	valueInfoName = 1
+	valueInfoType = 2
+	typeTensor    = 1
+	tensorShape   = 2
+	shapeDim      = 1
+	dimValue      = 1
+
+	dataTypeFloat = 1
+	dataTypeInt64 = 7
+)
+
+// graph is a decoded ONNX graph: its nodes in order and its constants.
+type graph struct {
+	nodes        []onnxNode
+	initializers map[string]*tensor
+	inputs       []valueInfo
+	outputs      []valueInfo
+}
+
+// valueInfo is a graph input or output: its name and shape, with -1 for
+// dimensions of any size such as the batch.
+type valueInfo struct {
+	name string
+	dims []int
+}
+
+type onnxNode struct {
+	opType  string
+	inputs  []string
+	outputs []string
+	attrs   map[string]attribute
+}
+
+type attribute struct {
+	f      float32
+	i      int64
+	s      string
+	ints   []int64
+	floats []float32
+	t      *tensor
+}
+
+// tensor is a float tensor in row-major order. int64 tensors, which ONNX
+// uses for shapes, are converted to floats.
+type tensor struct {
+	dims []int
+	data []float32
+}
+
+// field is a decoded protobuf field: v holds the bytes of length-delimited
+// fields, n the value of varint and fixed-size ones.
+type field struct {
+	num protowire.Number
+	typ protowire.Type
+	v   []byte
+	n   uint64
+}
+
+// eachField calls fn with every field of the message b.
+func eachField(b []byte, fn func(field) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		f := field{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.n, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.n = uint64(v)
+		case protowire.Fixed64Type:
+			f.n, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.v, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// varints returns the values of a repeated integer field, which may come
+// packed or one value per field.
+func varints(f field, values []int64) ([]int64, error) {
+	if f.typ != protowire.BytesType {
+		return append(values, int64(f.n)), nil
+	}
+	for b := f.v; len(b) > 0; {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		values = append(values, int64(v))
+		b = b[n:]
+	}
+	return values, nil
+}
+
+// floats returns the values of a repeated float field.
+func floats(f field, values []float32) []float32 {
+	if f.typ != protowire.BytesType {
+		return append(values, math.Float32frombits(uint32(f.n)))
+	}
+	for b := f.v; len(b) >= 4; b = b[4:] {
+		values = append(values, math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	}
+	return values
+}
+
+// loadONNX reads an ONNX model, with its weights inline or in external data
+// files next to it.
+func loadONNX(path string) (*graph, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := decoder{dir: filepath.Dir(path)}
+	var g *graph
+	err = eachField(b, func(f field) error {
+		if f.num == modelGraph {
+			var err error
+			g, err = d.graph(f.v)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("native: read %s: %w", path, err)
+	}
+	if g == nil {
+		return nil, fmt.Errorf("native: read %s: no graph", path)
+	}
+
+	return g, nil
+}
+
+type decoder struct {
+	// dir is where external data files are looked up.
+	dir string
+}
+
+func (d decoder) graph(b []byte) (*graph, error) {
+	g := &graph{initializers: make(map[string]*tensor)}
+	err := eachField(b, func(f field) error {
+		switch f.num {
+		case graphNode:
+			n, err := d.node(f.v)
+			if err != nil {
+				return err
+			}
+			g.nodes = append(g.nodes, n)
+		case graphInitializer:
+			name, t, err := d.tensor(f.v)
+			if err != nil {
+				return err
+			}
+			g.initializers[name] = t
+		case graphInput, graphOutput:
+			info, err := decodeValueInfo(f.v)
+			if err != nil {
+				return err
+			}
+			if f.num == graphInput {
+				g.inputs = append(g.inputs, info)
+			} else {
+				g.outputs = append(g.outputs, info)
+			}
+		}
+		return nil
+	})
+	return g, err
+}
+
+func (d decoder) node(b []byte) (onnxNode, error) {
+	n := onnxNode{attrs: make(map[string]attribute)}
+	err := eachField(b, func(f field) error {
+		switch f.num {
+		case nodeInput:
+			n.inputs = append(n.inputs, string(f.v))
+		case nodeOutput:
+			n.outputs = append(n.outputs, string(f.v))
+		case nodeOpType:
+			n.opType = string(f.v)
+		case nodeAttribute:
+			name, a, err := d.attribute(f.v)
+			if err != nil {
+				return err
+			}
+			n.attrs[name] = a
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (d decoder) attribute(b []byte) (string, attribute, error) {
+	var (
+		name string
+		a    attribute
+	)
+	err := eachField(b, func(f field) error {
+		var err error
+		switch f.num {
+		case attributeName:
+			name = string(f.v)
+		case attributeF:
+			a.f = math.Float32frombits(uint32(f.n))
+		case attributeI:
+			a.i = int64(f.n)
+		case attributeS:
+			a.s = string(f.v)
+		case attributeT:
+			_, a.t, err = d.tensor(f.v)
+		case attributeFloats:
+			a.floats = floats(f, a.floats)
+		case attributeInts:
+			a.ints, err = varints(f, a.ints)
+		}
+		return err
+	})
+	return name, a, err
+}
+
+func (d decoder) tensor(b []byte) (string, *tensor, error) {
+	var (
+		name     string
+		dims     []int64
+		dataType uint64
+		raw      []byte
+		values   []float32
+		ints     []int64
+		external = make(map[string]string)
+	)
+	err := eachField(b, func(f field) error {
+		var err error
+		switch f.num {
+		case tensorDims:
+			dims, err = varints(f, dims)
+		case tensorDataType:
+			dataType = f.n
+		case tensorFloatData:
+			values = floats(f, values)
+		case tensorInt64Data:
+			ints, err = varints(f, ints)
+		case tensorName:
+			name = string(f.v)
+		case tensorRawData:
+			raw = f.v
+		case tensorExternalData:
+			var key, value string
+			err = eachField(f.v, func(f field) error {
+				switch f.num {
+				case entryKey:
+					key = string(f.v)
+				case entryValue:
+					value = string(f.v)
+				}
+				return nil
+			})
+			external[key] = value
+		}
+		return err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	if location, ok := external["location"]; ok {
+		raw, err = d.externalData(location, external["offset"], external["length"])
+		if err != nil {
+			return "", nil, fmt.Errorf("tensor %s: %w", name, err)
+		}
+	}
+
+	t := &tensor{dims: make([]int, len(dims))}
+	for i, dim := range dims {
+		t.dims[i] = int(dim)
+	}
+
+	switch dataType {
+	case dataTypeFloat:
+		if raw != nil {
+			values = floats(field{typ: protowire.BytesType, v: raw}, nil)
+		}
+		t.data = values
+	case dataTypeInt64:
+		for ; len(raw) >= 8; raw = raw[8:] {
+			ints = append(ints, int64(binary.LittleEndian.Uint64(raw)))
+		}
+		t.data = make([]float32, len(ints))
+		for i, v := range ints {
+			t.data[i] = float32(v)
+		}
+	default:
+		return "", nil, fmt.Errorf("tensor %s: unsupported data type %d", name, dataType)
+	}
+
+	if len(t.data) != t.size() {
+		return "", nil, fmt.Errorf("tensor %s: %d values for shape %v", name, len(t.data), t.dims)
+	}
+	return name, t, nil
+}
+
+// externalData reads the bytes of a tensor stored outside the model file.
+func (d decoder) externalData(location, offset, length string) ([]byte, error) {
+	b, err := os.ReadFile(filepath.Join(d.dir, filepath.Clean(location)))
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := 0, len(b)
+	if offset != "" {
+		if start, err = strconv.Atoi(offset); err != nil {
+			return nil, fmt.Errorf("bad offset %q", offset)
+		}
+	}
+	if length != "" {
+		n, err := strconv.Atoi(length)
+		if err != nil {
+			return nil, fmt.Errorf("bad length %q", length)
+		}
+		end = start + n
+	}
+	if start < 0 || start > end || end > len(b) {
+		return nil, fmt.Errorf("data %d..%d is outside %s", start, end, location)
+	}
+
+	return b[start:end], nil
+}
+
+func decodeValueInfo(b []byte) (valueInfo, error) {
+	var info valueInfo
+	err := eachField(b, func(f field) error {
+		switch f.num {
+		case valueInfoName:
+			info.name = string(f.v)
+		case valueInfoType:
+			return nested(f.v, []protowire.Number{typeTensor, tensorShape, shapeDim}, func(dim []byte) error {
+				size := -1
+				err := eachField(dim, func(f field) error {
+					if f.num == dimValue {
+						size = int(f.n)
+					}
+					return nil
+				})
+				info.dims = append(info.dims, size)
+				return err
+			})
+		}
+		return nil
+	})
+	return info, err
+}
+
+// nested calls fn with every message found by following the path of
+// message fields down from b.
+func nested(b []byte, path []protowire.Number, fn func([]byte) error) error {
+	return eachField(b, func(f field) error {
+		if f.num != path[0] || f.typ != protowire.BytesType {
+			return nil
+		}
+		if len(path) == 1 {
+			return fn(f.v)
+		}
+		return nested(f.v, path[1:], fn)
+	})
+}
+
+func (t *tensor) size() int {
+	size := 1
+	for _, dim := range t.dims {
+		size *= dim
+	}
+	return size
+}
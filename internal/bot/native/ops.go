@@ -0,0 +1,381 @@
+package native
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// operator computes the output of a node from its inputs. Inputs are never
+// modified: they may be weights shared by concurrent runs.
+type operator func(n onnxNode, inputs []*tensor) (*tensor, error)
+
+// operators are the supported ONNX operators.
+var operators = map[string]operator{
+	"Add":                add,
+	"BatchNormalization": batchNormalization,
+	"Concat":             concat,
+	"Conv":               conv,
+	"Flatten":            flatten,
+	"Gemm":               gemm,
+	"Relu":               relu,
+	"Reshape":            reshape,
+	"Shape":              shape,
+}
+
+func (n onnxNode) int(name string, def int64) int64 {
+	if a, ok := n.attrs[name]; ok {
+		return a.i
+	}
+	return def
+}
+
+func (n onnxNode) float(name string, def float32) float32 {
+	if a, ok := n.attrs[name]; ok {
+		return a.f
+	}
+	return def
+}
+
+func (n onnxNode) ints(name string, def ...int64) []int64 {
+	if a, ok := n.attrs[name]; ok {
+		return a.ints
+	}
+	return def
+}
+
+func arity(inputs []*tensor, required int) error {
+	if len(inputs) < required {
+		return fmt.Errorf("%d inputs, want %d", len(inputs), required)
+	}
+	for i := range required {
+		if inputs[i] == nil {
+			return fmt.Errorf("input %d is missing", i)
+		}
+	}
+	return nil
+}
+
+func relu(_ onnxNode, inputs []*tensor) (*tensor, error) {
+	if err := arity(inputs, 1); err != nil {
+		return nil, err
+	}
+
+	x := inputs[0]
+	out := &tensor{dims: x.dims, data: make([]float32, len(x.data))}
+	for i, v := range x.data {
+		out.data[i] = max(v, 0)
+	}
+	return out, nil
+}
+
+// add adds tensors of the same shape, the only case the residual blocks need.
+func add(_ onnxNode, inputs []*tensor) (*tensor, error) {
+	if err := arity(inputs, 2); err != nil {
+		return nil, err
+	}
+
+	a, b := inputs[0], inputs[1]
+	if !slices.Equal(a.dims, b.dims) {
+		return nil, fmt.Errorf("%w: broadcasting %v to %v", ErrUnsupported, b.dims, a.dims)
+	}
+
+	out := &tensor{dims: a.dims, data: make([]float32, len(a.data))}
+	for i := range a.data {
+		out.data[i] = a.data[i] + b.data[i]
+	}
+	return out, nil
+}
+
+// conv is a 2D convolution with zero padding, NCHW input and MCkk weights.
+// Groups and dilations are not supported.
+func conv(n onnxNode, inputs []*tensor) (*tensor, error) {
+	if err := arity(inputs, 2); err != nil {
+		return nil, err
+	}
+	if n.int("group", 1) != 1 || slices.ContainsFunc(n.ints("dilations"), func(d int64) bool { return d != 1 }) {
+		return nil, fmt.Errorf("%w: grouped or dilated convolution", ErrUnsupported)
+	}
+	if pad := n.attrs["auto_pad"].s; pad != "" && pad != "NOTSET" {
+		return nil, fmt.Errorf("%w: auto_pad %s", ErrUnsupported, pad)
+	}
+
+	x, w := inputs[0], inputs[1]
+	if len(x.dims) != 4 || len(w.dims) != 4 || w.dims[1] != x.dims[1] {
+		return nil, fmt.Errorf("input %v and weights %v do not match", x.dims, w.dims)
+	}
+	batch, channels, height, width := x.dims[0], x.dims[1], x.dims[2], x.dims[3]
+	filters, kh, kw := w.dims[0], w.dims[2], w.dims[3]
+
+	pads := n.ints("pads", 0, 0, 0, 0)
+	strides := n.ints("strides", 1, 1)
+	if len(pads) != 4 || len(strides) != 2 {
+		return nil, fmt.Errorf("%w: pads %v, strides %v", ErrUnsupported, pads, strides)
+	}
+	top, left := int(pads[0]), int(pads[1])
+	sy, sx := int(strides[0]), int(strides[1])
+	outH := (height+top+int(pads[2])-kh)/sy + 1
+	outW := (width+left+int(pads[3])-kw)/sx + 1
+
+	var bias []float32
+	if len(inputs) > 2 && inputs[2] != nil {
+		bias = inputs[2].data
+	}
+
+	// each sample is unrolled into one column per output cell holding the
+	// input cells under the kernel (im2col), so that the convolution is a
+	// matrix product of the weights and the columns
+	patch, cells := channels*kh*kw, outH*outW
+	columns := make([]float32, patch*cells)
+
+	out := &tensor{dims: []int{batch, filters, outH, outW}, data: make([]float32, batch*filters*cells)}
+	for b := range batch {
+		clear(columns)
+		for c := range channels {
+			in := x.data[(b*channels+c)*height*width:][:height*width]
+			for ky := range kh {
+				for kx := range kw {
+					row := columns[((c*kh+ky)*kw+kx)*cells:][:cells]
+					for oy := range outH {
+						iy := oy*sy + ky - top
+						if iy < 0 || iy >= height {
+							continue
+						}
+						for ox := range outW {
+							if ix := ox*sx + kx - left; ix >= 0 && ix < width {
+								row[oy*outW+ox] = in[iy*width+ix]
+							}
+						}
+					}
+				}
+			}
+		}
+
+		for m := range filters {
+			plane := out.data[(b*filters+m)*cells:][:cells]
+			if bias != nil {
+				for i := range plane {
+					plane[i] = bias[m]
+				}
+			}
+			for q, weight := range w.data[m*patch:][:patch] {
+				row := columns[q*cells:][:cells]
+				for i, v := range row {
+					plane[i] += weight * v
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// gemm computes alpha*A*B + beta*C for 2D A and B, with C broadcast over
+// the rows.
+func gemm(n onnxNode, inputs []*tensor) (*tensor, error) {
+	if err := arity(inputs, 2); err != nil {
+		return nil, err
+	}
+	if n.int("transA", 0) != 0 {
+		return nil, fmt.Errorf("%w: transA", ErrUnsupported)
+	}
+
+	a, b := inputs[0], inputs[1]
+	if len(a.dims) != 2 || len(b.dims) != 2 {
+		return nil, fmt.Errorf("inputs %v and %v are not matrices", a.dims, b.dims)
+	}
+	rows, inner := a.dims[0], a.dims[1]
+
+	// element (k, j) of B is at b.data[k*kStride + j*jStride]
+	transB := n.int("transB", 0) != 0
+	cols, kStride, jStride := b.dims[1], b.dims[1], 1
+	if transB {
+		cols, kStride, jStride = b.dims[0], 1, b.dims[1]
+	}
+	if (transB && b.dims[1] != inner) || (!transB && b.dims[0] != inner) {
+		return nil, fmt.Errorf("inputs %v and %v do not match", a.dims, b.dims)
+	}
+
+	alpha, beta := n.float("alpha", 1), n.float("beta", 1)
+	var c *tensor
+	if len(inputs) > 2 {
+		c = inputs[2]
+	}
+
+	out := &tensor{dims: []int{rows, cols}, data: make([]float32, rows*cols)}
+	for i := range rows {
+		row := a.data[i*inner:][:inner]
+		for j := range cols {
+			sum := float32(0)
+			for k, v := range row {
+				sum += v * b.data[k*kStride+j*jStride]
+			}
+			out.data[i*cols+j] = alpha*sum + beta*broadcastAt(c, i, j, cols)
+		}
+	}
+	return out, nil
+}
+
+// broadcastAt returns element (i, j) of the rows×cols broadcast of c,
+// which is a scalar, a row or a full matrix.
+func broadcastAt(c *tensor, i, j, cols int) float32 {
+	switch {
+	case c == nil:
+		return 0
+	case len(c.data) == 1:
+		return c.data[0]
+	case len(c.data) == cols:
+		return c.data[j]
+	default:
+		return c.data[i*cols+j]
+	}
+}
+
+// batchNormalization normalizes the channels of an NCHW tensor with the
+// running statistics, as in inference.
+func batchNormalization(n onnxNode, inputs []*tensor) (*tensor, error) {
+	if err := arity(inputs, 5); err != nil {
+		return nil, err
+	}
+
+	x, scale, bias, mean, variance := inputs[0], inputs[1], inputs[2], inputs[3], inputs[4]
+	if len(x.dims) < 2 {
+		return nil, fmt.Errorf("input %v has no channels", x.dims)
+	}
+	epsilon := n.float("epsilon", 1e-5)
+
+	channels := x.dims[1]
+	size := len(x.data) / (x.dims[0] * channels)
+	out := &tensor{dims: x.dims, data: make([]float32, len(x.data))}
+	for i, v := range x.data {
+		c := i / size % channels
+		std := float32(math.Sqrt(float64(variance.data[c] + epsilon)))
+		out.data[i] = scale.data[c]*(v-mean.data[c])/std + bias.data[c]
+	}
+	return out, nil
+}
+
+// shape returns the dimensions of the input from start to end.
+func shape(n onnxNode, inputs []*tensor) (*tensor, error) {
+	if err := arity(inputs, 1); err != nil {
+		return nil, err
+	}
+
+	dims := inputs[0].dims
+	start := clampAxis(n.int("start", 0), len(dims))
+	end := clampAxis(n.int("end", int64(len(dims))), len(dims))
+	if end < start {
+		end = start
+	}
+
+	out := &tensor{dims: []int{end - start}}
+	for _, dim := range dims[start:end] {
+		out.data = append(out.data, float32(dim))
+	}
+	return out, nil
+}
+
+func clampAxis(axis int64, rank int) int {
+	if axis < 0 {
+		axis += int64(rank)
+	}
+	return int(min(max(axis, 0), int64(rank)))
+}
+
+// concat joins the inputs along an axis.
+func concat(n onnxNode, inputs []*tensor) (*tensor, error) {
+	if err := arity(inputs, 1); err != nil {
+		return nil, err
+	}
+
+	first := inputs[0]
+	axis := int(n.int("axis", 0))
+	if axis < 0 {
+		axis += len(first.dims)
+	}
+	if axis < 0 || axis >= len(first.dims) {
+		return nil, fmt.Errorf("axis %d out of range", axis)
+	}
+
+	outer := 1
+	for _, dim := range first.dims[:axis] {
+		outer *= dim
+	}
+
+	dims := slices.Clone(first.dims)
+	dims[axis] = 0
+	for _, t := range inputs {
+		if len(t.dims) != len(dims) {
+			return nil, fmt.Errorf("inputs of ranks %d and %d", len(dims), len(t.dims))
+		}
+		dims[axis] += t.dims[axis]
+	}
+
+	out := &tensor{dims: dims}
+	for o := range outer {
+		for _, t := range inputs {
+			chunk := len(t.data) / outer
+			out.data = append(out.data, t.data[o*chunk:(o+1)*chunk]...)
+		}
+	}
+	return out, nil
+}
+
+// reshape gives the input the shape of the second input. A 0 keeps the
+// input's dimension unless allowzero is set, and a -1 takes what is left.
+func reshape(n onnxNode, inputs []*tensor) (*tensor, error) {
+	if err := arity(inputs, 2); err != nil {
+		return nil, err
+	}
+
+	x := inputs[0]
+	allowZero := n.int("allowzero", 0) != 0
+
+	dims := make([]int, len(inputs[1].data))
+	inferred, known := -1, 1
+	for i, v := range inputs[1].data {
+		switch dim := int(v); {
+		case dim == -1:
+			if inferred >= 0 {
+				return nil, fmt.Errorf("shape %v has two -1", inputs[1].data)
+			}
+			inferred = i
+		case dim == 0 && !allowZero:
+			if i >= len(x.dims) {
+				return nil, fmt.Errorf("shape %v copies a missing dimension", inputs[1].data)
+			}
+			dims[i] = x.dims[i]
+			known *= dims[i]
+		default:
+			dims[i] = dim
+			known *= dim
+		}
+	}
+	if inferred >= 0 {
+		if known == 0 {
+			return nil, fmt.Errorf("shape %v cannot infer -1", inputs[1].data)
+		}
+		dims[inferred] = len(x.data) / known
+	}
+
+	out := &tensor{dims: dims, data: x.data}
+	if out.size() != len(x.data) {
+		return nil, fmt.Errorf("cannot reshape %v to %v", x.dims, dims)
+	}
+	return out, nil
+}
+
+// flatten reshapes the input to a matrix, joining the dimensions before and
+// from the axis.
+func flatten(n onnxNode, inputs []*tensor) (*tensor, error) {
+	if err := arity(inputs, 1); err != nil {
+		return nil, err
+	}
+
+	x := inputs[0]
+	axis := clampAxis(n.int("axis", 1), len(x.dims))
+	rows := 1
+	for _, dim := range x.dims[:axis] {
+		rows *= dim
+	}
+	return &tensor{dims: []int{rows, len(x.data) / max(rows, 1)}, data: x.data}, nil
+}
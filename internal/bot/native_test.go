@@ -0,0 +1,31 @@
+package bot
+
+import (
+	"path/filepath"
+	"testing"
+	"tic-tac-chec/engine"
+)
+
+// TestNativeModelPlays plays the trained network without ONNX Runtime.
+func TestNativeModelPlays(t *testing.T) {
+	batcher, err := NewNativeBatcher(filepath.Join("..", "..", "bot", "models", "bot.onnx"), BatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer batcher.Close()
+
+	for _, opts := range []SearchOptions{{}, {Simulations: 20, Workers: 2}} {
+		m := NewWithEvaluator(batcher, opts)
+
+		g := engine.NewGame()
+		for i := 0; i < 6 && g.Status != engine.GameOver; i++ {
+			piece, cell, err := m.SelectAction(g)
+			if err != nil {
+				t.Fatalf("%+v, move %d: %v", opts, i, err)
+			}
+			if err := g.Move(piece, cell); err != nil {
+				t.Fatalf("%+v, move %d: illegal move %v to %v: %v", opts, i, piece, cell, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Package player runs bots as game.Player values. It knows nothing about
+// how a bot picks its moves, so bots without ONNX Runtime can use it too.
+package player
+
+import (
+	"math/rand"
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/game"
+	"time"
+
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+)
+
+var logger = otelslog.NewLogger("tic-tac-chec/internal/bot/player")
+
+// Mover picks the move to play in a game; the game is the mover's own copy.
+type Mover interface {
+	SelectAction(g *engine.Game) (engine.Piece, engine.Cell, error)
+}
+
+// Resetter is a Mover that keeps state between moves of a game, such as a
+// search tree. Reset is called whenever a game ends or the bot is paired.
+type Resetter interface {
+	Reset()
+}
+
+// Run creates a game.Player backed by the mover and starts a goroutine
+// that listens for game events and responds with moves. After a game the
+// bot reacts with an emoji and asks for a rematch.
+func Run(mover Mover, playerID string) game.Player {
+	commands := make(chan game.Command, 2)
+	player := game.NewPlayerWithID(commands, playerID)
+
+	go playLoop(mover, &player, commands)
+
+	return player
+}
+
+// playLoop is the bot's event loop goroutine. It listens for game events
+// and responds with moves when it's the bot's turn.
+func playLoop(mover Mover, player *game.Player, commands chan game.Command) {
+	botColor := engine.White
+	botPlayerID := player.ID
+
+	reset := func() {
+		if r, ok := mover.(Resetter); ok {
+			r.Reset()
+		}
+	}
+
+	for event := range player.Updates {
+		switch e := event.(type) {
+		case game.PairedEvent:
+			botColor = e.Color
+			reset()
+		case game.SnapshotEvent:
+			if e.Game.Status() == engine.GameOver {
+				reset()
+				time.Sleep(500 * time.Millisecond)
+				emoji := game.ReactionEmojis[rand.Intn(len(game.ReactionEmojis))]
+				commands <- game.ReactionCommand{PlayerID: botPlayerID, Reaction: emoji}
+				commands <- game.RematchCommand{PlayerID: botPlayerID}
+				continue
+			}
+
+			if e.Game.Turn() == botColor {
+				piece, cell, err := mover.SelectAction(e.Game.Game())
+				if err != nil {
+					logger.Error("bot.select_action_failed", "err", err)
+					continue
+				}
+				commands <- game.MoveCommand{Piece: piece, To: cell}
+			}
+		case game.RematchRequestedEvent:
+			commands <- game.RematchCommand{PlayerID: botPlayerID}
+		default:
+			// ignore
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package player
+
+import (
+	"testing"
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/game"
+	"time"
+)
+
+// resetMover plays the first legal move and counts resets.
+type resetMover struct {
+	resets chan struct{}
+}
+
+func (m *resetMover) SelectAction(g *engine.Game) (engine.Piece, engine.Cell, error) {
+	move := g.AllLegalMoves()[0]
+	return move.Piece, move.To, nil
+}
+
+func (m *resetMover) Reset() {
+	m.resets <- struct{}{}
+}
+
+func TestRunResetsBetweenGames(t *testing.T) {
+	mover := &resetMover{resets: make(chan struct{}, 2)}
+	bot := Run(mover, "bot")
+	defer close(bot.Updates)
+
+	bot.Updates <- game.PairedEvent{PlayerID: bot.ID, Color: engine.Black}
+	expectReset(t, mover)
+
+	g, err := engine.ParseFEN("4/4/4/PRBN prbn b ud 0 4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot.Updates <- game.SnapshotEvent{Game: g.Snapshot()}
+	expectReset(t, mover)
+
+	if _, ok := (<-bot.Commands).(game.ReactionCommand); !ok {
+		t.Error("expected a reaction after the game")
+	}
+	if _, ok := (<-bot.Commands).(game.RematchCommand); !ok {
+		t.Error("expected a rematch request after the game")
+	}
+}
+
+func expectReset(t *testing.T, mover *resetMover) {
+	t.Helper()
+
+	select {
+	case <-mover.resets:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the mover to be reset")
+	}
+}
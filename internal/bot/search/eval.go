@@ -0,0 +1,68 @@
+package search
+
+import "tic-tac-chec/engine"
+
+// lineWeights scores a line only one side has pieces on, by the number of
+// cells still missing from it. A line one piece short is a threat.
+var lineWeights = [engine.MaxBoardSize + 1]int{0, 60, 12, 3, 1, 0}
+
+const (
+	// blockedThreat scores a line one piece short whose missing cell holds
+	// an opponent piece: it wins once that piece is captured.
+	blockedThreat = 20
+	// pieceValue scores every piece still in play. It only differs between
+	// the sides when captures are discarded.
+	pieceValue = 40
+	// handBonus scores a piece in hand: it can be dropped on any empty cell.
+	handBonus = 4
+)
+
+// Evaluate scores a position from the side to move's point of view:
+// positive if it is better for the side to move. It counts the lines each
+// side is building, the threats among them, and the pieces in play and in
+// hand. Won positions are scored by the search, not here.
+func Evaluate(p *engine.Position) int {
+	return evaluateSide(p, p.Turn) - evaluateSide(p, 1-p.Turn)
+}
+
+func evaluateSide(p *engine.Position, color engine.Color) int {
+	own := p.Occupied[color]
+	opponent := p.Occupied[1-color]
+
+	score := 0
+	for _, mask := range engine.WinMasks(p.Rules) {
+		missing := mask &^ own
+		if mask&opponent == 0 {
+			score += lineWeights[missing.Count()]
+		} else if missing.Count() == 1 {
+			score += blockedThreat
+		}
+	}
+
+	for kind := range engine.PieceKindCount {
+		switch p.Squares[color][kind] {
+		case engine.Discarded:
+		case engine.InHand:
+			score += pieceValue + handBonus
+		default:
+			score += pieceValue
+		}
+	}
+
+	return score
+}
+
+// gaps returns the cells that complete a line of color: for every line
+// color holds all but one cell of, the missing cell.
+func gaps(p *engine.Position, color engine.Color) engine.Bitboard {
+	own := p.Occupied[color]
+
+	var cells engine.Bitboard
+	for _, mask := range engine.WinMasks(p.Rules) {
+		if missing := mask &^ own; missing.Count() == 1 {
+			cells |= missing
+		}
+	}
+
+	return cells
+}
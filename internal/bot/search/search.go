@@ -0,0 +1,276 @@
+// Package search is a pure-Go alpha-beta searcher: iterative deepening,
+// a transposition table, move ordering and a handcrafted evaluation.
+// It needs no model and no ONNX Runtime, and it plays every engine.Rules
+// variant.
+package search
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/bot/player"
+	"tic-tac-chec/internal/game"
+)
+
+// ErrNoMoves is returned when the side to move has no legal move.
+var ErrNoMoves = errors.New("search: no legal moves")
+
+const (
+	// WinScore is the score of winning now. Wins further away score less,
+	// by one per ply, so that the search prefers the quickest win.
+	WinScore = 1_000_000
+	maxPly   = 64
+	// winThreshold separates won and lost scores from evaluations.
+	winThreshold = WinScore - maxPly
+	infinity     = WinScore + 1
+)
+
+// Searcher picks moves by searching the game tree to a fixed depth.
+// It keeps no state between searches and is safe for concurrent use.
+type Searcher struct {
+	depth int
+}
+
+// New returns a searcher that looks depth plies ahead. Depths below 1
+// are raised to 1.
+func New(depth int) *Searcher {
+	return &Searcher{depth: max(depth, 1)}
+}
+
+// Result is the outcome of a search.
+type Result struct {
+	Move engine.PositionMove
+	// Score is from the side to move's point of view, see Evaluate.
+	// Scores beyond ±(WinScore-64) are forced wins or losses.
+	Score int
+	// Depth is the depth of the last completed iteration.
+	Depth int
+	Nodes uint64
+}
+
+// Won reports whether the score is a forced win for the side to move.
+func (r Result) Won() bool {
+	return r.Score > winThreshold
+}
+
+// Lost reports whether the score is a forced loss for the side to move.
+func (r Result) Lost() bool {
+	return r.Score < -winThreshold
+}
+
+// Search finds the best move of the side to move, deepening one ply at
+// a time up to the searcher's depth. It stops early once a forced win or
+// loss is found. It fails with engine.ErrGameOver if a side already has
+// a winning line.
+func (s *Searcher) Search(p engine.Position) (Result, error) {
+	if _, won := p.Winner(); won {
+		return Result{}, engine.ErrGameOver
+	}
+
+	var buf [engine.MaxMoves]engine.PositionMove
+	if len(p.GenerateMoves(buf[:0])) == 0 {
+		return Result{}, ErrNoMoves
+	}
+
+	t := newTree()
+	var result Result
+	for depth := 1; depth <= s.depth; depth++ {
+		score := t.negamax(&p, depth, 0, -infinity, infinity)
+		result = Result{Move: t.table[p.Key()].move, Score: score, Depth: depth, Nodes: t.nodes}
+		if result.Won() || result.Lost() {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// SelectAction returns the move the search picks in the game.
+func (s *Searcher) SelectAction(g *engine.Game) (engine.Piece, engine.Cell, error) {
+	if g.Status == engine.GameOver {
+		return engine.Piece{}, engine.Cell{}, engine.ErrGameOver
+	}
+
+	result, err := s.Search(g.Position())
+	if err != nil {
+		return engine.Piece{}, engine.Cell{}, err
+	}
+
+	return engine.Piece{Color: g.Turn, Kind: result.Move.Kind}, result.Move.To.Cell(), nil
+}
+
+// RunPlayer creates a game.Player backed by the searcher, see player.Run.
+func (s *Searcher) RunPlayer(playerID string) game.Player {
+	return player.Run(s, playerID)
+}
+
+type bound uint8
+
+const (
+	exact bound = iota
+	lower       // the score is at least the stored one
+	upper       // the score is at most the stored one
+)
+
+type entry struct {
+	move  engine.PositionMove
+	score int
+	depth int
+	bound bound
+}
+
+type scoredMove struct {
+	move  engine.PositionMove
+	score int
+}
+
+// tree holds the state of one search.
+type tree struct {
+	nodes   uint64
+	table   map[engine.Key]entry
+	killers [maxPly][2]engine.PositionMove
+	history [engine.ColorCount][engine.PieceKindCount][engine.SquareCount]int
+	moves   [maxPly][engine.MaxMoves]engine.PositionMove
+	ordered [maxPly][engine.MaxMoves]scoredMove
+}
+
+func newTree() *tree {
+	return &tree{table: make(map[engine.Key]entry)}
+}
+
+// negamax returns the score of the position for the side to move,
+// searching depth plies further. ply is the distance from the root.
+func (t *tree) negamax(p *engine.Position, depth, ply, alpha, beta int) int {
+	t.nodes++
+
+	// only the side that just moved can have completed a line
+	if p.HasFourInARow(1 - p.Turn) {
+		return -WinScore + ply
+	}
+	if depth == 0 || ply == maxPly-1 {
+		return Evaluate(p)
+	}
+
+	key := p.Key()
+	var hashMove engine.PositionMove
+	e, found := t.table[key]
+	if found {
+		hashMove = e.move
+		if e.depth >= depth {
+			score := fromTable(e.score, ply)
+			switch {
+			case e.bound == exact:
+				return score
+			case e.bound == lower && score >= beta:
+				return score
+			case e.bound == upper && score <= alpha:
+				return score
+			}
+		}
+	}
+
+	moves := t.order(p, p.GenerateMoves(t.moves[ply][:0]), hashMove, found, ply)
+	if len(moves) == 0 {
+		return 0
+	}
+
+	originalAlpha := alpha
+	best := -infinity
+	var bestMove engine.PositionMove
+	for _, m := range moves {
+		u := p.MakeMove(m.move)
+		score := -t.negamax(p, depth-1, ply+1, -beta, -alpha)
+		p.UnmakeMove(m.move, u)
+
+		if score > best {
+			best = score
+			bestMove = m.move
+		}
+		if score > alpha {
+			alpha = score
+		}
+		if alpha >= beta {
+			if !u.Capture {
+				t.rememberCutoff(p.Turn, m.move, depth, ply)
+			}
+			break
+		}
+	}
+
+	e = entry{move: bestMove, score: toTable(best, ply), depth: depth, bound: exact}
+	switch {
+	case best <= originalAlpha:
+		e.bound = upper
+	case best >= beta:
+		e.bound = lower
+	}
+	t.table[key] = e
+
+	return best
+}
+
+// order sorts the moves best first: the move stored in the table, moves
+// onto cells that complete a line of the mover, captures, moves onto cells
+// that block a line of the opponent, killer moves, then by history.
+func (t *tree) order(p *engine.Position, moves []engine.PositionMove, hashMove engine.PositionMove, hasHash bool, ply int) []scoredMove {
+	wins := gaps(p, p.Turn)
+	blocks := gaps(p, 1-p.Turn)
+	opponent := p.Occupied[1-p.Turn]
+
+	ordered := t.ordered[ply][:len(moves)]
+	for i, m := range moves {
+		score := t.history[p.Turn][m.Kind][m.To]
+		switch {
+		case hasHash && m == hashMove:
+			score = 1 << 30
+		case wins.Has(m.To):
+			score = 1 << 29
+		case opponent.Has(m.To):
+			score = 1<<28 + int(m.Kind)
+		case blocks.Has(m.To):
+			score = 1 << 27
+		case m == t.killers[ply][0] || m == t.killers[ply][1]:
+			score = 1 << 26
+		}
+		ordered[i] = scoredMove{move: m, score: score}
+	}
+
+	slices.SortStableFunc(ordered, func(a, b scoredMove) int {
+		return cmp.Compare(b.score, a.score)
+	})
+	return ordered
+}
+
+// rememberCutoff records a quiet move that caused a beta cutoff,
+// so that it is tried early in sibling positions.
+func (t *tree) rememberCutoff(color engine.Color, m engine.PositionMove, depth, ply int) {
+	if t.killers[ply][0] != m {
+		t.killers[ply][1] = t.killers[ply][0]
+		t.killers[ply][0] = m
+	}
+	t.history[color][m.Kind][m.To] += depth * depth
+}
+
+// toTable and fromTable convert won and lost scores between distance from
+// the root, used in the search, and distance from the position, stored in
+// the table.
+func toTable(score, ply int) int {
+	switch {
+	case score > winThreshold:
+		return score + ply
+	case score < -winThreshold:
+		return score - ply
+	}
+	return score
+}
+
+func fromTable(score, ply int) int {
+	switch {
+	case score > winThreshold:
+		return score - ply
+	case score < -winThreshold:
+		return score + ply
+	}
+	return score
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"errors"
+	"testing"
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/game"
+	"time"
+)
+
+func gameFromFEN(t *testing.T, fen string) *engine.Game {
+	t.Helper()
+
+	g, err := engine.ParseFEN(fen)
+	if err != nil {
+		t.Fatalf("ParseFEN(%q): %v", fen, err)
+	}
+	return g
+}
+
+func TestSearchTakesImmediateWin(t *testing.T) {
+	g := gameFromFEN(t, "4/4/4/PRB1 Nprbn w ud 0 4")
+
+	piece, cell, err := New(3).SelectAction(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if piece != engine.WhiteKnight || cell != (engine.Cell{Row: 3, Col: 3}) {
+		t.Errorf("expected N@d1, got %v to %v", piece, cell)
+	}
+}
+
+func TestSearchBlocksThreat(t *testing.T) {
+	g := gameFromFEN(t, "4/4/4/PRB1 Nprbn b ud 0 4")
+
+	_, cell, err := New(2).SelectAction(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cell != (engine.Cell{Row: 3, Col: 3}) {
+		t.Errorf("expected a block on d1, got %v", cell)
+	}
+}
+
+func TestSearchFindsForcedWin(t *testing.T) {
+	// after a drop next to the rook and bishop, black cannot reach
+	// the last cell of the first rank
+	g := gameFromFEN(t, "br1n/1p2/4/RB2 PN w ud 0 8")
+
+	result, err := New(4).Search(g.Position())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Won() || result.Score != WinScore-3 || result.Depth != 3 {
+		t.Errorf("expected a win in 3 plies found at depth 3, got score %d at depth %d", result.Score, result.Depth)
+	}
+}
+
+func TestSearchPlaysVariants(t *testing.T) {
+	g := gameFromFEN(t, "5/5/5/5/PR3 BNprbn w ud 0 4 5x5-3")
+
+	piece, cell, err := New(2).SelectAction(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if piece.Color != engine.White || cell != (engine.Cell{Row: 4, Col: 2}) {
+		t.Errorf("expected a drop on c1, got %v to %v", piece, cell)
+	}
+}
+
+func TestSearchRejectsFinishedGames(t *testing.T) {
+	g := gameFromFEN(t, "4/4/4/PRBN prbn b ud 0 4")
+
+	if _, err := New(2).Search(g.Position()); !errors.Is(err, engine.ErrGameOver) {
+		t.Errorf("expected ErrGameOver, got %v", err)
+	}
+	if _, _, err := New(2).SelectAction(g); !errors.Is(err, engine.ErrGameOver) {
+		t.Errorf("expected ErrGameOver, got %v", err)
+	}
+}
+
+func TestEvaluateIsSymmetric(t *testing.T) {
+	p := engine.NewPosition()
+	if score := Evaluate(&p); score != 0 {
+		t.Errorf("expected the initial position to score 0, got %d", score)
+	}
+
+	g := gameFromFEN(t, "4/4/4/PRB1 Nprbn w ud 0 4")
+	p = g.Position()
+	white := Evaluate(&p)
+	p.Turn = engine.Black
+	if black := Evaluate(&p); black != -white {
+		t.Errorf("expected black to score %d, got %d", -white, black)
+	}
+}
+
+func TestRunPlayerAnswersMoves(t *testing.T) {
+	bot := New(1).RunPlayer("bot")
+	defer close(bot.Updates)
+
+	g := engine.NewGame()
+	if err := g.Move(engine.WhiteRook, engine.Cell{Row: 0, Col: 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	bot.Updates <- game.PairedEvent{PlayerID: bot.ID, Color: engine.Black}
+	bot.Updates <- game.SnapshotEvent{Game: g.Snapshot()}
+
+	select {
+	case command := <-bot.Commands:
+		move, ok := command.(game.MoveCommand)
+		if !ok {
+			t.Fatalf("expected a MoveCommand, got %T", command)
+		}
+		if err := g.Move(move.Piece, move.To); err != nil {
+			t.Errorf("expected a legal move, got %v: %v", move, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the bot did not answer")
+	}
+}
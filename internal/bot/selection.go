@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"cmp"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// pick returns the index of the action to play among actions with the
+// given weights: visit counts after a search, policy probabilities
+// without one. It plays the heaviest action, unless the options make it
+// blunder or sample, see SearchOptions.
+func (opts SearchOptions) pick(weights []float64) int {
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(weights[b], weights[a])
+	})
+
+	if len(order) > 1 && opts.BlunderChance > 0 && rand.Float64() < opts.BlunderChance {
+		depth := min(max(opts.BlunderDepth, 1), len(order)-1)
+		return order[1+rand.IntN(depth)]
+	}
+
+	if opts.Temperature > 0 {
+		return sample(weights, opts.Temperature)
+	}
+	return order[0]
+}
+
+// sample draws an index with probability proportional to its weight raised
+// to 1/temperature: 1 follows the weights, lower sharpens them towards the
+// heaviest, higher flattens them.
+func sample(weights []float64, temperature float64) int {
+	heaviest := slices.Max(weights)
+	if heaviest <= 0 {
+		return rand.IntN(len(weights))
+	}
+
+	// scaled by the heaviest weight, so that low temperatures do not overflow
+	scaled := make([]float64, len(weights))
+	total := 0.0
+	for i, w := range weights {
+		scaled[i] = math.Pow(w/heaviest, 1/temperature)
+		total += scaled[i]
+	}
+
+	r := rand.Float64() * total
+	for i, w := range scaled {
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	return slices.Index(weights, heaviest)
+}
+
+// addRootNoise mixes Dirichlet noise into the priors of n's children, as
+// AlphaZero does at the root, so that the search also looks at moves the
+// policy dismisses. A root reused from an earlier search gets its noise
+// once.
+func (opts SearchOptions) addRootNoise(n *node) {
+	if opts.DirichletAlpha <= 0 || opts.DirichletWeight <= 0 {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.noised || len(n.children) == 0 {
+		return
+	}
+	n.noised = true
+
+	noise := dirichlet(opts.DirichletAlpha, len(n.children))
+	for i, child := range n.children {
+		child.mu.Lock()
+		child.prior = float32((1-opts.DirichletWeight)*float64(child.prior) + opts.DirichletWeight*noise[i])
+		child.mu.Unlock()
+	}
+}
+
+// dirichlet draws from the symmetric Dirichlet distribution of n
+// components with concentration alpha.
+func dirichlet(alpha float64, n int) []float64 {
+	values := make([]float64, n)
+	total := 0.0
+	for i := range values {
+		values[i] = gamma(alpha)
+		total += values[i]
+	}
+	for i := range values {
+		if total > 0 {
+			values[i] /= total
+		} else {
+			values[i] = 1 / float64(n)
+		}
+	}
+	return values
+}
+
+// gamma draws from the gamma distribution of the given shape and scale 1,
+// with the method of Marsaglia and Tsang.
+func gamma(shape float64) float64 {
+	if shape < 1 {
+		// boost the shape above 1, then scale back down
+		return gamma(shape+1) * math.Pow(rand.Float64(), 1/shape)
+	}
+
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rand.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rand.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
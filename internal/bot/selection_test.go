@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"math"
+	"testing"
+	"tic-tac-chec/engine"
+)
+
+func TestPickHeaviest(t *testing.T) {
+	var opts SearchOptions
+	if got := opts.pick([]float64{3, 7, 7, 1}); got != 1 {
+		t.Errorf("expected the first of the heaviest, got %d", got)
+	}
+}
+
+func TestPickTemperature(t *testing.T) {
+	weights := []float64{10, 5, 1}
+
+	const draws = 4000
+	counts := make([]int, len(weights))
+	opts := SearchOptions{Temperature: 1}
+	for range draws {
+		counts[opts.pick(weights)]++
+	}
+	for i, w := range weights {
+		want := w / 16
+		if got := float64(counts[i]) / draws; math.Abs(got-want) > 0.04 {
+			t.Errorf("action %d: expected a share of %.2f, got %.2f", i, want, got)
+		}
+	}
+
+	cold := SearchOptions{Temperature: 0.01}
+	for range 100 {
+		if got := cold.pick(weights); got != 0 {
+			t.Fatalf("expected a low temperature to play the heaviest, got %d", got)
+		}
+	}
+}
+
+func TestPickBlunder(t *testing.T) {
+	opts := SearchOptions{BlunderChance: 1, BlunderDepth: 2}
+	seen := make(map[int]bool)
+	for range 200 {
+		seen[opts.pick([]float64{2, 5, 4, 3})] = true
+	}
+
+	// ranked 5, 4, 3, 2: the blunders are the second and third
+	if len(seen) != 2 || !seen[2] || !seen[3] {
+		t.Errorf("expected blunders to the second and third best, got %v", seen)
+	}
+}
+
+func TestDirichlet(t *testing.T) {
+	const n, draws = 10, 2000
+
+	mean := 0.0
+	for range draws {
+		values := dirichlet(0.3, n)
+		total := 0.0
+		for _, v := range values {
+			if v < 0 {
+				t.Fatalf("expected no negative component, got %v", v)
+			}
+			total += v
+		}
+		if math.Abs(total-1) > 1e-9 {
+			t.Fatalf("expected components adding up to 1, got %v", total)
+		}
+		mean += values[0] / draws
+	}
+
+	if math.Abs(mean-1.0/n) > 0.02 {
+		t.Errorf("expected a mean component of %v, got %v", 1.0/n, mean)
+	}
+}
+
+func TestRootNoise(t *testing.T) {
+	m := fakeModel(t, SearchOptions{Simulations: 20, DirichletAlpha: 0.3, DirichletWeight: 0.25})
+	g := engine.NewGame()
+	root := &node{game: g.Clone()}
+
+	if _, _, err := runMCTS(m, root, g, m.opts, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !root.noised {
+		t.Fatal("expected noise at the root")
+	}
+
+	total := float32(0)
+	uniform := true
+	for _, child := range root.children {
+		total += child.prior
+		if math.Abs(float64(child.prior-root.children[0].prior)) > 1e-6 {
+			uniform = false
+		}
+	}
+	if math.Abs(float64(total-1)) > 1e-4 {
+		t.Errorf("expected priors adding up to 1, got %v", total)
+	}
+	if uniform {
+		t.Error("expected the noise to change the priors")
+	}
+}
+
+func TestTemperatureVariesOpenings(t *testing.T) {
+	m := NewWithEvaluator(NewRolloutEvaluator(0, 1), SearchOptions{Temperature: 1})
+
+	openings := make(map[engine.Cell]bool)
+	for range 30 {
+		_, cell, err := m.SelectAction(engine.NewGame())
+		if err != nil {
+			t.Fatal(err)
+		}
+		openings[cell] = true
+	}
+	if len(openings) < 2 {
+		t.Error("expected different openings")
+	}
+}
@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"tic-tac-chec/engine"
+)
+
+// Example is a training example from self-play, laid out as the Python
+// trainer's replay buffer stores it.
+type Example struct {
+	// State is the position as the network sees it, StateSize floats.
+	State []float32
+	// Policy is the distribution of the root visits over the
+	// ActionSpaceSize actions, the target of the policy head.
+	Policy []float32
+	// Value is the outcome of the game for the side to move: 1 for a win,
+	// -1 for a loss, 0 for a draw.
+	Value float32
+}
+
+// SelfPlayOptions tunes SelfPlay.
+type SelfPlayOptions struct {
+	// Simulations is the number of simulations per move, the model's own
+	// if zero, or 400 if the model does not search.
+	Simulations int
+	// SampleMoves is the number of plies each game opens with whose moves
+	// are sampled in proportion to the visits.
+	SampleMoves int
+	// FinalTemperature is the temperature the visits are sharpened with
+	// after the opening, see SearchOptions.Temperature; 0 plays the most
+	// visited move.
+	FinalTemperature float64
+}
+
+// SelfPlay plays a game of the model against itself from the start and
+// returns the finished game and an example for every position in it. The
+// policy target of a position is the distribution its move was drawn
+// from: the visits, raised to 1/temperature.
+//
+// Both sides search the same tree, the subtree of each move played
+// becoming the next root. The model's workers and root noise apply, its
+// think time, early stop, temperature and blunders do not: every move gets
+// the full simulation budget.
+func (m *Model) SelfPlay(opts SelfPlayOptions) ([]Example, *engine.Game, error) {
+	searchOpts := SearchOptions{
+		Simulations:     cmp.Or(opts.Simulations, m.opts.Simulations, defaultAnalysisSimulations),
+		Workers:         m.opts.Workers,
+		DirichletAlpha:  m.opts.DirichletAlpha,
+		DirichletWeight: m.opts.DirichletWeight,
+	}
+
+	g := engine.NewGame()
+	root := &node{game: g.Clone()}
+
+	var (
+		examples []Example
+		movers   []engine.Color
+	)
+	for g.Status != engine.GameOver {
+		if err := search(m, root, searchOpts, 0); err != nil {
+			return nil, nil, err
+		}
+		if len(root.children) == 0 {
+			return nil, nil, fmt.Errorf("model: no children after MCTS")
+		}
+
+		temperature := opts.FinalTemperature
+		if len(g.History) < opts.SampleMoves {
+			temperature = 1
+		}
+		policy, weights := visitPolicy(root.children, temperature)
+
+		examples = append(examples, Example{State: NewStateEncoder().Encode(g), Policy: policy})
+		movers = append(movers, g.Turn)
+
+		child := root.children[sample(weights, 1)]
+		piece, cell, err := decodeActionToMove(child.action, g)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := g.Move(piece, cell); err != nil {
+			return nil, nil, fmt.Errorf("model: self-play move: %w", err)
+		}
+
+		child.parent = nil
+		root = child
+		if root.game == nil {
+			// never expanded, see node.game
+			root = &node{game: g.Clone()}
+		}
+	}
+
+	for i := range examples {
+		if g.Winner != nil {
+			examples[i].Value = -1
+			if *g.Winner == movers[i] {
+				examples[i].Value = 1
+			}
+		}
+	}
+
+	return examples, g, nil
+}
+
+// visitPolicy returns the distribution of the children's visits raised to
+// 1/temperature, over the whole action space and as weights in the
+// children's order. Temperature 0 puts it all on the most visited child.
+func visitPolicy(children []*node, temperature float64) ([]float32, []float64) {
+	most := 0
+	for _, child := range children {
+		most = max(most, child.visitCount)
+	}
+
+	weights := make([]float64, len(children))
+	total := 0.0
+	for i, child := range children {
+		switch {
+		case most == 0:
+			weights[i] = 1
+		case temperature <= 0:
+			if child.visitCount == most && total == 0 {
+				weights[i] = 1
+			}
+		default:
+			// scaled by the most visits, so that low temperatures do not overflow
+			weights[i] = math.Pow(float64(child.visitCount)/float64(most), 1/temperature)
+		}
+		total += weights[i]
+	}
+
+	policy := make([]float32, ActionSpaceSize)
+	for i, child := range children {
+		weights[i] /= total
+		policy[child.action] = float32(weights[i])
+	}
+
+	return policy, weights
+}
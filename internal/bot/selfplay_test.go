@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"math"
+	"testing"
+	"tic-tac-chec/engine"
+)
+
+func TestSelfPlay(t *testing.T) {
+	m := NewWithEvaluator(NewRolloutEvaluator(2, 1), SearchOptions{Workers: 2, DirichletAlpha: 0.3, DirichletWeight: 0.25})
+
+	examples, g, err := m.SelfPlay(SelfPlayOptions{Simulations: 32, SampleMoves: 4, FinalTemperature: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Status != engine.GameOver {
+		t.Fatal("expected the game to be played out")
+	}
+	if len(examples) != len(g.History) {
+		t.Fatalf("expected an example per ply, got %d for %d plies", len(examples), len(g.History))
+	}
+
+	replay := engine.NewGame()
+	for i, e := range examples {
+		if len(e.State) != StateSize || len(e.Policy) != ActionSpaceSize {
+			t.Fatalf("example %d: expected %d state and %d policy floats, got %d and %d",
+				i, StateSize, ActionSpaceSize, len(e.State), len(e.Policy))
+		}
+
+		state := NewStateEncoder().Encode(replay)
+		for j := range state {
+			if state[j] != e.State[j] {
+				t.Fatalf("example %d: state differs from the position at index %d", i, j)
+			}
+		}
+
+		// the policy covers the legal actions only, and the move played
+		sum := 0.0
+		legal := make(map[int]bool)
+		for _, action := range legalActions(replay) {
+			legal[action] = true
+		}
+		for action, p := range e.Policy {
+			if p != 0 && !legal[action] {
+				t.Fatalf("example %d: illegal action %d has probability %v", i, action, p)
+			}
+			sum += float64(p)
+		}
+		if math.Abs(sum-1) > 1e-4 {
+			t.Errorf("example %d: expected the policy to sum to 1, got %v", i, sum)
+		}
+		move := g.History[i]
+		if e.Policy[EncodeAction(move)] == 0 {
+			t.Errorf("example %d: the move played has probability 0", i)
+		}
+
+		want := float32(0)
+		if g.Winner != nil {
+			want = -1
+			if *g.Winner == replay.Turn {
+				want = 1
+			}
+		}
+		if e.Value != want {
+			t.Errorf("example %d: expected value %v, got %v", i, want, e.Value)
+		}
+
+		if err := replay.Play(move); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestVisitPolicy(t *testing.T) {
+	children := []*node{
+		{action: 3, visitCount: 6},
+		{action: 10, visitCount: 2},
+		{action: 7, visitCount: 6},
+		{action: 1, visitCount: 0},
+	}
+
+	policy, weights := visitPolicy(children, 1)
+	if policy[3] != 6.0/14 || policy[10] != 2.0/14 || policy[7] != 6.0/14 || policy[1] != 0 {
+		t.Errorf("expected the visit shares at temperature 1, got %v", weights)
+	}
+
+	policy, _ = visitPolicy(children, 0)
+	if policy[3] != 1 || policy[7] != 0 {
+		t.Errorf("expected the first most visited to get everything at temperature 0, got %v and %v", policy[3], policy[7])
+	}
+
+	_, weights = visitPolicy(children, 0.5)
+	if math.Abs(weights[0]-36.0/76) > 1e-9 {
+		t.Errorf("expected squared visit shares at temperature 0.5, got %v", weights)
+	}
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"tic-tac-chec/engine"
+)
+
+// treePlayer is the mover of one MCTS bot player. It keeps the search tree
+// between its moves: once the bot has moved and the opponent has replied,
+// the node of the resulting position becomes the new root, visit counts
+// and values included, and the simulation budget is spent on top of them.
+type treePlayer struct {
+	model *Model
+	root  *node
+}
+
+func newTreePlayer(m *Model) *treePlayer {
+	return &treePlayer{model: m}
+}
+
+func (p *treePlayer) SelectAction(g *engine.Game) (engine.Piece, engine.Cell, error) {
+	if !g.Rules.Standard() {
+		return engine.Piece{}, engine.Cell{}, ErrUnsupportedRules
+	}
+
+	root := p.reuse(g)
+	p.root = root
+
+	return runMCTS(p.model, root, g, p.model.opts, p.model.opts.Budget(g, 0))
+}
+
+// Reset drops the tree. The player loop calls it when a game ends and when
+// the bot is paired, so that no tree outlives its game.
+func (p *treePlayer) Reset() {
+	p.root = nil
+}
+
+// reuse returns the node of the kept tree that holds the position of g,
+// detached from its parent, or a new root if the tree does not reach it.
+func (p *treePlayer) reuse(g *engine.Game) *node {
+	if p.root != nil {
+		if n := p.root.descend(g); n != nil {
+			n.parent = nil
+			return n
+		}
+	}
+	return &node{game: g.Clone()}
+}
+
+// descend follows the moves g played since n's position down the tree.
+// It returns nil if g does not continue from n or the tree was not
+// expanded that far.
+func (n *node) descend(g *engine.Game) *node {
+	played := len(g.History) - len(n.game.History)
+	if played < 0 {
+		return nil
+	}
+
+	current := n
+	for _, move := range g.History[len(g.History)-played:] {
+		current = current.child(EncodeAction(move))
+		if current == nil {
+			return nil
+		}
+	}
+
+	if current.game == nil {
+		// never expanded, see node.game
+		game, err := current.play()
+		if err != nil {
+			return nil
+		}
+		current.game = game
+	}
+
+	// the moves match, the positions before them must too
+	if current.game.MoveCount != g.MoveCount || current.game.Key() != g.Key() {
+		return nil
+	}
+	return current
+}
+
+func (n *node) child(action int) *node {
+	for _, child := range n.children {
+		if child.action == action {
+			return child
+		}
+	}
+	return nil
+}
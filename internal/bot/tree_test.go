@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"testing"
+	"tic-tac-chec/engine"
+)
+
+// fakeModel runs MCTS on the echo network, which needs no ONNX Runtime.
+func fakeModel(t *testing.T, opts SearchOptions) *Model {
+	t.Helper()
+
+	batcher := newBatcher((&echoRun{}).run, BatchOptions{})
+	t.Cleanup(batcher.Close)
+	return NewWithEvaluator(batcher, opts)
+}
+
+func TestTreePlayerReusesSubtree(t *testing.T) {
+	p := newTreePlayer(fakeModel(t, SearchOptions{Simulations: 50}))
+	g := engine.NewGame()
+
+	piece, cell, err := p.SelectAction(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Move(piece, cell); err != nil {
+		t.Fatal(err)
+	}
+	move, _ := g.LastMove()
+	reply := g.AllLegalMoves()[0]
+	if err := g.Play(reply); err != nil {
+		t.Fatal(err)
+	}
+
+	grandchild := p.root.child(EncodeAction(move)).child(EncodeAction(reply))
+	if grandchild == nil {
+		t.Fatal("expected the reply to be in the tree")
+	}
+	visits := grandchild.visitCount
+
+	if _, _, err := p.SelectAction(g); err != nil {
+		t.Fatal(err)
+	}
+	if p.root != grandchild || p.root.parent != nil {
+		t.Fatal("expected the reply's node to become the root")
+	}
+	if p.root.visitCount != visits+50 {
+		t.Errorf("expected %d visits on the reused root, got %d", visits+50, p.root.visitCount)
+	}
+}
+
+func TestTreePlayerStartsOver(t *testing.T) {
+	p := newTreePlayer(fakeModel(t, SearchOptions{Simulations: 50}))
+	if _, _, err := p.SelectAction(engine.NewGame()); err != nil {
+		t.Fatal(err)
+	}
+	first := p.root
+
+	// same move count, different position
+	g, err := engine.ParseFEN("4/4/4/PRB1 Nprbn w ud 0 4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := p.SelectAction(g); err != nil {
+		t.Fatal(err)
+	}
+	if p.root == first || p.root.visitCount != 50 {
+		t.Errorf("expected a new tree for another position, got %d visits", p.root.visitCount)
+	}
+
+	p.Reset()
+	if _, _, err := p.SelectAction(engine.NewGame()); err != nil {
+		t.Fatal(err)
+	}
+	if p.root == first || p.root.visitCount != 50 {
+		t.Errorf("expected a new tree after Reset, got %d visits", p.root.visitCount)
+	}
+}
@@ -7,14 +7,18 @@ import (
 	"tic-tac-chec/engine"
 )
 
-var allKinds = []engine.PieceKind{engine.Pawn, engine.Rook, engine.Bishop, engine.Knight}
-
 func PrintGame(w io.Writer, game *engine.Game) {
-	fmt.Fprintln(w, "  a  b  c  d")
-	for row := range engine.BoardSize {
-		label := engine.BoardSize - row
+	size := game.Rules.Size()
+	files := fileLabels(size)
+
+	if !game.Rules.Standard() {
+		fmt.Fprintf(w, "Rules: %s\n", game.Rules)
+	}
+	fmt.Fprintln(w, files)
+	for row := range size {
+		label := size - row
 		fmt.Fprintf(w, "%d ", label)
-		for col := range engine.BoardSize {
+		for col := range size {
 			piece := game.Board[row][col]
 			if piece == nil {
 				fmt.Fprint(w, ".  ")
@@ -24,26 +28,40 @@ func PrintGame(w io.Writer, game *engine.Game) {
 		}
 		fmt.Fprintln(w, label)
 	}
-	fmt.Fprintln(w, "  a  b  c  d")
+	fmt.Fprintln(w, files)
 	fmt.Fprintf(w, "White hand: %s\n", HandString(game, engine.White))
 	fmt.Fprintf(w, "Black hand: %s\n", HandString(game, engine.Black))
 
 	if game.Status == engine.GameOver {
-		if game.Winner != nil {
+		if game.Winner != nil && game.Termination != engine.FourInARow {
+			fmt.Fprintf(w, "Game over. Winner: %s by %s.\n", *game.Winner, game.Termination)
+		} else if game.Winner != nil {
 			fmt.Fprintf(w, "Game over. Winner: %s\n", *game.Winner)
 		} else {
-			fmt.Fprintln(w, "Game over. Draw.")
+			fmt.Fprintf(w, "Game over. Draw by %s.\n", game.Termination)
 		}
 	} else {
 		fmt.Fprintf(w, "Next turn: %s\n", game.Turn)
 	}
+	fmt.Fprintf(w, "FEN: %s\n", engine.FormatFEN(game))
+}
+
+// fileLabels returns the file letters above and below a board of the given
+// size: "  a  b  c  d" for the standard board.
+func fileLabels(size int) string {
+	var out strings.Builder
+	out.WriteString(" ")
+	for col := range size {
+		fmt.Fprintf(&out, " %c ", 'a'+col)
+	}
+	return strings.TrimRight(out.String(), " ")
 }
 
 func HandString(game *engine.Game, color engine.Color) string {
 	var pieces []string
-	for _, kind := range allKinds {
+	for _, kind := range game.Rules.Kinds() {
 		piece := game.Pieces.Get(color, kind)
-		if _, onBoard := game.Board.Find(piece); !onBoard {
+		if game.PieceInHand(*piece) {
 			pieces = append(pieces, piece.Color.String()+piece.Kind.String())
 		}
 	}
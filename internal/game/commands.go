@@ -22,6 +22,15 @@ type RematchCommand struct {
 	PlayerID PlayerID
 }
 
+type ResignCommand struct {
+	PlayerID PlayerID
+}
+
+// DrawOfferCommand offers the opponent a draw, or accepts the one they offered.
+type DrawOfferCommand struct {
+	PlayerID PlayerID
+}
+
 type ReactionCommand struct {
 	PlayerID PlayerID
 	Reaction string
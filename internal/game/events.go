@@ -12,7 +12,7 @@ type RoomEvent any
 type GameStarted struct {
 	RoomID      RoomID
 	GameID      GameID
-	Game        engine.Game
+	Game        engine.Snapshot
 	GameNumber  uint
 	WhitePlayer PlayerID
 	BlackPlayer PlayerID
@@ -22,7 +22,7 @@ type GameStarted struct {
 func NewGameStarted(
 	roomID RoomID,
 	gameID GameID,
-	game engine.Game,
+	game engine.Snapshot,
 	gameNumber uint,
 	whitePlayer PlayerID,
 	blackPlayer PlayerID,
@@ -39,27 +39,34 @@ func NewGameStarted(
 	}
 }
 
+// StateUpdate carries the game after every change. Termination and
+// WinningLine repeat the snapshot's, for subscribers that only need
+// to know how the game ended.
 type StateUpdate struct {
-	RoomID     RoomID
-	GameID     GameID
-	Game       engine.Game
-	GameNumber uint
-	UpdatedAt  time.Time
+	RoomID      RoomID
+	GameID      GameID
+	Game        engine.Snapshot
+	GameNumber  uint
+	Termination engine.Termination
+	WinningLine []engine.Cell
+	UpdatedAt   time.Time
 }
 
 func NewStateUpdate(
 	roomID RoomID,
 	gameID GameID,
-	game engine.Game,
+	game engine.Snapshot,
 	gameNumber uint,
 	updatedAt time.Time,
 ) StateUpdate {
 	return StateUpdate{
-		RoomID:     roomID,
-		GameID:     gameID,
-		Game:       game,
-		GameNumber: gameNumber,
-		UpdatedAt:  updatedAt,
+		RoomID:      roomID,
+		GameID:      gameID,
+		Game:        game,
+		GameNumber:  gameNumber,
+		Termination: game.Termination(),
+		WinningLine: game.WinningLine(),
+		UpdatedAt:   updatedAt,
 	}
 }
 
@@ -93,14 +100,17 @@ func NewMoveApplied(
 	}
 }
 
+// SnapshotEvent carries the game as an engine.Snapshot, like all events
+// with a game: one snapshot may be sent to several players and subscribers,
+// and none of them shares memory with the room's live game.
 type SnapshotEvent struct {
 	RoomID RoomID
-	Game   engine.Game
+	Game   engine.Snapshot
 }
 
 type GameStartedEvent struct {
 	RoomID      RoomID
-	Game        engine.Game
+	Game        engine.Snapshot
 	PlayerColor engine.Color
 }
 
@@ -134,6 +144,10 @@ type RematchRequestedEvent struct {
 	PlayerID PlayerID
 }
 
+type DrawOfferedEvent struct {
+	PlayerID PlayerID
+}
+
 type ReactionEvent struct {
 	PlayerID PlayerID
 	Reaction string
@@ -32,6 +32,7 @@ type Player struct {
 	Commands        <-chan Command
 	Updates         chan Event
 	ConnectionState string
+	away            *time.Timer // while disconnected from a game in progress, see Room.AbandonAfter
 }
 
 type ReconnectInfo struct {
@@ -45,11 +46,15 @@ type Room struct {
 	GameID                GameID // current game id
 	Players               [2]Player
 	Game                  *engine.Game
+	Rules                 engine.Rules // of every game in the room, rematches included
 	Quit                  chan struct{}
 	Reconnect             chan ReconnectInfo
 	WhiteRematchRequested bool
 	BlackRematchRequested bool
+	WhiteDrawOffered      bool // until the next move
+	BlackDrawOffered      bool
 	GameNumber            uint
+	AbandonAfter          time.Duration // a player may be disconnected from a game in progress before losing it; zero, the default, waits forever
 	subscribers           map[chan<- RoomEvent]struct{}
 	mu                    sync.RWMutex
 }
@@ -80,16 +85,24 @@ func NewPlayerWithID(commands <-chan Command, id string) Player {
 	}
 }
 
-func NewRoom(player1, player2 Player) *Room {
+// NewRoom pairs two players for games under the given rules,
+// or engine.StandardRules if none are given.
+func NewRoom(player1, player2 Player, rules ...engine.Rules) *Room {
 	player1.Color, player2.Color = engine.White, engine.Black
 
+	r := engine.StandardRules
+	if len(rules) > 0 {
+		r = rules[0].Normalized()
+	}
+
 	roomId := uuid.Must(uuid.NewV7()).String()
 	gameId := uuid.Must(uuid.NewV7()).String()
 
 	room := &Room{
 		ID:                    RoomID(roomId),
 		GameID:                GameID(gameId),
-		Game:                  engine.NewGame(),
+		Game:                  engine.NewGame(r),
+		Rules:                 r,
 		Players:               [2]Player{player1, player2},
 		Quit:                  make(chan struct{}),
 		Reconnect:             make(chan ReconnectInfo),
@@ -107,7 +120,7 @@ func (r *Room) Run() {
 		r.close()
 	}()
 
-	r.emit(NewGameStarted(r.ID, r.GameID, *r.Game, r.GameNumber, r.Players[0].ID, r.Players[1].ID, time.Now()))
+	r.emit(NewGameStarted(r.ID, r.GameID, r.Game.Snapshot(), r.GameNumber, r.Players[0].ID, r.Players[1].ID, time.Now()))
 
 	// Before the game starts, send the paired event to each player.
 	// Use sendUpdateTo so restored Players (Updates=nil) don't block Run.
@@ -133,6 +146,10 @@ func (r *Room) Run() {
 				r.handleMove(*r.white(), command)
 			case RematchCommand:
 				r.handleRematch(*r.white())
+			case ResignCommand:
+				r.handleResign(*r.white())
+			case DrawOfferCommand:
+				r.handleDrawOffer(*r.white())
 			case ReactionCommand:
 				r.handleReaction(*r.white(), command)
 			}
@@ -149,10 +166,20 @@ func (r *Room) Run() {
 				r.handleMove(*r.black(), command)
 			case RematchCommand:
 				r.handleRematch(*r.black())
+			case ResignCommand:
+				r.handleResign(*r.black())
+			case DrawOfferCommand:
+				r.handleDrawOffer(*r.black())
 			case ReactionCommand:
 				r.handleReaction(*r.black(), command)
 			}
 
+		case <-r.white().abandoned():
+			r.handleAbandon(r.white())
+
+		case <-r.black().abandoned():
+			r.handleAbandon(r.black())
+
 		case player, ok := <-r.Reconnect:
 			if !ok {
 				continue
@@ -160,11 +187,11 @@ func (r *Room) Run() {
 
 			if player.PlayerID == r.white().ID {
 				r.reconnect(r.white(), player.Commands, player.Updates)
-				sendUpdateTo(*r.white(), SnapshotEvent{RoomID: r.ID, Game: *r.Game})
+				sendUpdateTo(*r.white(), SnapshotEvent{RoomID: r.ID, Game: r.Game.Snapshot()})
 				sendUpdateTo(*r.black(), OpponentReconnectedEvent{PlayerID: r.white().ID})
 			} else if player.PlayerID == r.black().ID {
 				r.reconnect(r.black(), player.Commands, player.Updates)
-				sendUpdateTo(*r.black(), SnapshotEvent{RoomID: r.ID, Game: *r.Game})
+				sendUpdateTo(*r.black(), SnapshotEvent{RoomID: r.ID, Game: r.Game.Snapshot()})
 				sendUpdateTo(*r.white(), OpponentReconnectedEvent{PlayerID: r.black().ID})
 			} else {
 				// ignore reconnect for unknown player
@@ -179,7 +206,7 @@ func (r *Room) Run() {
 }
 
 func (r *Room) close() {
-	r.emit(NewStateUpdate(r.ID, r.GameID, *r.Game, r.GameNumber, time.Now()))
+	r.emit(NewStateUpdate(r.ID, r.GameID, r.Game.Snapshot(), r.GameNumber, time.Now()))
 
 	r.clearSubs()
 
@@ -252,12 +279,86 @@ func (r *Room) handleMove(mover Player, move MoveCommand) {
 		return
 	}
 
+	r.WhiteDrawOffered, r.BlackDrawOffered = false, false
+
 	now := time.Now()
+	snapshot := r.Game.Snapshot()
 	r.emit(NewMoveApplied(r.ID, mover.ID, move.Piece, move.To, r.Game.MoveCount, r.GameNumber, now))
-	r.emit(NewStateUpdate(r.ID, r.GameID, *r.Game, r.GameNumber, now))
+	r.emit(NewStateUpdate(r.ID, r.GameID, snapshot, r.GameNumber, now))
+
+	for _, player := range r.Players {
+		sendUpdateTo(player, SnapshotEvent{RoomID: r.ID, Game: snapshot})
+	}
+}
+
+func (r *Room) handleResign(mover Player) {
+	if err := r.Game.Resign(mover.Color); err != nil {
+		sendUpdateTo(mover, ErrorEvent{Error: err})
+		return
+	}
+
+	r.sendState()
+}
+
+// handleDrawOffer offers a draw to the opponent, or accepts theirs.
+func (r *Room) handleDrawOffer(mover Player) {
+	if r.Game.Status == engine.GameOver {
+		sendUpdateTo(mover, ErrorEvent{Error: engine.ErrGameOver})
+		return
+	}
+
+	switch mover.Color {
+	case engine.White:
+		r.WhiteDrawOffered = true
+		if !r.BlackDrawOffered {
+			sendUpdateTo(*r.black(), DrawOfferedEvent{PlayerID: r.white().ID})
+			return
+		}
+	case engine.Black:
+		r.BlackDrawOffered = true
+		if !r.WhiteDrawOffered {
+			sendUpdateTo(*r.white(), DrawOfferedEvent{PlayerID: r.black().ID})
+			return
+		}
+	default:
+		panic("invalid color")
+	}
+
+	r.WhiteDrawOffered, r.BlackDrawOffered = false, false
+	if err := r.Game.AgreeDraw(); err != nil {
+		sendUpdateTo(mover, ErrorEvent{Error: err})
+		return
+	}
+
+	r.sendState()
+}
+
+// handleAbandon ends the game in progress of a player who did not
+// reconnect in time.
+func (r *Room) handleAbandon(p *Player) {
+	p.away = nil
+
+	// the game may have ended while they were away
+	if r.Game.Status == engine.GameOver {
+		return
+	}
+
+	if err := r.Game.Abandon(p.Color); err != nil {
+		logger.Warn("room.abandon_failed", "room_id", r.ID, "player_id", p.ID, "err", err)
+		return
+	}
+
+	r.sendState()
+}
+
+// sendState sends the game to subscribers and players after a change
+// other than a move.
+func (r *Room) sendState() {
+	snapshot := r.Game.Snapshot()
+	r.emit(NewStateUpdate(r.ID, r.GameID, snapshot, r.GameNumber, time.Now()))
 
 	for _, player := range r.Players {
-		sendUpdateTo(player, SnapshotEvent{RoomID: r.ID, Game: *r.Game})
+		sendUpdateTo(player, SnapshotEvent{RoomID: r.ID, Game: snapshot})
 	}
 }
 
@@ -292,15 +393,17 @@ func (r *Room) startRematch() {
 
 	gameId := uuid.Must(uuid.NewV7()).String()
 	r.GameID = GameID(gameId)
-	r.Game = engine.NewGame()
+	r.Game = engine.NewGame(r.Rules)
 	r.WhiteRematchRequested = false
 	r.BlackRematchRequested = false
+	r.WhiteDrawOffered = false
+	r.BlackDrawOffered = false
 	r.GameNumber++
 
 	// swap colors
 	r.Players[0].Color, r.Players[1].Color = r.Players[1].Color, r.Players[0].Color
 
-	gameSnapshot := *r.Game
+	gameSnapshot := r.Game.Snapshot()
 	gameNumber := r.GameNumber
 	players := r.Players
 	whiteID := r.white().ID
@@ -330,6 +433,17 @@ func (r *Room) disconnect(p *Player) {
 
 	p.Commands = nil
 	p.Updates = nil
+
+	r.awaitReturn(p)
+}
+
+// awaitReturn gives a player who disconnected AbandonAfter to reconnect
+// before they lose the game in progress. Players of restored rooms, who
+// start disconnected, are not timed until they have reconnected once.
+func (r *Room) awaitReturn(p *Player) {
+	if r.AbandonAfter > 0 && r.Game.Status != engine.GameOver && p.away == nil {
+		p.away = time.NewTimer(r.AbandonAfter)
+	}
 }
 
 func (r *Room) reconnect(p *Player, commands <-chan Command, updates chan Event) {
@@ -340,6 +454,11 @@ func (r *Room) reconnect(p *Player, commands <-chan Command, updates chan Event)
 		close(p.Updates)
 	}
 
+	if p.away != nil {
+		p.away.Stop()
+		p.away = nil
+	}
+
 	p.ConnectionState = Connected
 	p.Commands = commands // TODO: should we close the old commands channel?
 	p.Updates = updates
@@ -358,6 +477,15 @@ func (r *Room) PlayerColor(playerID PlayerID) engine.Color {
 	panic("player not found")
 }
 
+// abandoned fires when the player has been away for the room's
+// AbandonAfter, and never while they are connected.
+func (p *Player) abandoned() <-chan time.Time {
+	if p.away == nil {
+		return nil
+	}
+	return p.away.C
+}
+
 func (r *Room) white() *Player {
 	if r.Players[0].Color == engine.White {
 		return &r.Players[0]
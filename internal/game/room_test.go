@@ -48,7 +48,7 @@ func TestRoom(t *testing.T) {
 		t.Fatalf("expected GameStateMsg, got: %T", msg0)
 	}
 
-	piece := state.Game.Board.At(engine.Cell{Row: 0, Col: 0})
+	piece := state.Game.Game().Board.At(engine.Cell{Row: 0, Col: 0})
 	if *piece != engine.WhiteBishop {
 		t.Fatalf("expected WhiteBishop at {0, 0}, got: %v", piece)
 	}
@@ -264,6 +264,159 @@ func TestRoom_SubscribeReceivesMoveAppliedThenStateUpdate(t *testing.T) {
 	}
 }
 
+func TestRoom_ResignEndsGame(t *testing.T) {
+	room, commands := setupRoom()
+	defer close(commands[0])
+
+	subscriber := make(chan RoomEvent, 10)
+	cancel := room.Subscribe(subscriber)
+	defer cancel()
+
+	go room.Run()
+
+	<-subscriber // drain GameStarted message
+
+	commands[1] <- ResignCommand{PlayerID: room.Players[1].ID}
+
+	event, ok := <-subscriber
+	if !ok {
+		t.Fatalf("expected state update to be received after resignation, but none was received")
+	}
+	update, ok := event.(StateUpdate)
+	if !ok {
+		t.Fatalf("expected StateUpdate, but got: %v", event)
+	}
+	if update.Termination != engine.Resignation {
+		t.Fatalf("expected resignation, got %v", update.Termination)
+	}
+	if winner := update.Game.Winner(); winner == nil || *winner != engine.White {
+		t.Fatalf("expected white to win, got %v", winner)
+	}
+}
+
+func TestRoom_DrawOffersEndGameWhenBothAgree(t *testing.T) {
+	room, commands := setupRoom()
+	defer close(commands[0])
+	defer close(commands[1])
+	defer close(room.Quit)
+
+	subscriber := make(chan RoomEvent, 10)
+	cancel := room.Subscribe(subscriber)
+	defer cancel()
+
+	go room.Run()
+
+	<-room.Players[0].Updates // paired
+	<-room.Players[1].Updates
+
+	commands[0] <- DrawOfferCommand{PlayerID: room.Players[0].ID}
+	require.Equal(t, DrawOfferedEvent{PlayerID: room.Players[0].ID}, <-room.Players[1].Updates)
+
+	// a move declines the offer
+	commands[0] <- MoveCommand{Piece: engine.WhiteRook, To: engine.Cell{Row: 0, Col: 0}}
+	<-room.Players[0].Updates // snapshots
+	<-room.Players[1].Updates
+	commands[1] <- DrawOfferCommand{PlayerID: room.Players[1].ID}
+	require.Equal(t, DrawOfferedEvent{PlayerID: room.Players[1].ID}, <-room.Players[0].Updates)
+
+	commands[0] <- DrawOfferCommand{PlayerID: room.Players[0].ID}
+
+	for event := range subscriber {
+		update, ok := event.(StateUpdate)
+		if !ok || update.Game.Status() != engine.GameOver {
+			continue
+		}
+		require.Equal(t, engine.AgreedDraw, update.Termination)
+		require.Nil(t, update.Game.Winner())
+		return
+	}
+}
+
+func TestRoom_DisconnectedPlayerAbandonsGame(t *testing.T) {
+	room, commands := setupRoom()
+	room.AbandonAfter = 10 * time.Millisecond
+	defer close(commands[0])
+	defer close(room.Quit)
+
+	subscriber := make(chan RoomEvent, 10)
+	cancel := room.Subscribe(subscriber)
+	defer cancel()
+
+	go room.Run()
+
+	<-subscriber // GameStarted
+
+	close(commands[1])
+
+	select {
+	case event := <-subscriber:
+		update, ok := event.(StateUpdate)
+		require.True(t, ok, "expected StateUpdate, got %T", event)
+		require.Equal(t, engine.Abandonment, update.Termination)
+		winner := update.Game.Winner()
+		require.NotNil(t, winner)
+		require.Equal(t, engine.White, *winner)
+	case <-time.After(time.Second):
+		t.Fatal("expected the game to be abandoned")
+	}
+}
+
+func TestRoom_RestoredPlayerIsNotAbandonedBeforeReconnecting(t *testing.T) {
+	commands := make(chan Command)
+	defer close(commands)
+	white := NewPlayer(commands)
+	// restored players start disconnected, see registry.playerFor
+	black := Player{ID: "black", ConnectionState: Disconnected}
+
+	room := NewRoom(white, black)
+	require.Zero(t, room.AbandonAfter, "abandonment should be opt-in")
+	room.AbandonAfter = 10 * time.Millisecond
+	defer close(room.Quit)
+
+	subscriber := make(chan RoomEvent, 10)
+	cancel := room.Subscribe(subscriber)
+	defer cancel()
+
+	go room.Run()
+
+	<-subscriber // GameStarted
+
+	select {
+	case event := <-subscriber:
+		t.Fatalf("expected the game to go on, got %#v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRoom_ReconnectKeepsGame(t *testing.T) {
+	room, commands := setupRoom()
+	room.AbandonAfter = 50 * time.Millisecond
+	defer close(commands[0])
+	defer close(room.Quit)
+
+	subscriber := make(chan RoomEvent, 10)
+	cancel := room.Subscribe(subscriber)
+	defer cancel()
+
+	go room.Run()
+
+	<-subscriber // GameStarted
+	<-room.Players[0].Updates
+
+	close(commands[1])
+	<-room.Players[0].Updates // opponent away
+
+	newCommands := make(chan Command)
+	defer close(newCommands)
+	room.Reconnect <- ReconnectInfo{PlayerID: room.Players[1].ID, Commands: newCommands, Updates: make(chan Event, 1)}
+
+	select {
+	case event := <-subscriber:
+		t.Fatalf("expected the game to go on, got %#v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
 func TestRoom_CancelStopsDelivery(t *testing.T) {
 	room, commands := setupRoom()
 	defer close(commands[0])
@@ -408,3 +561,60 @@ func TestRoom_RematchWithSubscriber(t *testing.T) {
 	require.Equal(t, uint(2), gs2.GameNumber)
 	require.NotEqual(t, initialWhite, gs2.WhitePlayer, "colors should be swapped after rematch")
 }
+
+func TestRoom_SnapshotsDoNotChangeAfterLaterMoves(t *testing.T) {
+	room, commands := setupRoom()
+	defer close(commands[0])
+	defer close(commands[1])
+	defer close(room.Quit)
+
+	go room.Run()
+
+	<-room.Players[0].Updates
+	<-room.Players[1].Updates
+
+	commands[0] <- MoveCommand{Piece: engine.WhiteBishop, To: engine.Cell{Row: 1, Col: 1}}
+	first := (<-room.Players[0].Updates).(SnapshotEvent)
+	<-room.Players[1].Updates
+	fen := engine.FormatFEN(first.Game.Game())
+
+	commands[1] <- MoveCommand{Piece: engine.BlackBishop, To: engine.Cell{Row: 2, Col: 2}}
+	<-room.Players[0].Updates
+	<-room.Players[1].Updates
+	commands[0] <- MoveCommand{Piece: engine.WhiteBishop, To: engine.Cell{Row: 2, Col: 2}}
+	<-room.Players[0].Updates
+	<-room.Players[1].Updates
+
+	require.Equal(t, fen, engine.FormatFEN(first.Game.Game()))
+	require.Len(t, first.Game.Game().History, 1)
+}
+
+func TestRoom_RematchKeepsRules(t *testing.T) {
+	commands := [2]chan Command{make(chan Command), make(chan Command)}
+	defer close(commands[0])
+	defer close(commands[1])
+
+	rules := engine.Rules{BoardSize: 5, WinLength: 4, Queen: true}
+	room := NewRoom(NewPlayer(commands[0]), NewPlayer(commands[1]), rules)
+	defer close(room.Quit)
+
+	sub := make(chan RoomEvent, 1000)
+	cancel := room.Subscribe(sub)
+	defer cancel()
+
+	go room.Run()
+
+	gs1 := (<-sub).(GameStarted)
+	require.Equal(t, rules, gs1.Game.Rules())
+
+	commands[0] <- RematchCommand{PlayerID: room.Players[0].ID}
+	commands[1] <- RematchCommand{PlayerID: room.Players[1].ID}
+
+	for {
+		if gs, ok := (<-sub).(GameStarted); ok {
+			require.Equal(t, uint(2), gs.GameNumber)
+			require.Equal(t, rules, gs.Game.Rules())
+			break
+		}
+	}
+}
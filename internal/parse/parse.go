@@ -16,6 +16,8 @@ func Piece(piece string) (engine.Piece, error) {
 		return engine.WhiteBishop, nil
 	case "WP":
 		return engine.WhitePawn, nil
+	case "WQ":
+		return engine.WhiteQueen, nil
 	case "BR":
 		return engine.BlackRook, nil
 	case "BP":
@@ -24,12 +26,21 @@ func Piece(piece string) (engine.Piece, error) {
 		return engine.BlackKnight, nil
 	case "BB":
 		return engine.BlackBishop, nil
+	case "BQ":
+		return engine.BlackQueen, nil
 	default:
 		return engine.Piece{}, fmt.Errorf("invalid piece: %s", piece)
 	}
 }
 
+// Square parses a square of the standard board: a1 .. d4.
 func Square(square string) (engine.Cell, error) {
+	return SquareOn(square, engine.BoardSize)
+}
+
+// SquareOn parses a square of a board of the given size,
+// a1 being the bottom-left cell.
+func SquareOn(square string, size int) (engine.Cell, error) {
 	if len(square) != 2 {
 		return engine.Cell{}, fmt.Errorf("invalid square: %s", square)
 	}
@@ -37,12 +48,30 @@ func Square(square string) (engine.Cell, error) {
 	file := square[0]
 	rank := square[1]
 
-	if file < 'a' || file > 'd' || rank < '1' || rank > '4' {
+	if file < 'a' || file >= 'a'+byte(size) || rank < '1' || rank >= '1'+byte(size) {
 		return engine.Cell{}, fmt.Errorf("invalid square: %s", square)
 	}
 
 	col := int(file - 'a')
-	row := int('4' - rank)
+	row := size - int(rank-'0')
 
 	return engine.Cell{Row: row, Col: col}, nil
 }
+
+// FormatPiece is the inverse of Piece: WhiteRook → "WR".
+func FormatPiece(piece engine.Piece) string {
+	return piece.Color.String() + piece.Kind.String()
+}
+
+// FormatSquare is the inverse of Square: Cell{Row: 3, Col: 0} → "a1".
+func FormatSquare(cell engine.Cell) string {
+	return FormatSquareOn(cell, engine.BoardSize)
+}
+
+// FormatSquareOn is the inverse of SquareOn.
+func FormatSquareOn(cell engine.Cell, size int) string {
+	file := 'a' + rune(cell.Col)
+	rank := '0' + rune(size-cell.Row)
+
+	return string([]rune{file, rank})
+}
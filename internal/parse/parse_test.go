@@ -26,3 +26,50 @@ func TestSquare(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatSquareRoundTrip(t *testing.T) {
+	for row := range engine.BoardSize {
+		for col := range engine.BoardSize {
+			cell := engine.Cell{Row: row, Col: col}
+			parsed, err := Square(FormatSquare(cell))
+			if err != nil {
+				t.Fatalf("Square(FormatSquare(%v)) returned error: %v", cell, err)
+			}
+			if parsed != cell {
+				t.Errorf("Square(FormatSquare(%v)) = %v", cell, parsed)
+			}
+		}
+	}
+}
+
+func TestSquareOnLargerBoard(t *testing.T) {
+	cell, err := SquareOn("e5", engine.MaxBoardSize)
+	if err != nil {
+		t.Fatalf("SquareOn(e5) returned error: %v", err)
+	}
+	if cell != (engine.Cell{Row: 0, Col: 4}) {
+		t.Errorf("SquareOn(e5) = %v", cell)
+	}
+	if got := FormatSquareOn(engine.Cell{Row: 4, Col: 0}, engine.MaxBoardSize); got != "a1" {
+		t.Errorf("FormatSquareOn = %q, want a1", got)
+	}
+
+	if _, err := Square("e5"); err == nil {
+		t.Error("Square(e5) must fail on the standard board")
+	}
+}
+
+func TestFormatPieceRoundTrip(t *testing.T) {
+	for color := range engine.ColorCount {
+		for kind := range engine.PieceKindCount {
+			piece := engine.Piece{Color: color, Kind: kind}
+			parsed, err := Piece(FormatPiece(piece))
+			if err != nil {
+				t.Fatalf("Piece(FormatPiece(%v)) returned error: %v", piece, err)
+			}
+			if parsed != piece {
+				t.Errorf("Piece(FormatPiece(%v)) = %v", piece, parsed)
+			}
+		}
+	}
+}
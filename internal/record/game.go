@@ -0,0 +1,118 @@
+package record
+
+import (
+	"fmt"
+	"tic-tac-chec/engine"
+)
+
+// FromGame builds the record of a game: its moves, result and termination,
+// and a FEN tag if the game did not start from the initial position.
+// Extra tags (players, date, bot difficulty) come first, in the given order.
+func FromGame(g *engine.Game, tags ...Tag) *Record {
+	r := &Record{Tags: append([]Tag(nil), tags...)}
+
+	start := g.Clone()
+	start.Reopen()
+	for start.Undo() == nil {
+	}
+	if fen := engine.FormatFEN(start); fen != engine.StartFEN {
+		r.SetTag(TagFEN, fen)
+	}
+
+	r.SetTag(TagResult, ResultOf(g))
+	if g.Termination != engine.NotTerminated {
+		r.SetTag(TagTermination, g.Termination.String())
+	}
+
+	for i, move := range g.History {
+		wins := i == len(g.History)-1 && g.Termination == engine.FourInARow
+		r.Moves = append(r.Moves, FormatMove(move, g.Rules.Size(), wins))
+	}
+
+	return r
+}
+
+// ResultOf returns the result of the game as written in records.
+func ResultOf(g *engine.Game) string {
+	if g.Status != engine.GameOver {
+		return ResultOngoing
+	}
+	if g.Winner == nil {
+		return ResultDraw
+	}
+	if *g.Winner == engine.White {
+		return ResultWhiteWins
+	}
+	return ResultBlackWins
+}
+
+// Replay plays the record's moves through the engine, starting from
+// the FEN tag if there is one, and returns the resulting game.
+// It fails on the first illegal move, and if the Result or Termination
+// tags do not match how the game actually ended. Games the moves leave
+// unfinished are ended by the tags if they tell of a resignation, abandonment
+// or agreed draw.
+func Replay(r *Record) (*engine.Game, error) {
+	g := engine.NewGame()
+	if fen, ok := r.Tag(TagFEN); ok {
+		var err error
+		if g, err = engine.ParseFEN(fen); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRecord, err)
+		}
+	}
+
+	for i, text := range r.Moves {
+		move, err := ParseMove(g, text)
+		if err == nil {
+			err = g.Play(move)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: move %d (%s): %w", ErrInvalidRecord, i+1, text, err)
+		}
+
+		wins := g.Termination == engine.FourInARow
+		if moveText.FindStringSubmatch(text)[4] == "#" && !wins {
+			return nil, fmt.Errorf("%w: move %d (%s) does not win", ErrInvalidRecord, i+1, text)
+		}
+	}
+
+	if termination, ok := r.Tag(TagTermination); ok && g.Status != engine.GameOver {
+		endGame(g, termination, r.Result())
+	}
+
+	if result := ResultOf(g); result != r.Result() {
+		return nil, fmt.Errorf("%w: Result tag is %s, but the moves give %s", ErrInvalidRecord, r.Result(), result)
+	}
+	if termination, ok := r.Tag(TagTermination); ok && termination != g.Termination.String() {
+		return nil, fmt.Errorf("%w: Termination tag is %q, but the game ended by %q", ErrInvalidRecord, termination, g.Termination)
+	}
+
+	return g, nil
+}
+
+// endGame ends an unfinished game the way the Termination and Result tags
+// tell. It leaves the game alone if the tags tell of anything else, for
+// Replay to report the mismatch.
+func endGame(g *engine.Game, termination, result string) {
+	var loser engine.Color
+	switch result {
+	case ResultWhiteWins:
+		loser = engine.Black
+	case ResultBlackWins:
+		loser = engine.White
+	case ResultDraw:
+		if termination == engine.AgreedDraw.String() {
+			g.AgreeDraw()
+		}
+		return
+	default:
+		return
+	}
+
+	switch termination {
+	case engine.Resignation.String():
+		g.Resign(loser)
+	case engine.Abandonment.String():
+		g.Abandon(loser)
+	}
+}
@@ -0,0 +1,83 @@
+package record
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/parse"
+)
+
+var moveText = regexp.MustCompile(`^([PRBNQprbnq])(@|x?)([a-e][1-5])([#+]?)$`)
+
+// FormatMove writes a move in record notation: N@b2 for a drop,
+// Nc3 for a move and Nxc3 for a capture, naming squares for a board of the
+// given size. wins adds the "#" suffix.
+func FormatMove(m engine.Move, size int, wins bool) string {
+	var out strings.Builder
+
+	out.WriteString(m.Piece.Kind.String())
+	switch {
+	case m.Drop:
+		out.WriteString("@")
+	case m.Capture:
+		out.WriteString("x")
+	}
+	out.WriteString(parse.FormatSquareOn(m.To, size))
+	if wins {
+		out.WriteString("#")
+	}
+
+	return out.String()
+}
+
+// ParseMove reads a move in record notation for the side to move in g.
+// It checks that the move fits the position (a dropped piece is in hand,
+// a moved piece is on the board, "x" is used exactly for captures) but
+// leaves piece movement rules to the engine.
+func ParseMove(g *engine.Game, text string) (engine.Move, error) {
+	m := moveText.FindStringSubmatch(text)
+	if m == nil {
+		return engine.Move{}, fmt.Errorf("malformed move %q", text)
+	}
+
+	piece, err := parse.Piece(g.Turn.String() + m[1])
+	if err != nil {
+		return engine.Move{}, err
+	}
+	to, err := parse.SquareOn(m[3], g.Rules.Size())
+	if err != nil {
+		return engine.Move{}, err
+	}
+
+	move := engine.Move{Piece: piece, To: to}
+	target := g.Board.At(to)
+
+	if m[2] == "@" {
+		if !g.PieceInHand(piece) {
+			return engine.Move{}, fmt.Errorf("%s: %s is not in hand", text, piece.FriendlyName())
+		}
+		move.Drop = true
+		return move, nil
+	}
+
+	from, onBoard := g.Find(piece)
+	if !onBoard {
+		return engine.Move{}, fmt.Errorf("%s: %s is not on the board", text, piece.FriendlyName())
+	}
+	move.From = from
+
+	capture := m[2] == "x"
+	if capture != (target != nil) {
+		if capture {
+			return engine.Move{}, fmt.Errorf("%s: nothing to capture on %s", text, m[3])
+		}
+		return engine.Move{}, fmt.Errorf("%s: %s is occupied, write %sx%s", text, m[3], m[1], m[3])
+	}
+	if capture {
+		move.Capture = true
+		move.Captured = *target
+	}
+
+	return move, nil
+}
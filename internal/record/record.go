@@ -0,0 +1,212 @@
+// Package record reads and writes game records: a PGN-like text format
+// with header tags followed by the moves of the game.
+//
+//	[White "alice"]
+//	[Black "bot"]
+//	[Date "2026.04.21"]
+//	[Result "1-0"]
+//	[Termination "four in a row"]
+//
+//	1. R@a4 R@d4 2. P@a3 P@d3 3. B@a2 B@d2 4. N@a1# 1-0
+//
+// Moves use the piece letters of parse.Piece without the color, which follows
+// from the move order, and the squares of parse.Square. A drop from hand is
+// written with "@" (N@b2), a move on the board with the target square only
+// (Nc3, or Nxc3 for a capture): each side has one piece of each kind, so
+// the source square is never needed. A move that wins the game ends with "#".
+package record
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Standard tag names.
+const (
+	TagWhite         = "White"
+	TagBlack         = "Black"
+	TagDate          = "Date"
+	TagResult        = "Result"
+	TagTermination   = "Termination"
+	TagBotDifficulty = "BotDifficulty"
+	TagFEN           = "FEN" // starting position, if not the initial one
+)
+
+// Results, as written in the Result tag and at the end of the move list.
+const (
+	ResultWhiteWins = "1-0"
+	ResultBlackWins = "0-1"
+	ResultDraw      = "1/2-1/2"
+	ResultOngoing   = "*"
+)
+
+// DateFormat is the time layout of the Date tag.
+const DateFormat = "2006.01.02"
+
+var ErrInvalidRecord = errors.New("invalid game record")
+
+// Tag is a single header line: [Name "Value"].
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Record is a game record. Tags keep their order; Moves are in move notation.
+type Record struct {
+	Tags  []Tag
+	Moves []string
+}
+
+// Tag returns the value of the named tag.
+func (r *Record) Tag(name string) (string, bool) {
+	for _, tag := range r.Tags {
+		if tag.Name == name {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetTag replaces the value of the named tag or appends the tag.
+func (r *Record) SetTag(name, value string) {
+	for i, tag := range r.Tags {
+		if tag.Name == name {
+			r.Tags[i].Value = value
+			return
+		}
+	}
+	r.Tags = append(r.Tags, Tag{Name: name, Value: value})
+}
+
+// Result returns the Result tag, or ResultOngoing if there is none.
+func (r *Record) Result() string {
+	if result, ok := r.Tag(TagResult); ok {
+		return result
+	}
+	return ResultOngoing
+}
+
+// Encode writes the record as text.
+func Encode(w io.Writer, r *Record) error {
+	_, err := io.WriteString(w, Format(r))
+	return err
+}
+
+// Format returns the record as text: tags, a blank line, and the move list
+// with move numbers, ending with the result.
+func Format(r *Record) string {
+	var out strings.Builder
+
+	for _, tag := range r.Tags {
+		value := strings.ReplaceAll(tag.Value, `\`, `\\`)
+		value = strings.ReplaceAll(value, `"`, `\"`)
+		fmt.Fprintf(&out, "[%s \"%s\"]\n", tag.Name, value)
+	}
+	if len(r.Tags) > 0 {
+		out.WriteString("\n")
+	}
+
+	blackFirst := false
+	if fen, ok := r.Tag(TagFEN); ok {
+		blackFirst = sideToMove(fen) == "b"
+	}
+
+	for i, move := range r.Moves {
+		ply := i
+		if blackFirst {
+			ply++
+		}
+
+		switch {
+		case ply%2 == 0:
+			fmt.Fprintf(&out, "%d. ", ply/2+1)
+		case i == 0:
+			fmt.Fprintf(&out, "%d... ", ply/2+1)
+		}
+		out.WriteString(move)
+		out.WriteString(" ")
+	}
+	out.WriteString(r.Result())
+	out.WriteString("\n")
+
+	return out.String()
+}
+
+var (
+	tagLine    = regexp.MustCompile(`^\[([A-Za-z0-9_]+)\s+"((?:[^"\\]|\\.)*)"\]$`)
+	moveNumber = regexp.MustCompile(`^\d+\.+`)
+)
+
+// Decode reads a single record. Moves are only checked for their shape;
+// use Replay to check that they are legal.
+func Decode(rd io.Reader) (*Record, error) {
+	r := &Record{}
+	var result string
+
+	scanner := bufio.NewScanner(rd)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if len(r.Moves) > 0 || result != "" {
+				return nil, fmt.Errorf("%w: line %d: tag after moves", ErrInvalidRecord, lineNo)
+			}
+
+			m := tagLine.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("%w: line %d: malformed tag %q", ErrInvalidRecord, lineNo, line)
+			}
+			value := strings.ReplaceAll(m[2], `\"`, `"`)
+			value = strings.ReplaceAll(value, `\\`, `\`)
+			r.Tags = append(r.Tags, Tag{Name: m[1], Value: value})
+			continue
+		}
+
+		for _, token := range strings.Fields(line) {
+			if result != "" {
+				return nil, fmt.Errorf("%w: line %d: %q after the result", ErrInvalidRecord, lineNo, token)
+			}
+
+			token = moveNumber.ReplaceAllString(token, "")
+			switch token {
+			case "":
+			case ResultWhiteWins, ResultBlackWins, ResultDraw, ResultOngoing:
+				result = token
+			default:
+				if !moveText.MatchString(token) {
+					return nil, fmt.Errorf("%w: line %d: malformed move %q", ErrInvalidRecord, lineNo, token)
+				}
+				r.Moves = append(r.Moves, token)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if result != "" && result != r.Result() {
+		return nil, fmt.Errorf("%w: result %s does not match the Result tag %s", ErrInvalidRecord, result, r.Result())
+	}
+
+	return r, nil
+}
+
+// Parse is Decode for a string.
+func Parse(text string) (*Record, error) {
+	return Decode(strings.NewReader(text))
+}
+
+func sideToMove(fen string) string {
+	fields := strings.Fields(fen)
+	if len(fields) < 3 {
+		return ""
+	}
+	return fields[2]
+}
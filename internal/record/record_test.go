@@ -0,0 +1,241 @@
+package record
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"tic-tac-chec/engine"
+)
+
+const wonGame = `[White "alice"]
+[Black "bob"]
+[Date "2026.04.21"]
+[Result "1-0"]
+[Termination "four in a row"]
+
+1. R@a4 R@d4 2. P@a3 P@d3 3. B@a2 B@d2 4. N@a1# 1-0
+`
+
+func TestFormatMove(t *testing.T) {
+	tests := []struct {
+		move     engine.Move
+		wins     bool
+		expected string
+	}{
+		{engine.Move{Piece: engine.WhiteKnight, To: engine.Cell{Row: 2, Col: 1}, Drop: true}, false, "N@b2"},
+		{engine.Move{Piece: engine.BlackRook, From: engine.Cell{Row: 0, Col: 0}, To: engine.Cell{Row: 0, Col: 3}}, false, "Rd4"},
+		{engine.Move{Piece: engine.WhiteBishop, From: engine.Cell{Row: 1, Col: 1}, To: engine.Cell{Row: 2, Col: 2}, Capture: true}, false, "Bxc2"},
+		{engine.Move{Piece: engine.WhitePawn, To: engine.Cell{Row: 3, Col: 0}, Drop: true}, true, "P@a1#"},
+	}
+
+	for _, test := range tests {
+		if got := FormatMove(test.move, engine.BoardSize, test.wins); got != test.expected {
+			t.Errorf("FormatMove(%v) = %q, want %q", test.move, got, test.expected)
+		}
+	}
+}
+
+func TestParseMove(t *testing.T) {
+	g := engine.NewGame()
+	g.Move(engine.WhiteBishop, engine.Cell{Row: 1, Col: 1})
+	g.Move(engine.BlackBishop, engine.Cell{Row: 2, Col: 2})
+
+	move, err := ParseMove(g, "Bxc2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !move.Capture || move.Captured != engine.BlackBishop || move.From != (engine.Cell{Row: 1, Col: 1}) {
+		t.Errorf("unexpected move %+v", move)
+	}
+
+	for _, text := range []string{"Bc2", "B@a1", "Rd4", "Rxa1", "Q@a1", "N@e5"} {
+		if _, err := ParseMove(g, text); err == nil {
+			t.Errorf("ParseMove(%q) expected error", text)
+		}
+	}
+
+	// there is no king, whatever the case of the letter
+	for _, text := range []string{"K@a1", "Kb2", "k@a1", "kxc2"} {
+		if _, err := ParseMove(g, text); err == nil || !strings.Contains(err.Error(), "malformed") {
+			t.Errorf("ParseMove(%q) = %v, want malformed move", text, err)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	r, err := Parse(wonGame)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := Replay(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Status != engine.GameOver || g.Winner == nil || *g.Winner != engine.White {
+		t.Errorf("expected White to win, got status %v winner %v", g.Status, g.Winner)
+	}
+	if white, _ := r.Tag(TagWhite); white != "alice" {
+		t.Errorf("White tag = %q", white)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := engine.NewGame()
+	moves := []struct {
+		piece engine.Piece
+		cell  engine.Cell
+	}{
+		{engine.WhiteBishop, engine.Cell{Row: 1, Col: 1}},
+		{engine.BlackBishop, engine.Cell{Row: 2, Col: 2}},
+		{engine.WhiteBishop, engine.Cell{Row: 2, Col: 2}}, // capture
+		{engine.BlackBishop, engine.Cell{Row: 0, Col: 0}}, // drop back
+		{engine.WhitePawn, engine.Cell{Row: 1, Col: 1}},
+		{engine.BlackKnight, engine.Cell{Row: 3, Col: 3}},
+		{engine.WhitePawn, engine.Cell{Row: 0, Col: 1}},
+	}
+	for _, m := range moves {
+		if err := g.Move(m.piece, m.cell); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := FromGame(g, Tag{TagWhite, "alice"}, Tag{TagBotDifficulty, "easy"})
+	text := Format(r)
+	if !strings.Contains(text, "1. B@b3 B@c2 2. Bxc2 B@a4 3. P@b3 N@d1 4. Pb4 *") {
+		t.Errorf("unexpected move list:\n%s", text)
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := Replay(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if engine.FormatFEN(replayed) != engine.FormatFEN(g) {
+		t.Errorf("replayed position %s, want %s", engine.FormatFEN(replayed), engine.FormatFEN(g))
+	}
+	if Format(parsed) != text {
+		t.Errorf("Format(Parse(text)) differs:\n%s\nwant:\n%s", Format(parsed), text)
+	}
+}
+
+func TestRoundTripFromFEN(t *testing.T) {
+	g, err := engine.ParseFEN("R3/4/4/3r PBNpbn b ud 0 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Move(engine.BlackRook, engine.Cell{Row: 0, Col: 3})
+	g.Move(engine.WhiteRook, engine.Cell{Row: 0, Col: 3})
+
+	r := FromGame(g)
+	text := Format(r)
+	if !strings.Contains(text, `[FEN "R3/4/4/3r PBNpbn b ud 0 2"]`) || !strings.Contains(text, "1... Rd4 2. Rxd4 *") {
+		t.Errorf("unexpected record:\n%s", text)
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Replay(parsed); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTagEscaping(t *testing.T) {
+	r := &Record{Tags: []Tag{{TagWhite, `Bobby "The Pawn" \ Tables`}}}
+
+	parsed, err := Parse(Format(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if white, _ := parsed.Tag(TagWhite); white != r.Tags[0].Value {
+		t.Errorf("White tag = %q, want %q", white, r.Tags[0].Value)
+	}
+}
+
+func TestInvalidRecords(t *testing.T) {
+	records := []string{
+		"[White alice]\n\n*",
+		"1. R@a4 [White \"alice\"]",
+		"1. R@a4 R@f5 *",
+		"1. R@a4 *\n2. R@d4",
+		"[Result \"1-0\"]\n\n1. R@a4 0-1",
+	}
+	for _, text := range records {
+		if _, err := Parse(text); !errors.Is(err, ErrInvalidRecord) {
+			t.Errorf("Parse(%q) = %v, want ErrInvalidRecord", text, err)
+		}
+	}
+
+	illegal := []string{
+		"1. R@a4 R@a4 *",                  // occupied
+		"1. R@a4 R@d4 2. Rb3 *",           // rook can't reach b3
+		"1. R@a4# *",                      // does not win
+		"[Result \"1-0\"]\n\n1. R@a4 1-0", // not over
+		strings.Replace(wonGame, "four in a row", "move limit", 1),
+		strings.Replace(wonGame, "N@a1#", "N@a1# N@d1", 1), // game already over
+	}
+	for _, text := range illegal {
+		r, err := Parse(text)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", text, err)
+		}
+		if _, err := Replay(r); !errors.Is(err, ErrInvalidRecord) {
+			t.Errorf("Replay(%q) = %v, want ErrInvalidRecord", text, err)
+		}
+	}
+}
+
+func TestRoundTripVariant(t *testing.T) {
+	g := engine.NewGame(engine.Rules{BoardSize: 5, Queen: true, DiscardCaptures: true})
+	g.Move(engine.WhiteQueen, engine.Cell{Row: 0, Col: 4})
+	g.Move(engine.BlackRook, engine.Cell{Row: 4, Col: 0})
+	g.Move(engine.WhiteQueen, engine.Cell{Row: 4, Col: 0})
+
+	text := Format(FromGame(g))
+	if !strings.Contains(text, `[FEN "5/5/5/5/5 PRBNQprbnq w ud 0 0 5x5+queen+discard"]`) || !strings.Contains(text, "1. Q@e5 R@a1 2. Qxa1 *") {
+		t.Errorf("unexpected record:\n%s", text)
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := Replay(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if engine.FormatFEN(replayed) != engine.FormatFEN(g) {
+		t.Errorf("replayed position %s, want %s", engine.FormatFEN(replayed), engine.FormatFEN(g))
+	}
+}
+
+func TestRoundTripResignation(t *testing.T) {
+	g := engine.NewGame()
+	g.Move(engine.WhiteRook, engine.Cell{Row: 0, Col: 0})
+	g.Resign(engine.Black)
+
+	text := Format(FromGame(g))
+	if !strings.Contains(text, `[Termination "resignation"]`) || !strings.Contains(text, "1. R@a4 1-0") {
+		t.Errorf("unexpected record:\n%s", text)
+	}
+	if strings.Contains(text, "[FEN") {
+		t.Errorf("expected no FEN tag for a game from the initial position:\n%s", text)
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := Replay(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Termination != engine.Resignation || replayed.Winner == nil || *replayed.Winner != engine.White {
+		t.Errorf("expected White to win by resignation, got %v winner %v", replayed.Termination, replayed.Winner)
+	}
+}
@@ -0,0 +1,53 @@
+package solver
+
+import (
+	"slices"
+	"tic-tac-chec/engine"
+)
+
+// Miss is a forced win a player let slip.
+type Miss struct {
+	// Ply is the index in the game history of the move played instead.
+	Ply    int
+	Played engine.Move
+	// Win is the forced win that was on the board before the move.
+	Win Result
+}
+
+// MissedWins replays the game and returns, in order, the moves after which
+// their player no longer had a forced win they had before, looking for wins
+// of at most maxPlies plies. It is meant for post-game feedback such as
+// "you missed a win in 3".
+func MissedWins(g *engine.Game, maxPlies int) ([]Miss, error) {
+	c := g.Snapshot().Game()
+	c.Reopen()
+
+	var misses []Miss
+	for len(c.History) > 0 {
+		played, _ := c.LastMove()
+		after := c.Position()
+		if err := c.Undo(); err != nil {
+			return nil, err
+		}
+
+		before, err := Solve(c, maxPlies)
+		if err != nil {
+			return nil, err
+		}
+		if before.Win && !keepsWin(&after, played.Piece.Color, before.Plies) {
+			misses = append(misses, Miss{Ply: len(c.History), Played: played, Win: before})
+		}
+	}
+
+	slices.Reverse(misses)
+	return misses, nil
+}
+
+// keepsWin reports whether color, having just moved to after, still wins
+// within the rest of a forced win of plies plies.
+func keepsWin(after *engine.Position, color engine.Color, plies int) bool {
+	if after.HasFourInARow(color) {
+		return true
+	}
+	return plies > 1 && newSolver(plies).forced(after, plies-1, 0)
+}
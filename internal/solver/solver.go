@@ -0,0 +1,282 @@
+// Package solver proves forced wins: whether the side to move can complete
+// a line within a number of plies whatever the opponent plays. It is exact,
+// unlike the evaluation-driven search bots, so it serves as ground truth for
+// endgames, puzzles and post-game feedback.
+//
+// Draws by repetition or by the no-progress rule are not considered: within
+// the short horizons the solver is meant for they cannot save the defender.
+package solver
+
+import (
+	"cmp"
+	"slices"
+	"tic-tac-chec/engine"
+)
+
+// Result is the outcome of Solve.
+type Result struct {
+	Win bool
+	// Plies is the length of the shortest forced win, counting the moves of
+	// both sides: 1 wins on the spot, 3 wins on the mover's second move.
+	Plies int
+	// Line is the winning line of play: the winner's moves and the
+	// opponent's longest defense, ending with the move that completes the line.
+	Line  []engine.Move
+	Nodes uint64
+}
+
+// Moves returns the number of moves of the winner in the forced win,
+// the N of mate-in-N.
+func (r Result) Moves() int {
+	return (r.Plies + 1) / 2
+}
+
+// Solve looks for a forced win of the side to move within maxPlies plies,
+// trying the shortest wins first. It fails with engine.ErrGameOver if the
+// game is over.
+func Solve(g *engine.Game, maxPlies int) (Result, error) {
+	if g.Status == engine.GameOver {
+		return Result{}, engine.ErrGameOver
+	}
+
+	p := g.Position()
+	s := newSolver(maxPlies)
+	for plies := 1; plies <= maxPlies; plies += 2 {
+		if !s.win(&p, plies, 0) {
+			continue
+		}
+
+		line, err := replay(g, s.line(&p, plies, 0))
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Win: true, Plies: plies, Line: line, Nodes: s.nodes}, nil
+	}
+
+	return Result{Nodes: s.nodes}, nil
+}
+
+// MateIn looks for a forced win of the side to move within n of its moves.
+func MateIn(g *engine.Game, n int) (Result, error) {
+	return Solve(g, 2*n-1)
+}
+
+// bounds is what the solver knows about a position with the attacker to move.
+type bounds struct {
+	// proven is the fewest plies a win was proven within, 0 if none.
+	proven int
+	// disproven is the most plies a win was disproven within, 0 if none.
+	disproven int
+}
+
+type solver struct {
+	nodes uint64
+	// table is keyed on Key, not CanonicalKey: within the solver's horizons
+	// symmetric positions hardly ever meet, and mapping them costs a third
+	// more time for no fewer nodes.
+	table map[engine.Key]bounds
+	moves [][engine.MaxMoves]engine.PositionMove
+}
+
+func newSolver(maxPlies int) *solver {
+	return &solver{
+		table: make(map[engine.Key]bounds),
+		moves: make([][engine.MaxMoves]engine.PositionMove, max(maxPlies, 1)+1),
+	}
+}
+
+// win reports whether the side to move wins within plies plies, an odd
+// number. ply is the distance from the root.
+func (s *solver) win(p *engine.Position, plies, ply int) bool {
+	s.nodes++
+
+	key := p.Key()
+	b := s.table[key]
+	if b.proven != 0 && b.proven <= plies {
+		return true
+	}
+	if b.disproven >= plies {
+		return false
+	}
+
+	attacker := p.Turn
+	won := false
+	if reachable(p, attacker, plies) {
+		for _, m := range s.attacks(p, plies, ply) {
+			u := p.MakeMove(m)
+			won = p.HasFourInARow(attacker) || (plies > 1 && s.forced(p, plies-1, ply+1))
+			p.UnmakeMove(m, u)
+			if won {
+				break
+			}
+		}
+	}
+
+	if won {
+		b.proven = plies
+	} else {
+		b.disproven = plies
+	}
+	s.table[key] = b
+
+	return won
+}
+
+// forced reports whether every move of the side to move, the defender,
+// loses within plies plies, an even number.
+func (s *solver) forced(p *engine.Position, plies, ply int) bool {
+	s.nodes++
+
+	defender := p.Turn
+	defenses := s.defenses(p, ply)
+	if len(defenses) == 0 {
+		return false
+	}
+
+	for _, m := range defenses {
+		u := p.MakeMove(m)
+		lost := !p.HasFourInARow(defender) && s.win(p, plies-1, ply+1)
+		p.UnmakeMove(m, u)
+		if !lost {
+			return false
+		}
+	}
+
+	return true
+}
+
+// line returns a forced win of the side to move in exactly plies plies,
+// which must have been proven: the winner's fastest moves and the
+// defender's replies that hold out longest.
+func (s *solver) line(p *engine.Position, plies, ply int) []engine.PositionMove {
+	attacker := p.Turn
+
+	for _, m := range s.attacks(p, plies, ply) {
+		u := p.MakeMove(m)
+		if p.HasFourInARow(attacker) {
+			p.UnmakeMove(m, u)
+			return []engine.PositionMove{m}
+		}
+		if plies == 1 || !s.forced(p, plies-1, ply+1) {
+			p.UnmakeMove(m, u)
+			continue
+		}
+
+		reply, rest := s.longestDefense(p, plies-1, ply+1)
+		line := append([]engine.PositionMove{m, reply}, rest...)
+		p.UnmakeMove(m, u)
+		return line
+	}
+
+	return nil
+}
+
+// longestDefense returns the defender's move that delays the loss the most,
+// and the rest of the winning line after it.
+func (s *solver) longestDefense(p *engine.Position, plies, ply int) (engine.PositionMove, []engine.PositionMove) {
+	var (
+		best     engine.PositionMove
+		bestLine []engine.PositionMove
+		longest  int
+	)
+
+	// defenses reuses the buffer of this ply, so the moves are copied first
+	defenses := slices.Clone(s.defenses(p, ply))
+	for _, m := range defenses {
+		u := p.MakeMove(m)
+		// the loss is proven within plies-1, so the loop ends there
+		remaining := 1
+		for remaining < plies-1 && !s.win(p, remaining, ply+1) {
+			remaining += 2
+		}
+		if remaining > longest {
+			best, longest = m, remaining
+			bestLine = s.line(p, remaining, ply+1)
+		}
+		p.UnmakeMove(m, u)
+	}
+
+	return best, bestLine
+}
+
+// attacks returns the moves of the attacker, those onto cells that
+// complete a line first, then captures. With a single ply left only the
+// moves onto such cells can win.
+func (s *solver) attacks(p *engine.Position, plies, ply int) []engine.PositionMove {
+	moves := p.GenerateMoves(s.moves[ply][:0])
+	wins := gaps(p, p.Turn)
+
+	if plies == 1 {
+		return slices.DeleteFunc(moves, func(m engine.PositionMove) bool {
+			return !wins.Has(m.To)
+		})
+	}
+
+	opponent := p.Occupied[1-p.Turn]
+	return sortBy(moves, func(m engine.PositionMove) int {
+		return boolRank(wins.Has(m.To))*2 + boolRank(opponent.Has(m.To))
+	})
+}
+
+// defenses returns the moves of the defender, those that stop a threat
+// first: moves onto the missing cell of an attacker's line and captures.
+func (s *solver) defenses(p *engine.Position, ply int) []engine.PositionMove {
+	moves := p.GenerateMoves(s.moves[ply][:0])
+	blocks := gaps(p, 1-p.Turn)
+	attacker := p.Occupied[1-p.Turn]
+
+	return sortBy(moves, func(m engine.PositionMove) int {
+		return boolRank(blocks.Has(m.To))*2 + boolRank(attacker.Has(m.To))
+	})
+}
+
+func sortBy(moves []engine.PositionMove, rank func(engine.PositionMove) int) []engine.PositionMove {
+	slices.SortStableFunc(moves, func(a, b engine.PositionMove) int {
+		return cmp.Compare(rank(b), rank(a))
+	})
+	return moves
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// reachable reports whether color could complete a line within plies
+// plies at all: each of its moves adds at most one cell to a line, and
+// the opponent's moves never do.
+func reachable(p *engine.Position, color engine.Color, plies int) bool {
+	moves := (plies + 1) / 2
+	for _, mask := range engine.WinMasks(p.Rules) {
+		if (mask &^ p.Occupied[color]).Count() <= moves {
+			return true
+		}
+	}
+	return false
+}
+
+// gaps returns the cells that complete a line of color: for every line
+// color holds all but one cell of, the missing cell.
+func gaps(p *engine.Position, color engine.Color) engine.Bitboard {
+	var cells engine.Bitboard
+	for _, mask := range engine.WinMasks(p.Rules) {
+		if missing := mask &^ p.Occupied[color]; missing.Count() == 1 {
+			cells |= missing
+		}
+	}
+	return cells
+}
+
+// replay plays the moves on a copy of the game and returns them as
+// recorded in its history.
+func replay(g *engine.Game, moves []engine.PositionMove) ([]engine.Move, error) {
+	c := g.Snapshot().Game()
+	for _, m := range moves {
+		if err := c.Move(engine.Piece{Color: c.Turn, Kind: m.Kind}, m.To.Cell()); err != nil {
+			return nil, err
+		}
+	}
+	return slices.Clone(c.History[len(g.History):]), nil
+}
@@ -0,0 +1,148 @@
+package solver
+
+import (
+	"errors"
+	"testing"
+	"tic-tac-chec/engine"
+)
+
+func gameFromFEN(t *testing.T, fen string) *engine.Game {
+	t.Helper()
+
+	g, err := engine.ParseFEN(fen)
+	if err != nil {
+		t.Fatalf("ParseFEN(%q): %v", fen, err)
+	}
+	return g
+}
+
+func TestSolveImmediateWin(t *testing.T) {
+	g := gameFromFEN(t, "4/4/4/PRB1 Nprbn w ud 0 4")
+
+	result, err := Solve(g, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Win || result.Plies != 1 || result.Moves() != 1 {
+		t.Fatalf("expected a win in 1 ply, got %+v", result)
+	}
+	if len(result.Line) != 1 || result.Line[0].Piece != engine.WhiteKnight || result.Line[0].To != (engine.Cell{Row: 3, Col: 3}) {
+		t.Errorf("expected N@d1, got %v", result.Line)
+	}
+}
+
+func TestSolveForcedWin(t *testing.T) {
+	// after a drop next to the rook and bishop, black cannot reach
+	// the last cell of the first rank
+	g := gameFromFEN(t, "br1n/1p2/4/RB2 PN w ud 0 8")
+
+	result, err := Solve(g, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Win || result.Plies != 3 || len(result.Line) != 3 {
+		t.Fatalf("expected a win in 3 plies, got %+v", result)
+	}
+
+	c := g.Snapshot().Game()
+	for _, m := range result.Line {
+		if err := c.Move(m.Piece, m.To); err != nil {
+			t.Fatalf("line %v: %v", result.Line, err)
+		}
+	}
+	if c.Status != engine.GameOver || c.Winner == nil || *c.Winner != engine.White {
+		t.Errorf("expected the line %v to win for white", result.Line)
+	}
+
+	mate, err := MateIn(g, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mate.Win || mate.Plies != 3 {
+		t.Errorf("expected mate in 2, got %+v", mate)
+	}
+	if mate, _ := MateIn(g, 1); mate.Win {
+		t.Errorf("expected no mate in 1, got %+v", mate)
+	}
+}
+
+func TestSolveLongestDefense(t *testing.T) {
+	// after Rb4xc4 black loses at once unless it drops on c3
+	g := gameFromFEN(t, "BRrp/1N2/4/1b1P n w ud 1 10")
+
+	result, err := Solve(g, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Win || result.Plies != 5 || len(result.Line) != 5 {
+		t.Fatalf("expected a win in 5 plies, got %+v", result)
+	}
+	if defense := result.Line[1]; defense.To != (engine.Cell{Row: 1, Col: 2}) {
+		t.Errorf("expected black to defend on c3, got %v", defense)
+	}
+}
+
+func TestSolveNoWin(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+	}{
+		{"initial position", "4/4/4/4 PRBNprbn w ud 0 0"},
+		{"threat of the opponent", "4/4/4/PRB1 Nprbn b ud 0 4"},
+		{"variant", "5/5/5/5/PR3 BNprbn b ud 0 4 5x5-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Solve(gameFromFEN(t, tt.fen), 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Win {
+				t.Errorf("expected no forced win, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestSolveVariant(t *testing.T) {
+	g := gameFromFEN(t, "5/5/5/5/P1R2 BNprbn w ud 0 4 5x5-3")
+
+	result, err := Solve(g, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Win || result.Plies != 1 || result.Line[0].To != (engine.Cell{Row: 4, Col: 1}) {
+		t.Errorf("expected a drop on b1 to win, got %+v", result)
+	}
+}
+
+func TestSolveRejectsFinishedGames(t *testing.T) {
+	g := gameFromFEN(t, "4/4/4/PRBN prbn b ud 0 4")
+
+	if _, err := Solve(g, 3); !errors.Is(err, engine.ErrGameOver) {
+		t.Errorf("expected ErrGameOver, got %v", err)
+	}
+}
+
+func TestMissedWins(t *testing.T) {
+	g := gameFromFEN(t, "4/4/4/PRB1 Nprbn w ud 0 4")
+	if err := g.Move(engine.WhiteKnight, engine.Cell{Row: 0, Col: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Move(engine.BlackKnight, engine.Cell{Row: 3, Col: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	misses, err := MissedWins(g, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(misses) != 1 {
+		t.Fatalf("expected 1 missed win, got %+v", misses)
+	}
+	miss := misses[0]
+	if miss.Ply != 0 || miss.Played.Piece != engine.WhiteKnight || miss.Win.Plies != 1 {
+		t.Errorf("expected N@a4 to miss a win in 1 ply, got %+v", miss)
+	}
+}
@@ -23,8 +23,6 @@ const (
 	PhaseWaiting
 )
 
-var Kinds = []engine.PieceKind{engine.Pawn, engine.Rook, engine.Bishop, engine.Knight}
-
 // layout holds computed parameters for flipped/normal board orientation.
 // All cursor movement and panel transitions use these instead of hardcoded directions.
 type layout struct {
@@ -32,8 +30,8 @@ type layout struct {
 	bottomColor engine.Color // which color's hand panel is on bottom
 	upDelta     int          // row delta when pressing "up" (-1 normal, +1 flipped)
 	downDelta   int          // row delta when pressing "down" (+1 normal, -1 flipped)
-	topRow      int          // engine row at visual top of board (0 normal, 3 flipped)
-	bottomRow   int          // engine row at visual bottom of board (3 normal, 0 flipped)
+	topRow      int          // engine row at visual top of board (0 normal, last flipped)
+	bottomRow   int          // engine row at visual bottom of board (last normal, 0 flipped)
 }
 
 type Model struct {
@@ -56,9 +54,11 @@ type Model struct {
 
 type Disconnected struct{}
 
-func InitialModel() Model {
+// InitialModel starts a local game under the given rules,
+// or engine.StandardRules if none are given.
+func InitialModel(rules ...engine.Rules) Model {
 	return Model{
-		Game:   engine.NewGame(),
+		Game:   engine.NewGame(rules...),
 		Cursor: NewCursor(),
 	}
 }
@@ -117,8 +117,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.nextCmd()
 
 	case game.SnapshotEvent:
-		game := msg.Game
-		m.Game = &game
+		m.Game = msg.Game.Game()
 		m.SelectedPiece = nil
 
 		m.resetCursor()
@@ -153,11 +152,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "n":
 			if m.gameOver() && !m.online() {
 				schemeIdx := m.SchemeIdx
-				m = InitialModel()
+				m = InitialModel(m.Game.Rules)
 				m.SchemeIdx = schemeIdx
 			}
 			return m, nil
 
+		case "u":
+			if !m.online() {
+				m.step(m.Game.Undo)
+			}
+			return m, nil
+
+		case "r":
+			if !m.online() {
+				m.step(m.Game.Redo)
+			}
+			return m, nil
+
 		case "c":
 			m.SchemeIdx = (m.SchemeIdx + 1) % len(ColorSchemes)
 			return m, nil
@@ -191,7 +202,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			} else {
 				if m.Game.Turn == lay.bottomColor {
-					m.Cursor.enterBoard(lay.bottomRow, *m.Cursor.PanelIndex)
+					m.Cursor.enterBoard(lay.bottomRow, min(*m.Cursor.PanelIndex, m.boardSize()-1))
 				}
 			}
 
@@ -208,12 +219,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			} else {
 				if m.Game.Turn == lay.topColor {
-					m.Cursor.enterBoard(lay.topRow, *m.Cursor.PanelIndex)
+					m.Cursor.enterBoard(lay.topRow, min(*m.Cursor.PanelIndex, m.boardSize()-1))
 				}
 			}
 
 		case "right", "l":
-			if m.Cursor.col() < engine.BoardSize-1 {
+			last := m.boardSize() - 1
+			if !m.cursorOnBoard() {
+				last = len(m.kinds()) - 1
+			}
+			if m.Cursor.col() < last {
 				m.Cursor.moveHorizontally(+1)
 			}
 
@@ -239,9 +254,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			} else { // cursor on hand panel
-				piece := m.Game.Pieces.Get(m.Game.Turn, Kinds[*m.Cursor.PanelIndex])
+				piece := m.Game.Pieces.Get(m.Game.Turn, m.kinds()[*m.Cursor.PanelIndex])
 
-				if piece != nil && piece.Color == m.Game.Turn && !m.Game.PieceOnBoard(*piece) {
+				if piece != nil && piece.Color == m.Game.Turn && m.Game.PieceInHand(*piece) {
 					m.SelectedPiece = piece
 				}
 			}
@@ -265,12 +280,21 @@ func (m *Model) executeMove(piece engine.Piece, cell engine.Cell) {
 	}
 }
 
+// step takes a move back or replays it (local games only).
+func (m *Model) step(step func() error) {
+	m.SelectedPiece = nil
+
+	if err := step(); err != nil {
+		m.LastErrorMessage = err.Error()
+	}
+	m.resetCursor()
+}
+
 func (m *Model) resetCursor() {
-	for i, kind := range Kinds {
+	for i, kind := range m.kinds() {
 		piece := m.Game.Pieces.Get(m.Game.Turn, kind)
-		_, onBoard := m.Game.Board.Find(piece)
 
-		if !onBoard {
+		if m.Game.PieceInHand(*piece) {
 			m.Cursor.enterPanel(i)
 			return
 		}
@@ -280,8 +304,8 @@ func (m *Model) resetCursor() {
 	// scanning from the player's bottom row upward.
 	lay := m.layout()
 	row := lay.bottomRow
-	for range engine.BoardSize {
-		for col := range engine.BoardSize {
+	for range m.boardSize() {
+		for col := range m.boardSize() {
 			p := m.Game.Board.At(engine.Cell{Row: row, Col: col})
 			if p != nil && p.Color == m.Game.Turn {
 				m.Cursor.enterBoard(row, col)
@@ -294,7 +318,7 @@ func (m *Model) resetCursor() {
 
 func (m *Model) pickUnusedPanelPiece() (int, bool) {
 	if m.cursorOnBoard() {
-		kind := Kinds[m.Cursor.col()]
+		kind := m.kinds()[min(m.Cursor.col(), len(m.kinds())-1)]
 		piece := m.Game.Pieces.Get(m.Game.Turn, kind)
 
 		if m.Game.PieceInHand(*piece) {
@@ -302,7 +326,7 @@ func (m *Model) pickUnusedPanelPiece() (int, bool) {
 		}
 	}
 
-	for col, kind := range Kinds {
+	for col, kind := range m.kinds() {
 		piece := m.Game.Pieces.Get(m.Game.Turn, kind)
 
 		if m.Game.PieceInHand(*piece) {
@@ -313,6 +337,15 @@ func (m *Model) pickUnusedPanelPiece() (int, bool) {
 	return 0, false
 }
 
+// kinds are the piece kinds in play, in hand panel order.
+func (m *Model) kinds() []engine.PieceKind {
+	return m.Game.Rules.Kinds()
+}
+
+func (m *Model) boardSize() int {
+	return m.Game.Rules.Size()
+}
+
 func (m *Model) gameOver() bool {
 	return m.Game != nil && m.Game.Status == engine.GameOver
 }
@@ -357,7 +390,7 @@ func (m *Model) layout() layout {
 			bottomColor: engine.Black,
 			upDelta:     +1,
 			downDelta:   -1,
-			topRow:      engine.BoardSize - 1,
+			topRow:      m.boardSize() - 1,
 			bottomRow:   0,
 		}
 	}
@@ -369,6 +402,6 @@ func (m *Model) layout() layout {
 		upDelta:     -1,
 		downDelta:   +1,
 		topRow:      0,
-		bottomRow:   engine.BoardSize - 1,
+		bottomRow:   m.boardSize() - 1,
 	}
 }
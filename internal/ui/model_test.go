@@ -5,6 +5,7 @@ import (
 	"tic-tac-chec/engine"
 	"tic-tac-chec/internal/game"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"go.uber.org/goleak"
 )
 
@@ -22,8 +23,8 @@ func TestExecuteMoveOnline_ReturnsResponse(t *testing.T) {
 
 	// fake Room.Run goroutine, in order to process move
 	go func() {
-		<-commands                                             // read command (moves/rematch)
-		updates <- game.SnapshotEvent{Game: *engine.NewGame()} // send some state back
+		<-commands                                                       // read command (moves/rematch)
+		updates <- game.SnapshotEvent{Game: engine.NewGame().Snapshot()} // send some state back
 	}()
 
 	piece := engine.WhiteBishop
@@ -36,3 +37,53 @@ func TestExecuteMoveOnline_ReturnsResponse(t *testing.T) {
 		t.Errorf("expected game.SnapshotEvent, got %T", msg)
 	}
 }
+
+func TestUndoRedoInLocalMode(t *testing.T) {
+	model := InitialModel()
+	model.Mode = ModeLocal
+
+	model.executeMove(engine.WhiteBishop, engine.Cell{Row: 0, Col: 0})
+	if model.Game.Turn != engine.Black {
+		t.Fatalf("expected black's turn after the move, got %v", model.Game.Turn)
+	}
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	model = updated.(Model)
+	if model.Game.Turn != engine.White || !model.Game.PieceInHand(engine.WhiteBishop) {
+		t.Fatal("expected undo to take the white bishop back to hand")
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	model = updated.(Model)
+	if !model.Game.PieceOnBoard(engine.WhiteBishop) {
+		t.Fatal("expected redo to put the white bishop back on the board")
+	}
+}
+
+func TestVariantHandPanelWiderThanBoard(t *testing.T) {
+	model := InitialModel(engine.Rules{Queen: true})
+	model.Mode = ModeLocal
+
+	press := func(key string) {
+		updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		model = updated.(Model)
+	}
+
+	// the hand panel holds five kinds, one more than the board has columns
+	for range 5 {
+		press("l")
+	}
+	if *model.Cursor.PanelIndex != 4 {
+		t.Fatalf("expected the cursor on the queen, got panel index %d", *model.Cursor.PanelIndex)
+	}
+
+	press("enter")
+	if model.SelectedPiece == nil || *model.SelectedPiece != engine.WhiteQueen {
+		t.Fatalf("expected the white queen to be selected, got %v", model.SelectedPiece)
+	}
+
+	press("k")
+	if !model.cursorOnBoard() || model.Cursor.col() != engine.BoardSize-1 {
+		t.Fatalf("expected the cursor on the last board column, got %v", model.Cursor.BoardCursor)
+	}
+}
@@ -2,6 +2,8 @@ package ui
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"tic-tac-chec/engine"
 
@@ -35,6 +37,7 @@ var (
 	borderDimmed   = lipgloss.Color("238")
 	borderHovered  = lipgloss.Color("15")
 	borderSelected = lipgloss.Color("11")
+	borderTarget   = lipgloss.Color("10")
 )
 
 var baseCellStyle = lipgloss.NewStyle().
@@ -47,12 +50,12 @@ var fixedLine = lipgloss.NewStyle().Height(1)
 
 const rowLabelWidth = 3
 
-func letterMarkers() string {
+func letterMarkers(size int) string {
 	cellW := lipgloss.Width(baseCellStyle.Render(" "))
 	letterStyle := lipgloss.NewStyle().Width(cellW).Align(lipgloss.Center)
-	labels := make([]string, engine.BoardSize)
-	for i, l := range []string{"a", "b", "c", "d"} {
-		labels[i] = letterStyle.Render(l)
+	labels := make([]string, size)
+	for i := range size {
+		labels[i] = letterStyle.Render(string(rune('a' + i)))
 	}
 	return fmt.Sprintf("%*s", rowLabelWidth, "") + lipgloss.JoinHorizontal(lipgloss.Top, labels...)
 }
@@ -74,28 +77,48 @@ func rightLabel(n string) string {
 		Render(n)
 }
 
-func rulesView() string {
-	return `
-  Tic Tac Chec — Rules
+func rulesView(rules engine.Rules) string {
+	size := rules.Size()
 
-  4×4 board, 2 players (White / Black)
-  Each player has: Pawn, Rook, Bishop, Knight
+	var kinds []string
+	for _, kind := range rules.Kinds() {
+		kinds = append(kinds, kind.Name())
+	}
+
+	captures := "Captures return the piece to its owner's hand (shogi-style)"
+	if rules.DiscardCaptures {
+		captures = "Captured pieces leave the game for good"
+	}
+	pawns := "Pawns reverse direction at the far edge (no promotion)"
+	if rules.KeepPawnDirection {
+		pawns = "Pawns never turn around (no promotion)"
+	}
+
+	return fmt.Sprintf(`
+  Tic Tac Chec — Rules (%s)
+
+  %d×%d board, 2 players (White / Black)
+  Each player has: %s
 
   On your turn, either:
     • Place a piece from your hand onto any empty cell
     • Move a piece already on the board (chess rules)
 
-  Captures return the piece to its owner's hand (shogi-style)
-  Pawns reverse direction at the far edge (no promotion)
+  %s
+  %s
 
-  Win: get 4 of your color in a row
+  Win: get %d of your color in a row
        (horizontal, vertical, or diagonal)
+`, rules, size, size, strings.Join(kinds, ", "), captures, pawns, rules.LineLength()) + `
+  Draw: the same position occurs 3 times, or
+        50 moves pass without a placement or capture
 
   Controls:
     arrows/hjkl  Move cursor
     enter/space  Select piece / confirm move
     c            Change color scheme
     n            New game (local only)
+    u / r        Undo / redo a move (local only)
     ?            Toggle this screen
     q            Quit
 
@@ -109,10 +132,13 @@ func turnIndicator(m Model) string {
 
 	if m.gameOver() {
 		if m.draw() {
-			return style.Render("Draw!")
+			return style.Render("Draw by " + m.Game.Termination.String() + "!")
 		}
 
 		style = style.Foreground(toLipglossColor(scheme, *m.winner()))
+		if m.Game.Termination != engine.FourInARow {
+			return style.Render(colorName(*m.winner()) + " wins by " + m.Game.Termination.String() + "!")
+		}
 		return style.Render(colorName(*m.winner()) + " wins!")
 	}
 
@@ -131,14 +157,14 @@ func turnIndicator(m Model) string {
 
 func (m Model) View() string {
 	if m.ShowRules {
-		return rulesView()
+		return rulesView(m.Game.Rules)
 	}
 
 	if m.Phase == PhaseWaiting {
 		return "\n  Waiting for opponent...\n\n  Press ? for rules, q to quit\n"
 	}
 
-	bw := rowLabelWidth + lipgloss.Width(baseCellStyle.Render(" "))*engine.BoardSize + 2
+	bw := rowLabelWidth + lipgloss.Width(baseCellStyle.Render(" "))*m.boardSize() + 2
 	title := lipgloss.NewStyle().Width(bw).Align(lipgloss.Center).Render("Tic Tac Chec")
 
 	turnLine := lipgloss.NewStyle().Width(bw).Align(lipgloss.Center).Render(turnIndicator(m))
@@ -170,27 +196,28 @@ func colorName(c engine.Color) string {
 
 func boardView(m Model) string {
 	flipped := m.shouldFlip()
-	parts := []string{letterMarkers()}
+	size := m.boardSize()
+	parts := []string{letterMarkers(size)}
 
-	for i := range engine.BoardSize {
+	for i := range size {
 		engineRow := i
 		if flipped {
-			engineRow = engine.BoardSize - 1 - i
+			engineRow = size - 1 - i
 		}
 		cells := rowCells(m, engineRow)
-		num := fmt.Sprintf("%d", engine.BoardSize-engineRow)
+		num := fmt.Sprintf("%d", size-engineRow)
 		cellsRow := lipgloss.JoinHorizontal(lipgloss.Top, cells...)
 		parts = append(parts, lipgloss.JoinHorizontal(lipgloss.Top, leftLabel(num), cellsRow, rightLabel(num)))
 	}
 
-	parts = append(parts, letterMarkers())
+	parts = append(parts, letterMarkers(size))
 	return lipgloss.JoinVertical(lipgloss.Left, parts...)
 }
 
 func rowCells(m Model, row int) []string {
-	cells := make([]string, engine.BoardSize)
+	cells := make([]string, m.boardSize())
 
-	for col := range engine.BoardSize {
+	for col := range m.boardSize() {
 		style := baseCellStyle.BorderForeground(cellBorderColor(m, row, col))
 		cells[col] = style.Render(pieceView(m.Game.Board[row][col], m.colorScheme()))
 	}
@@ -200,15 +227,14 @@ func rowCells(m Model, row int) []string {
 
 func handPanel(m Model, color engine.Color) string {
 	game := m.Game
-	handCells := make([]string, len(Kinds))
+	handCells := make([]string, len(m.kinds()))
 
-	for col, kind := range Kinds {
+	for col, kind := range m.kinds() {
 		style := baseCellStyle.BorderForeground(handPieceBorderColor(m, color, col))
 		piece := game.Piece(engine.Piece{Color: color, Kind: kind})
-		onBoard := game.PieceOnBoard(*piece)
 
 		pieceStr := " "
-		if !onBoard {
+		if game.PieceInHand(*piece) {
 			pieceStr = pieceView(piece, m.colorScheme())
 		}
 
@@ -232,6 +258,8 @@ func pieceView(piece *engine.Piece, scheme ColorScheme) string {
 		symbol = "♝"
 	case engine.Knight:
 		symbol = "♞"
+	case engine.Queen:
+		symbol = "♛"
 	default:
 		symbol = "?"
 	}
@@ -256,12 +284,16 @@ func showCursor(m Model) bool {
 }
 
 func cellBorderColor(m Model, row, col int) lipgloss.Color {
+	if m.gameOver() && slices.Contains(m.Game.WinningLine, engine.Cell{Row: row, Col: col}) {
+		return borderSelected
+	}
+
 	if !showCursor(m) {
 		return borderDimmed
 	}
 
 	if m.SelectedPiece != nil {
-		selectedCell, selectedOnBoard := m.Game.Board.Find(m.SelectedPiece)
+		selectedCell, selectedOnBoard := m.Game.Find(*m.SelectedPiece)
 
 		if selectedOnBoard && selectedCell.Row == row && selectedCell.Col == col {
 			return borderSelected
@@ -272,15 +304,30 @@ func cellBorderColor(m Model, row, col int) lipgloss.Color {
 		return borderHovered
 	}
 
+	if m.SelectedPiece != nil && isTarget(m, engine.Cell{Row: row, Col: col}) {
+		return borderTarget
+	}
+
 	return borderDimmed
 }
 
+// isTarget reports whether the selected piece can legally move or be dropped on the cell.
+func isTarget(m Model, cell engine.Cell) bool {
+	for _, move := range m.Game.AllLegalMoves() {
+		if move.Piece == *m.SelectedPiece && move.To == cell {
+			return true
+		}
+	}
+
+	return false
+}
+
 func handPieceBorderColor(m Model, handColor engine.Color, pos int) lipgloss.Color {
 	if !showCursor(m) {
 		return borderDimmed
 	}
 
-	piece := m.Game.Pieces.Get(handColor, Kinds[pos])
+	piece := m.Game.Pieces.Get(handColor, m.kinds()[pos])
 	inHand := m.Game.PieceInHand(*piece)
 
 	activeHand := m.Game.Turn == handColor
@@ -164,3 +164,28 @@ func TestShowLocalCursorFalseInOnlineModeWhenNotMyTurn(t *testing.T) {
 		t.Errorf("expected showCursor to be true, got false")
 	}
 }
+
+func TestCellBorderHighlightsTargetsOfSelectedPiece(t *testing.T) {
+	m := InitialModel()
+	m.Mode = ModeLocal
+
+	wr := m.Game.Pieces.Get(engine.White, engine.Rook)
+	bp := m.Game.Pieces.Get(engine.Black, engine.Pawn)
+	m.Game.Board[3][0] = wr
+	m.Game.Board[1][0] = bp
+	m.SelectedPiece = wr
+	m.Cursor.enterBoard(3, 0)
+
+	if got := cellBorderColor(m, 2, 0); got != borderTarget {
+		t.Errorf("expected empty target cell to be highlighted, got %v", got)
+	}
+	if got := cellBorderColor(m, 1, 0); got != borderTarget {
+		t.Errorf("expected capture cell to be highlighted, got %v", got)
+	}
+	if got := cellBorderColor(m, 0, 0); got != borderDimmed {
+		t.Errorf("expected cell behind the pawn not to be highlighted, got %v", got)
+	}
+	if got := cellBorderColor(m, 2, 1); got != borderDimmed {
+		t.Errorf("expected diagonal cell not to be highlighted, got %v", got)
+	}
+}
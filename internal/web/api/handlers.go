@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"tic-tac-chec/engine"
 	"tic-tac-chec/internal/game"
 	"tic-tac-chec/internal/web/bots"
 	"tic-tac-chec/internal/web/clients"
@@ -35,11 +36,19 @@ func (a *API) Me(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(clientResponse{Token: string(client.ID)})
 }
 
+// CreateLobby opens a private lobby. The optional "rules" query parameter
+// picks a variant in engine.Rules notation, e.g. "5x5-4+queen".
 func (a *API) CreateLobby(w http.ResponseWriter, r *http.Request) {
-	lobby := a.lobbyRegistry.Create()
+	rules, err := engine.ParseRules(r.URL.Query().Get("rules"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lobby := a.lobbyRegistry.Create(rules)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(lobbyResponse{ID: string(lobby.ID)})
+	json.NewEncoder(w).Encode(lobbyResponse{ID: string(lobby.ID), Rules: lobby.Rules.String()})
 }
 
 func (a *API) BotGame(w http.ResponseWriter, r *http.Request) {
@@ -70,13 +79,22 @@ func (a *API) BotGame(w http.ResponseWriter, r *http.Request) {
 	humanCommands := make(chan game.Command)
 	humanPlayer := game.NewPlayerWithID(humanCommands, client.PlayerID)
 
-	botPlayer := bot.Model.RunPlayer(bot.Info.PlayerID)
+	// the adaptive bot plays at the human's rating and follows the room's
+	// games to keep up with it
+	botPlayer, adaptive, err := bot.Seat(r.Context(), a.db, client.PlayerID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	entry := a.roomRegistry.CreateWithPlayers(
 		humanPlayer, botPlayer, [2]clients.ClientID{client.ID, clients.BotClientID},
 	)
 
 	persistor.Run(a.db.Games(), entry.Room)
+	if adaptive != nil {
+		adaptive.Watch(entry.Room)
+	}
 	go entry.Room.Run()
 
 	w.Header().Set("Content-Type", "application/json")
@@ -168,16 +186,18 @@ func (a *API) Room(w http.ResponseWriter, r *http.Request) {
 	ws.ServeRoom(r.Context(), sock, roomEntry.Room, participant)
 }
 
-// pickBot returns the bot for the requested difficulty.
-// Falls back to: requested → "hard" → "medium" → "easy" → any available.
+// pickBot returns the bot for the requested difficulty, "adaptive"
+// included. Falls back to: requested → "hard" → "medium" → "easy" → any available,
+// trying the search bot of each ("search-hard", ...) when the ONNX one
+// is not loaded.
 func (a *API) pickBot(difficulty string) *bots.Bot {
-	if b, ok := a.bots[difficulty]; ok {
-		return b
-	}
-	for _, name := range []string{"hard", "medium", "easy"} {
+	for _, name := range []string{difficulty, "hard", "medium", "easy"} {
 		if b, ok := a.bots[name]; ok {
 			return b
 		}
+		if b, ok := a.bots["search-"+name]; ok {
+			return b
+		}
 	}
 	// Return any available bot
 	for _, b := range a.bots {
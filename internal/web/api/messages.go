@@ -5,5 +5,6 @@ type clientResponse struct {
 }
 
 type lobbyResponse struct {
-	ID string `json:"id"`
+	ID    string `json:"id"`
+	Rules string `json:"rules"`
 }
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"tic-tac-chec/internal/game"
 	"tic-tac-chec/internal/web/api"
@@ -27,13 +28,16 @@ type App struct {
 
 func NewApp(ctx context.Context, db *store.Store, cfg config.Config) *App {
 	bb := bots.Init(ctx, db, *cfg.Bots)
-	spawnBot := func(botID string) (game.Player, bool) {
-		for _, bot := range bb {
-			if bot.Info.ID == botID {
-				return bot.Model.RunPlayer(bot.Info.PlayerID), true
-			}
+	spawnBot := func(ctx context.Context, botID, humanID string) (game.Player, func(*game.Room), error) {
+		bot, ok := bb.ByID(botID)
+		if !ok {
+			return game.Player{}, nil, fmt.Errorf("bot %s is not available", botID)
 		}
-		return game.Player{}, false
+		player, adaptive, err := bot.Seat(ctx, db, humanID)
+		if err != nil || adaptive == nil {
+			return player, nil, err
+		}
+		return player, adaptive.Watch, nil
 	}
 
 	roomRegistry := room.NewRegistry(db.Games(), db.Players(), spawnBot)
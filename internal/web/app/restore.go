@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"tic-tac-chec/engine"
 	"tic-tac-chec/internal/game"
+	"tic-tac-chec/internal/web/bots"
 	"tic-tac-chec/internal/web/clients"
 	store "tic-tac-chec/internal/web/persistence/sqlite"
 	"tic-tac-chec/internal/web/persistor"
@@ -23,25 +24,32 @@ func (a *App) buildRoomFromGame(ctx context.Context, g store.Game) (room.Entry,
 		return room.Entry{}, room.ErrRoomNotFound
 	}
 
-	gamePlayerWhite, clientWhite, err := a.playerFor(whitePlayer)
+	gamePlayerWhite, clientWhite, adaptiveWhite, err := a.playerFor(ctx, whitePlayer, blackPlayer)
 	if err != nil {
 		return room.Entry{}, room.ErrRoomNotFound
 	}
-	gamePlayerBlack, clientBlack, err := a.playerFor(blackPlayer)
+	gamePlayerBlack, clientBlack, adaptiveBlack, err := a.playerFor(ctx, blackPlayer, whitePlayer)
 	if err != nil {
 		return room.Entry{}, room.ErrRoomNotFound
 	}
 
-	var gameState engine.Game
+	var gameState engine.Snapshot
 	err = json.Unmarshal(g.State, &gameState)
 	if err != nil {
 		return room.Entry{}, room.ErrRoomNotFound
 	}
 
-	r := game.NewRoom(gamePlayerWhite, gamePlayerBlack)
+	r := game.NewRoom(gamePlayerWhite, gamePlayerBlack, gameState.Rules())
 	r.ID = game.RoomID(g.RoomID)
 	r.GameID = game.GameID(g.ID)
-	r.Game = &gameState
+	r.Game = gameState.Game()
+	r.AbandonAfter = room.AbandonAfter
+
+	for _, adaptive := range []*bots.AdaptiveGame{adaptiveWhite, adaptiveBlack} {
+		if adaptive != nil {
+			adaptive.Watch(r)
+		}
+	}
 
 	entry := room.Entry{
 		Room: r,
@@ -53,15 +61,22 @@ func (a *App) buildRoomFromGame(ctx context.Context, g store.Game) (room.Entry,
 	return entry, nil
 }
 
-func (a *App) playerFor(p store.Player) (game.Player, clients.ClientID, error) {
+// playerFor restores the room player of p, playing against opponent.
+// Adaptive bots come back at the opponent's rating and are returned to
+// watch the room with.
+func (a *App) playerFor(ctx context.Context, p, opponent store.Player) (game.Player, clients.ClientID, *bots.AdaptiveGame, error) {
 	switch {
 	case p.BotID != nil:
-		player, ok := a.spawnBot(*p.BotID)
+		b, ok := a.bots.ByID(*p.BotID)
 		if !ok {
-			return game.Player{}, "", fmt.Errorf("bot %s is not available", *p.BotID)
+			return game.Player{}, "", nil, fmt.Errorf("bot %s is not available", *p.BotID)
+		}
+		player, adaptive, err := b.Seat(ctx, a.db, opponent.ID)
+		if err != nil {
+			return game.Player{}, "", nil, err
 		}
 
-		return player, clients.BotClientID, nil
+		return player, clients.BotClientID, adaptive, nil
 	case p.UserID != nil:
 		player := game.Player{
 			ID:              game.PlayerID(p.ID),
@@ -72,19 +87,10 @@ func (a *App) playerFor(p store.Player) (game.Player, clients.ClientID, error) {
 			Color:    engine.Color(0),
 		}
 
-		return player, clients.ClientID(*p.UserID), nil
+		return player, clients.ClientID(*p.UserID), nil, nil
 	default:
-		return game.Player{}, "", fmt.Errorf("player neither bot nor user")
-	}
-}
-
-func (a *App) spawnBot(botID string) (game.Player, bool) {
-	for _, bot := range a.bots {
-		if bot.Info.ID == botID {
-			return bot.Model.RunPlayer(bot.Info.PlayerID), true
-		}
+		return game.Player{}, "", nil, fmt.Errorf("player neither bot nor user")
 	}
-	return game.Player{}, false
 }
 
 func (a *App) restoreActiveGames(ctx context.Context) {
@@ -0,0 +1,278 @@
+package bots
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/bot"
+	"tic-tac-chec/internal/bot/player"
+	"tic-tac-chec/internal/game"
+	store "tic-tac-chec/internal/web/persistence/sqlite"
+)
+
+// Adaptive is the difficulty of the bot that plays at the strength of its
+// opponent, see Bot.Adapt.
+const Adaptive = "adaptive"
+
+const (
+	// initialRating is the rating of a player without results against
+	// rated bots: between easy and medium.
+	initialRating = 1000
+	// maxK and minK bound how far a game moves a rating: far while the
+	// rating is new, less once it rests on many games.
+	maxK = 64
+	minK = 24
+)
+
+// rung is a strength the adaptive bot can play at, after one of the
+// difficulties of the bots table where there is one. Between rungs the
+// settings are interpolated.
+type rung struct {
+	rating      float64
+	simulations int
+	temperature float64
+	blunder     float64
+	depth       int
+}
+
+var ladder = []rung{
+	{rating: 600, simulations: 0, temperature: 1.5, blunder: 0.3, depth: 3},
+	{rating: 800, simulations: 0, temperature: 1, blunder: 0.1, depth: 3}, // easy
+	{rating: 1000, simulations: 30, temperature: 0.75, blunder: 0.08, depth: 2},
+	{rating: 1200, simulations: 100, temperature: 0.5, blunder: 0.05, depth: 2}, // medium
+	{rating: 1400, simulations: 250, temperature: 0.25, blunder: 0.02, depth: 2},
+	{rating: 1600, simulations: 500, temperature: 0, blunder: 0, depth: 1}, // hard
+	{rating: 1800, simulations: 1000, temperature: 0, blunder: 0, depth: 1},
+}
+
+// optionsFor returns base with the simulations, temperature and blunders
+// of the ladder at the given rating.
+func optionsFor(base bot.SearchOptions, rating float64) bot.SearchOptions {
+	lower, upper := ladder[0], ladder[len(ladder)-1]
+	for i := 1; i < len(ladder); i++ {
+		if rating <= ladder[i].rating {
+			lower, upper = ladder[i-1], ladder[i]
+			break
+		}
+	}
+
+	t := (rating - lower.rating) / (upper.rating - lower.rating)
+	t = min(max(t, 0), 1)
+	lerp := func(a, b float64) float64 { return a + t*(b-a) }
+
+	opts := base
+	opts.Simulations = int(math.Round(lerp(float64(lower.simulations), float64(upper.simulations))))
+	opts.Temperature = lerp(lower.temperature, upper.temperature)
+	opts.BlunderChance = lerp(lower.blunder, upper.blunder)
+	opts.BlunderDepth = int(math.Round(lerp(float64(lower.depth), float64(upper.depth))))
+	if opts.Simulations == 0 {
+		// greedy, as the think time alone would make it search
+		opts.ThinkTime = 0
+	}
+	return opts
+}
+
+// expectedScore returns the score a player of the given rating expects
+// against an opponent of the other, from 0 to 1.
+func expectedScore(rating, opponent float64) float64 {
+	return 1 / (1 + math.Pow(10, (opponent-rating)/400))
+}
+
+// rate returns the rating after a game with the given score against an
+// opponent, from a rating resting on the given number of games.
+func rate(rating float64, games int, opponent, score float64) float64 {
+	k := max(minK, maxK/(1+float64(games)/10))
+	return rating + k*(score-expectedScore(rating, opponent))
+}
+
+// AdaptiveGame is the adaptive bot of a room: it plays at the human's
+// rating, and moves the rating after every game of the room.
+type AdaptiveGame struct {
+	base    bot.SearchOptions
+	eval    bot.Evaluator
+	botID   string
+	humanID string
+	ratings *store.RatingStore
+
+	mu     sync.Mutex
+	rating store.Rating
+	model  *bot.Model
+}
+
+// Adapt prepares the bot to play the human player: it loads the player's
+// rating, or estimates it from their results against rated bots, and sets
+// its strength to match. Run the bot with RunPlayer and let it follow the
+// room with Watch. It fails for bots that are not adaptive.
+func (b *Bot) Adapt(ctx context.Context, db *store.Store, humanID string) (*AdaptiveGame, error) {
+	if b.Info.Difficulty != Adaptive || b.eval == nil {
+		return nil, errors.New("bots: " + b.Info.Difficulty + " bot is not adaptive")
+	}
+
+	rating, err := db.Ratings().Get(ctx, humanID)
+	if errors.Is(err, store.ErrNotFound) {
+		rating, err = estimateRating(ctx, db.Games(), humanID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a := &AdaptiveGame{
+		base:    searchOptions(b.Info),
+		eval:    b.eval,
+		botID:   b.Info.PlayerID,
+		humanID: humanID,
+		ratings: db.Ratings(),
+		rating:  rating,
+	}
+	a.model = bot.NewWithEvaluator(a.eval, optionsFor(a.base, rating.Rating))
+
+	return a, nil
+}
+
+// Seat creates the bot's player for a room against the human with the given
+// player ID. The adaptive bot plays at the human's rating, see Adapt, and is
+// returned to Watch the room with; it is nil for the other bots.
+func (b *Bot) Seat(ctx context.Context, db *store.Store, humanID string) (game.Player, *AdaptiveGame, error) {
+	if b.Info.Difficulty != Adaptive {
+		return b.Model.RunPlayer(b.Info.PlayerID), nil, nil
+	}
+
+	a, err := b.Adapt(ctx, db, humanID)
+	if err != nil {
+		return game.Player{}, nil, err
+	}
+	return a.RunPlayer(), a, nil
+}
+
+// estimateRating rates the player by replaying their results against rated
+// bots from the initial rating.
+func estimateRating(ctx context.Context, games *store.GameStore, humanID string) (store.Rating, error) {
+	results, err := games.LoadBotResults(ctx, humanID)
+	if err != nil {
+		return store.Rating{}, err
+	}
+
+	rating := store.Rating{PlayerID: humanID, Rating: initialRating}
+	for _, r := range results {
+		rating.Rating = rate(rating.Rating, rating.Games, float64(r.BotRating), r.Score)
+		rating.Games++
+	}
+	return rating, nil
+}
+
+// Rating returns the human's current rating.
+func (a *AdaptiveGame) Rating() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.rating.Rating
+}
+
+// RunPlayer creates the bot's game.Player, see player.Run.
+func (a *AdaptiveGame) RunPlayer() game.Player {
+	return player.Run(a, a.botID)
+}
+
+// SelectAction plays at the strength the bot was last set to.
+func (a *AdaptiveGame) SelectAction(g *engine.Game) (engine.Piece, engine.Cell, error) {
+	a.mu.Lock()
+	model := a.model
+	a.mu.Unlock()
+
+	return model.SelectAction(g)
+}
+
+// Watch follows the games of the room. When one ends it rates the human
+// against the strength the bot played at, stores the rating and sets the
+// bot to the new rating for the rematch. Games the human leaves unfinished,
+// for a rematch or by the room closing, count as losses.
+func (a *AdaptiveGame) Watch(room *game.Room) {
+	listener := make(chan game.RoomEvent, 32)
+	cancel := room.Subscribe(listener)
+
+	go func() {
+		defer cancel()
+		a.follow(listener)
+	}()
+}
+
+// played is what Watch keeps of a game of the room until it ends.
+type played struct {
+	human    engine.Color
+	opponent float64
+}
+
+func (a *AdaptiveGame) follow(listener <-chan game.RoomEvent) {
+	games := make(map[game.GameID]played)
+
+	for event := range listener {
+		switch e := event.(type) {
+		case game.GameStarted:
+			a.forfeit(games)
+
+			human := engine.White
+			if string(e.BlackPlayer) == a.humanID {
+				human = engine.Black
+			}
+			games[e.GameID] = played{human: human, opponent: a.Rating()}
+
+		case game.StateUpdate:
+			g, ok := games[e.GameID]
+			if !ok || e.Game.Status() != engine.GameOver {
+				continue
+			}
+			// the room sends the final state again when it closes
+			delete(games, e.GameID)
+
+			score := 0.5
+			if winner := e.Game.Winner(); winner != nil {
+				score = 0
+				if *winner == g.human {
+					score = 1
+				}
+			}
+			a.record(g.opponent, score)
+		}
+	}
+
+	// the room closed
+	a.forfeit(games)
+}
+
+// forfeit rates the games that were left before they ended as losses.
+func (a *AdaptiveGame) forfeit(games map[game.GameID]played) {
+	for id, g := range games {
+		delete(games, id)
+		a.record(g.opponent, 0)
+	}
+}
+
+// record rates the human's score against an opponent of the given rating,
+// stores the change and sets the bot to the stored rating, which includes
+// the games the human played in other rooms meanwhile.
+func (a *AdaptiveGame) record(opponent, score float64) {
+	a.mu.Lock()
+	from := a.rating
+	a.mu.Unlock()
+
+	to := from
+	to.Rating = rate(from.Rating, from.Games, opponent, score)
+	to.Games++
+	to.UpdatedAt = time.Now()
+
+	slog.Info("bots.adaptive_rated", "player_id", to.PlayerID, "rating", to.Rating, "games", to.Games)
+	stored, err := a.ratings.Save(context.Background(), from, to)
+	if err != nil {
+		slog.Error("bots.rating_save_failed", "player_id", to.PlayerID, "err", err)
+		stored = to
+	}
+
+	a.mu.Lock()
+	a.rating = stored
+	a.model = bot.NewWithEvaluator(a.eval, optionsFor(a.base, stored.Rating))
+	a.mu.Unlock()
+}
@@ -0,0 +1,275 @@
+package bots
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/bot"
+	"tic-tac-chec/internal/game"
+	store "tic-tac-chec/internal/web/persistence/sqlite"
+)
+
+const adaptivePlayerID = "0194c000-0000-7001-8000-000000000007"
+
+func TestOptionsFor(t *testing.T) {
+	base := bot.SearchOptions{Workers: 2, ThinkTime: 2 * time.Second, EarlyStop: true}
+
+	for _, tt := range []struct {
+		rating      float64
+		simulations int
+		temperature float64
+		blunder     float64
+		thinks      bool
+	}{
+		{rating: 0, simulations: 0, temperature: 1.5, blunder: 0.3},
+		{rating: 800, simulations: 0, temperature: 1, blunder: 0.1},
+		{rating: 1100, simulations: 65, temperature: 0.625, blunder: 0.065, thinks: true},
+		{rating: 1600, simulations: 500, temperature: 0, blunder: 0, thinks: true},
+		{rating: 2500, simulations: 1000, temperature: 0, blunder: 0, thinks: true},
+	} {
+		opts := optionsFor(base, tt.rating)
+		if opts.Simulations != tt.simulations || math.Abs(opts.Temperature-tt.temperature) > 1e-9 || math.Abs(opts.BlunderChance-tt.blunder) > 1e-9 {
+			t.Errorf("rating %v: expected %d simulations, temperature %v and blunders %v, got %+v",
+				tt.rating, tt.simulations, tt.temperature, tt.blunder, opts)
+		}
+		if thinks := opts.ThinkTime > 0; thinks != tt.thinks || opts.Workers != 2 {
+			t.Errorf("rating %v: expected the base options, thinking %v, got %+v", tt.rating, tt.thinks, opts)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	if got := rate(1000, 0, 1000, 1); got != 1032 {
+		t.Errorf("expected a new player's win against an equal to gain 32, got %v", got-1000)
+	}
+	if got := rate(1000, 90, 1000, 0); got != 988 {
+		t.Errorf("expected a settled player's loss against an equal to cost 12, got %v", got-1000)
+	}
+	if got := rate(1000, 0, 1200, 0.5); got <= 1000 {
+		t.Errorf("expected a draw against a stronger opponent to gain, got %v", got)
+	}
+}
+
+func TestAdaptiveFollowsGames(t *testing.T) {
+	db, err := store.NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	user, err := db.Users().Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &Bot{
+		Info: store.Bot{PlayerID: adaptivePlayerID, Difficulty: Adaptive},
+		eval: bot.NewRolloutEvaluator(0, 1),
+	}
+	a, err := b.Adapt(ctx, db, user.PlayerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Rating() != initialRating {
+		t.Fatalf("expected a player without results to start at %d, got %v", initialRating, a.Rating())
+	}
+
+	won := whiteWins(t)
+	events := make(chan game.RoomEvent, 8)
+	human, machine := game.PlayerID(user.PlayerID), game.PlayerID(adaptivePlayerID)
+	events <- game.NewGameStarted("room", "game-1", engine.NewGame().Snapshot(), 1, human, machine, time.Now())
+	events <- game.NewStateUpdate("room", "game-1", won, 1, time.Now())
+	// the room repeats the final state when it closes
+	events <- game.NewStateUpdate("room", "game-1", won, 1, time.Now())
+	events <- game.NewGameStarted("room", "game-2", engine.NewGame().Snapshot(), 2, machine, human, time.Now())
+	events <- game.NewStateUpdate("room", "game-2", won, 2, time.Now())
+	close(events)
+	a.follow(events)
+
+	// a win then a loss against the rating played at
+	want := rate(rate(initialRating, 0, initialRating, 1), 1, initialRating+32, 0)
+	if math.Abs(a.Rating()-want) > 1e-9 {
+		t.Errorf("expected a rating of %v, got %v", want, a.Rating())
+	}
+
+	stored, err := db.Ratings().Get(ctx, user.PlayerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Games != 2 || math.Abs(stored.Rating-want) > 1e-9 {
+		t.Errorf("expected a stored rating of %v over 2 games, got %v over %d", want, stored.Rating, stored.Games)
+	}
+
+	// the next room starts from the stored rating
+	next, err := b.Adapt(ctx, db, user.PlayerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Rating() != stored.Rating {
+		t.Errorf("expected the stored rating %v, got %v", stored.Rating, next.Rating())
+	}
+}
+
+func TestAdaptiveRatesUnfinishedGamesAsLosses(t *testing.T) {
+	db, err := store.NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	user, err := db.Users().Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &Bot{
+		Info: store.Bot{PlayerID: adaptivePlayerID, Difficulty: Adaptive},
+		eval: bot.NewRolloutEvaluator(0, 1),
+	}
+	a, err := b.Adapt(ctx, db, user.PlayerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan game.RoomEvent, 8)
+	human, machine := game.PlayerID(user.PlayerID), game.PlayerID(adaptivePlayerID)
+	unfinished := engine.NewGame().Snapshot()
+	events <- game.NewGameStarted("room", "game-1", unfinished, 1, human, machine, time.Now())
+	// a rematch before the first game ended
+	events <- game.NewGameStarted("room", "game-2", unfinished, 2, machine, human, time.Now())
+	// the room closes during the second
+	events <- game.NewStateUpdate("room", "game-2", unfinished, 2, time.Now())
+	close(events)
+	a.follow(events)
+
+	want := rate(rate(initialRating, 0, initialRating, 0), 1, initialRating-32, 0)
+	if math.Abs(a.Rating()-want) > 1e-9 {
+		t.Errorf("expected two losses to give a rating of %v, got %v", want, a.Rating())
+	}
+
+	stored, err := db.Ratings().Get(ctx, user.PlayerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Games != 2 {
+		t.Errorf("expected 2 rated games, got %d", stored.Games)
+	}
+}
+
+func TestAdaptiveRoomsAddUp(t *testing.T) {
+	db, err := store.NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	user, err := db.Users().Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &Bot{
+		Info: store.Bot{PlayerID: adaptivePlayerID, Difficulty: Adaptive},
+		eval: bot.NewRolloutEvaluator(0, 1),
+	}
+	// two rooms open at once, both from the initial rating
+	first, err := b.Adapt(ctx, db, user.PlayerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := b.Adapt(ctx, db, user.PlayerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	won := whiteWins(t)
+	human, machine := game.PlayerID(user.PlayerID), game.PlayerID(adaptivePlayerID)
+	for _, tt := range []struct {
+		a            *AdaptiveGame
+		white, black game.PlayerID
+	}{{first, human, machine}, {second, machine, human}} {
+		events := make(chan game.RoomEvent, 2)
+		events <- game.NewGameStarted("room", "game", engine.NewGame().Snapshot(), 1, tt.white, tt.black, time.Now())
+		events <- game.NewStateUpdate("room", "game", won, 1, time.Now())
+		close(events)
+		tt.a.follow(events)
+	}
+
+	// a win and a loss from the same rating cancel out
+	stored, err := db.Ratings().Get(ctx, user.PlayerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Games != 2 || math.Abs(stored.Rating-initialRating) > 1e-9 {
+		t.Errorf("expected a stored rating of %v over 2 games, got %v over %d", float64(initialRating), stored.Rating, stored.Games)
+	}
+	if second.Rating() != stored.Rating {
+		t.Errorf("expected the last room to play at the stored rating %v, got %v", stored.Rating, second.Rating())
+	}
+}
+
+func TestAdaptEstimatesFromBotResults(t *testing.T) {
+	db, err := store.NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	user, err := db.Users().Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// easy beaten again and again
+	const easy = "0194c000-0000-7001-8000-000000000001"
+	for _, id := range []string{"game-1", "game-2", "game-3"} {
+		g := store.NewGame(id, "room", user.PlayerID, easy)
+		g.State = []byte("state")
+		if err := db.Games().Create(ctx, g); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Games().Finish(ctx, id, "white", "fourInARow", "", []byte("state"), time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b := &Bot{
+		Info: store.Bot{PlayerID: adaptivePlayerID, Difficulty: Adaptive},
+		eval: bot.NewRolloutEvaluator(0, 1),
+	}
+	a, err := b.Adapt(ctx, db, user.PlayerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Rating() <= initialRating {
+		t.Errorf("expected wins against easy to raise the rating, got %v", a.Rating())
+	}
+
+	if _, err := (&Bot{Info: store.Bot{Difficulty: "hard"}}).Adapt(ctx, db, user.PlayerID); err == nil {
+		t.Error("expected a bot that is not adaptive to refuse")
+	}
+}
+
+// whiteWins returns a finished game white won.
+func whiteWins(t *testing.T) engine.Snapshot {
+	t.Helper()
+
+	g, err := engine.ParseFEN("PRB1/4/4/1brp Nn w ud 0 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Move(engine.Piece{Color: engine.White, Kind: engine.Knight}, engine.Cell{Row: 0, Col: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if g.Status != engine.GameOver {
+		t.Fatal("expected the knight drop to win")
+	}
+	return g.Snapshot()
+}
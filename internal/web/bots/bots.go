@@ -3,42 +3,52 @@ package bots
 import (
 	"context"
 	"log"
+	"time"
 
 	"tic-tac-chec/internal/bot"
+	"tic-tac-chec/internal/bot/search"
+	"tic-tac-chec/internal/game"
 	"tic-tac-chec/internal/web/config"
 	store "tic-tac-chec/internal/web/persistence/sqlite"
 
 	ort "github.com/yalue/onnxruntime_go"
 )
 
+// Model is what a bot plays with: bot.Model or search.Searcher.
+type Model interface {
+	RunPlayer(playerID string) game.Player
+}
+
 type Bot struct {
-	Model *bot.Model
+	Model Model
 	Info  store.Bot
+	// eval is what an ONNX bot judges positions with, for Adapt.
+	eval bot.Evaluator
 }
 
 type Bots map[string]*Bot
 
+// ByID returns the bot of the bots table row with the given ID.
+func (bb Bots) ByID(id string) (*Bot, bool) {
+	for _, b := range bb {
+		if b.Info.ID == id {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
 // UnavailableReason is set when Init returns nil (empty bots map), for HTTP errors.
 var UnavailableReason string
 
-// initBots loads a single ONNX model and creates three difficulty levels
-// using different MCTS simulation counts: easy (0), medium (250), hard (500).
+// Init creates a bot for every version 1 row of the bots table, keyed by
+// difficulty. ONNX bots share one model, and one inference batcher, with
+// different MCTS simulation and worker counts and need ONNX Runtime,
+// unless cfg.Inference is native; without it only the search bots are
+// loaded.
 func Init(ctx context.Context, db *store.Store, cfg config.Bots) Bots {
 	UnavailableReason = ""
 
-	if cfg.OrtLibPath == "" {
-		UnavailableReason = "ORT_LIB_PATH is not set (path to the ONNX Runtime shared library)"
-		log.Println("ORT_LIB_PATH not set, bot disabled")
-		return nil
-	}
-
-	ort.SetSharedLibraryPath(cfg.OrtLibPath)
-	if err := ort.InitializeEnvironment(); err != nil {
-		UnavailableReason = "ONNX Runtime init failed: " + err.Error()
-		log.Printf("Failed to initialize ONNX Runtime: %v - bot disabled", err)
-		return nil
-	}
-
 	version := 1
 	botRecords, err := db.Bots().LoadBots(ctx, version)
 	if err != nil {
@@ -47,24 +57,102 @@ func Init(ctx context.Context, db *store.Store, cfg config.Bots) Bots {
 		return nil
 	}
 
+	onnxUnavailable := initInference(cfg)
+	// bots that share a model file share its batcher, so that their
+	// requests are run together
+	batchers := make(map[string]*bot.Batcher)
+
 	bots := make(Bots)
 	for _, br := range botRecords {
-		b, err := bot.New(br.ModelPath, br.Mcts_Sims)
-		if err != nil {
-			UnavailableReason = "failed to load bot model " + br.Difficulty + ": " + err.Error()
-			log.Printf("Failed to create bot %s: %v - bot disabled", br.Difficulty, err)
-			return nil
-		}
+		switch br.Engine {
+		case store.BotEngineSearch:
+			bots[br.Difficulty] = &Bot{Model: search.New(br.SearchDepth), Info: br}
+		case store.BotEngineONNX:
+			if onnxUnavailable != "" {
+				UnavailableReason = onnxUnavailable
+				continue
+			}
+
+			batcher, ok := batchers[br.ModelPath]
+			if !ok {
+				var err error
+				batcher, err = newBatcher(cfg, br.ModelPath)
+				if err != nil {
+					UnavailableReason = "failed to load bot model " + br.Difficulty + ": " + err.Error()
+					log.Printf("Failed to create bot %s: %v - skipped", br.Difficulty, err)
+					continue
+				}
+				batchers[br.ModelPath] = batcher
+			}
 
-		bots[br.Difficulty] = &Bot{Model: b, Info: br}
+			bots[br.Difficulty] = &Bot{Model: bot.NewWithEvaluator(batcher, searchOptions(br)), Info: br, eval: batcher}
+		default:
+			log.Printf("Unknown engine %q for bot %s - skipped", br.Engine, br.Difficulty)
+		}
 	}
 
 	if len(bots) == 0 {
-		UnavailableReason = "no bot rows in the database for version 1 (run migrations and ensure bots/players are seeded)"
-		log.Println("no bot rows in database for version 1, bot disabled")
+		if UnavailableReason == "" {
+			UnavailableReason = "no bot rows in the database for version 1 (run migrations and ensure bots/players are seeded)"
+		}
+		log.Println("no bots loaded for version 1, bot disabled")
 		return nil
 	}
 
+	UnavailableReason = ""
 	log.Printf("bots ready: %d difficulty level(s) loaded", len(bots))
 	return bots
 }
+
+// initInference prepares the inference backend of the ONNX bots and returns
+// why it is unavailable, or "" if ONNX bots can be created. The native
+// backend needs no preparation.
+func initInference(cfg config.Bots) string {
+	switch cfg.Inference {
+	case config.InferenceNative:
+		log.Println("ONNX bots run on native Go inference")
+		return ""
+	case config.InferenceONNXRuntime:
+	default:
+		log.Printf("Unknown BOT_INFERENCE %q - ONNX bots disabled", cfg.Inference)
+		return "BOT_INFERENCE is not one of onnxruntime, native"
+	}
+
+	if cfg.OrtLibPath == "" {
+		log.Println("ORT_LIB_PATH not set, ONNX bots disabled")
+		return "ORT_LIB_PATH is not set (path to the ONNX Runtime shared library)"
+	}
+
+	ort.SetSharedLibraryPath(cfg.OrtLibPath)
+	if err := ort.InitializeEnvironment(); err != nil {
+		log.Printf("Failed to initialize ONNX Runtime: %v - ONNX bots disabled", err)
+		return "ONNX Runtime init failed: " + err.Error()
+	}
+
+	return ""
+}
+
+func newBatcher(cfg config.Bots, modelPath string) (*bot.Batcher, error) {
+	opts := bot.BatchOptions{MaxBatch: cfg.BatchMax, Window: cfg.BatchWindow}
+	if cfg.Inference == config.InferenceNative {
+		return bot.NewNativeBatcher(modelPath, opts)
+	}
+	return bot.NewBatcher(modelPath, opts)
+}
+
+// searchOptions returns how the ONNX bot of the row searches and picks
+// its moves.
+func searchOptions(br store.Bot) bot.SearchOptions {
+	return bot.SearchOptions{
+		Simulations: br.Mcts_Sims,
+		Workers:     br.MctsWorkers,
+		ThinkTime:   time.Duration(br.ThinkMs) * time.Millisecond,
+		EarlyStop:   br.EarlyStop,
+
+		Temperature:     br.Temperature,
+		DirichletAlpha:  br.DirichletAlpha,
+		DirichletWeight: br.DirichletWeight,
+		BlunderChance:   br.BlunderChance,
+		BlunderDepth:    br.BlunderDepth,
+	}
+}
@@ -0,0 +1,15 @@
+package bots
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+)
+
+func TestMain(m *testing.M) {
+	goose.SetLogger(log.New(io.Discard, "", 0))
+	os.Exit(m.Run())
+}
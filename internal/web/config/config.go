@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/sethvargo/go-envconfig"
 )
@@ -27,10 +28,22 @@ type Database struct {
 }
 
 type Bots struct {
+	// Inference is how ONNX bots run their network: "onnxruntime" through
+	// the shared library at OrtLibPath, or "native" in pure Go.
+	Inference  string `env:"BOT_INFERENCE, default=onnxruntime"`
 	OrtLibPath string `env:"ORT_LIB_PATH"`
+	// BatchMax and BatchWindow tune how ONNX inference requests of all bot
+	// games are batched, see bot.BatchOptions.
+	BatchMax    int           `env:"BOT_BATCH_MAX, default=64"`
+	BatchWindow time.Duration `env:"BOT_BATCH_WINDOW, default=2ms"`
 }
 
 // Logging configures slog output: stderr text (LOG_ENABLED) and/or OTLP (OTEL_ENABLED).
+const (
+	InferenceONNXRuntime = "onnxruntime"
+	InferenceNative      = "native"
+)
+
 type Logging struct {
 	OtelEnabled bool `env:"OTEL_ENABLED, default=false"`
 	LogEnabled  bool `env:"LOG_ENABLED, default=true"`
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log/slog"
 	"sync"
+	"tic-tac-chec/engine"
 	"tic-tac-chec/internal/game"
 	"tic-tac-chec/internal/web/clients"
 	store "tic-tac-chec/internal/web/persistence/sqlite"
@@ -35,6 +36,7 @@ type LobbyID string
 
 type Lobby struct {
 	ID           LobbyID
+	Rules        engine.Rules // of the games paired in this lobby
 	roomRegistry room.Registry
 	games        *store.GameStore
 	waiter       *waiter
@@ -55,8 +57,8 @@ var (
 	ErrLobbyIsFull = errors.New("lobby is full")
 )
 
-func NewLobby(id LobbyID, roomRegistry room.Registry, games *store.GameStore, persistent bool) *Lobby {
-	return &Lobby{ID: id, roomRegistry: roomRegistry, games: games, persistent: persistent}
+func NewLobby(id LobbyID, rules engine.Rules, roomRegistry room.Registry, games *store.GameStore, persistent bool) *Lobby {
+	return &Lobby{ID: id, Rules: rules.Normalized(), roomRegistry: roomRegistry, games: games, persistent: persistent}
 }
 
 func (l *Lobby) Join(client clients.Client) (<-chan PairingResult, error) {
@@ -117,19 +119,20 @@ func (l *Lobby) Join(client clients.Client) (<-chan PairingResult, error) {
 	results1 := waiter.results
 	results2 := make(chan PairingResult, 1)
 
-	roomEntry := l.roomRegistry.Create(room.Pairing{Players: [2]clients.Client{waiter.client, client}})
+	pairing := room.Pairing{Players: [2]clients.Client{waiter.client, client}, Rules: l.Rules}
+	roomEntry := l.roomRegistry.Create(pairing)
 
 	persistor.Run(l.games, roomEntry.Room)
 	go roomEntry.Room.Run()
 
 	result := PairingResult{
-		Pairing:   room.Pairing{Players: [2]clients.Client{waiter.client, client}},
+		Pairing:   pairing,
 		RoomEntry: roomEntry,
 	}
 
 	if !l.persistent {
 		l.completed = &completedPairing{
-			Pairing: room.Pairing{Players: [2]clients.Client{waiter.client, client}},
+			Pairing: pairing,
 			RoomID:  roomEntry.Room.ID,
 		}
 	}
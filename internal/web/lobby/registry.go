@@ -3,6 +3,7 @@ package lobby
 import (
 	"sync"
 
+	"tic-tac-chec/engine"
 	store "tic-tac-chec/internal/web/persistence/sqlite"
 	"tic-tac-chec/internal/web/room"
 
@@ -11,7 +12,7 @@ import (
 
 type Registry interface {
 	DefaultLobby() *Lobby
-	Create() *Lobby
+	Create(rules engine.Rules) *Lobby
 	Find(id LobbyID) *Lobby
 }
 
@@ -57,7 +58,8 @@ func (r *registry) Find(id LobbyID) *Lobby {
 	return lobby
 }
 
-func (r *registry) Create() *Lobby {
+// Create opens an ephemeral lobby whose games are played under the rules.
+func (r *registry) Create(rules engine.Rules) *Lobby {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -66,7 +68,7 @@ func (r *registry) Create() *Lobby {
 		return lobby
 	}
 
-	lobby := NewLobby(id, r.roomRegistry, r.games, EphemeralLobby)
+	lobby := NewLobby(id, rules, r.roomRegistry, r.games, EphemeralLobby)
 	r.lobbies[id] = lobby
 	return lobby
 }
@@ -79,7 +81,7 @@ func (r *registry) createDefaultLobby() {
 		return
 	}
 
-	lobby := NewLobby(DefaultLobbyID, r.roomRegistry, r.games, PersistentLobby)
+	lobby := NewLobby(DefaultLobbyID, engine.StandardRules, r.roomRegistry, r.games, PersistentLobby)
 	r.lobbies[DefaultLobbyID] = lobby
 }
 
@@ -13,34 +13,57 @@ type Bot struct {
 	Difficulty string
 	Version    int
 	Mcts_Sims  int
-	ModelPath  string
+	// MctsWorkers is the number of goroutines the MCTS runs on.
+	MctsWorkers int
+	// ThinkMs is how long the MCTS thinks about a move in milliseconds,
+	// 0 for no limit, and EarlyStop ends it once the best move is settled.
+	ThinkMs   int
+	EarlyStop bool
+	// Temperature, DirichletAlpha, DirichletWeight, BlunderChance and
+	// BlunderDepth add variety to the moves, see bot.SearchOptions.
+	Temperature     float64
+	DirichletAlpha  float64
+	DirichletWeight float64
+	BlunderChance   float64
+	BlunderDepth    int
+	ModelPath       string
+	// Engine is how the bot picks its moves, BotEngineONNX or BotEngineSearch.
+	Engine      string
+	SearchDepth int
+	// Rating is the bot's nominal Elo strength, 0 if it has none.
+	Rating int
 }
 
+const (
+	BotEngineONNX   = "onnx"
+	BotEngineSearch = "search"
+)
+
 type BotStore struct {
 	db *sql.DB
 }
 
 const (
 	selectBotsByVersionSQL = `
-	SELECT bots.id as bot_id, players.id as player_id, label, difficulty, version, mcts_sims, model_path
+	SELECT bots.id as bot_id, players.id as player_id, label, difficulty, version, mcts_sims, mcts_workers, think_ms, early_stop, temperature, dirichlet_alpha, dirichlet_weight, blunder_chance, blunder_depth, model_path, engine, search_depth, rating
 	FROM bots
 	INNER JOIN players ON bots.id = players.bot_id
 	WHERE version = ?`
 
 	selectBotSQL = `
-	SELECT bots.id as bot_id, players.id as player_id, label, difficulty, version, mcts_sims, model_path
+	SELECT bots.id as bot_id, players.id as player_id, label, difficulty, version, mcts_sims, mcts_workers, think_ms, early_stop, temperature, dirichlet_alpha, dirichlet_weight, blunder_chance, blunder_depth, model_path, engine, search_depth, rating
 	FROM bots
 	INNER JOIN players ON bots.id = players.bot_id
 	WHERE bots.id = ?`
 
 	selectBotByPlayerSQL = `
-	SELECT bots.id as bot_id, players.id as player_id, label, difficulty, version, mcts_sims, model_path
+	SELECT bots.id as bot_id, players.id as player_id, label, difficulty, version, mcts_sims, mcts_workers, think_ms, early_stop, temperature, dirichlet_alpha, dirichlet_weight, blunder_chance, blunder_depth, model_path, engine, search_depth, rating
 	FROM bots
 	INNER JOIN players ON bots.id = players.bot_id
 	WHERE players.id = ?`
 
 	selectLatestBotByDifficultySQL = `
-	SELECT bots.id as bot_id, players.id as player_id, label, difficulty, version, mcts_sims, model_path
+	SELECT bots.id as bot_id, players.id as player_id, label, difficulty, version, mcts_sims, mcts_workers, think_ms, early_stop, temperature, dirichlet_alpha, dirichlet_weight, blunder_chance, blunder_depth, model_path, engine, search_depth, rating
 	FROM bots
 	INNER JOIN players ON bots.id = players.bot_id
 	WHERE difficulty = ?
@@ -58,7 +81,7 @@ func (s *BotStore) LoadBots(ctx context.Context, version int) ([]Bot, error) {
 	var bots []Bot
 	for rows.Next() {
 		var bot Bot
-		if err := rows.Scan(&bot.ID, &bot.PlayerID, &bot.Label, &bot.Difficulty, &bot.Version, &bot.Mcts_Sims, &bot.ModelPath); err != nil {
+		if err := rows.Scan(&bot.ID, &bot.PlayerID, &bot.Label, &bot.Difficulty, &bot.Version, &bot.Mcts_Sims, &bot.MctsWorkers, &bot.ThinkMs, &bot.EarlyStop, &bot.Temperature, &bot.DirichletAlpha, &bot.DirichletWeight, &bot.BlunderChance, &bot.BlunderDepth, &bot.ModelPath, &bot.Engine, &bot.SearchDepth, &bot.Rating); err != nil {
 			return nil, err
 		}
 		bots = append(bots, bot)
@@ -86,7 +109,7 @@ func (s *BotStore) GetLatestByDifficulty(ctx context.Context, difficulty string)
 
 func (s *BotStore) parseRow(row *sql.Row) (Bot, error) {
 	var bot Bot
-	err := row.Scan(&bot.ID, &bot.PlayerID, &bot.Label, &bot.Difficulty, &bot.Version, &bot.Mcts_Sims, &bot.ModelPath)
+	err := row.Scan(&bot.ID, &bot.PlayerID, &bot.Label, &bot.Difficulty, &bot.Version, &bot.Mcts_Sims, &bot.MctsWorkers, &bot.ThinkMs, &bot.EarlyStop, &bot.Temperature, &bot.DirichletAlpha, &bot.DirichletWeight, &bot.BlunderChance, &bot.BlunderDepth, &bot.ModelPath, &bot.Engine, &bot.SearchDepth, &bot.Rating)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return Bot{}, ErrNotFound
@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	store "tic-tac-chec/internal/web/persistence/sqlite"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,6 +22,12 @@ func TestBotStore_Get(t *testing.T) {
 	assert.Equal(t, bot.Version, 1)
 	assert.Equal(t, bot.PlayerID, "0194c000-0000-7001-8000-000000000001")
 	assert.Equal(t, bot.Mcts_Sims, 0)
+	assert.Equal(t, bot.ThinkMs, 0)
+	assert.False(t, bot.EarlyStop)
+	assert.Equal(t, bot.Temperature, 1.0)
+	assert.Equal(t, bot.BlunderChance, 0.1)
+	assert.Equal(t, bot.BlunderDepth, 3)
+	assert.Equal(t, bot.Rating, 800)
 }
 
 func TestBotStore_GetByPlayer(t *testing.T) {
@@ -34,6 +42,9 @@ func TestBotStore_GetByPlayer(t *testing.T) {
 	assert.Equal(t, bot.Version, 1)
 	assert.Equal(t, bot.PlayerID, "0194c000-0000-7001-8000-000000000002")
 	assert.Equal(t, bot.Mcts_Sims, 100)
+	assert.Equal(t, bot.MctsWorkers, 2)
+	assert.Equal(t, bot.DirichletAlpha, 0.3)
+	assert.Equal(t, bot.DirichletWeight, 0.25)
 }
 
 func TestBotStore_LoadBots(t *testing.T) {
@@ -42,5 +53,41 @@ func TestBotStore_LoadBots(t *testing.T) {
 
 	bots, err := s.Bots().LoadBots(ctx, 1)
 	require.NoError(t, err)
-	assert.Len(t, bots, 3)
+	assert.Len(t, bots, 7)
+}
+
+func TestBotStore_GetAdaptiveBot(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	bot, err := s.Bots().GetLatestByDifficulty(ctx, "adaptive")
+	require.NoError(t, err)
+	assert.Equal(t, bot.ID, "adaptive-v1")
+	assert.Equal(t, bot.PlayerID, "0194c000-0000-7001-8000-000000000007")
+	assert.Equal(t, bot.Engine, store.BotEngineONNX)
+	assert.Equal(t, bot.Rating, 0)
+}
+
+func TestBotStore_GetSearchBot(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	bot, err := s.Bots().GetLatestByDifficulty(ctx, "search-hard")
+	require.NoError(t, err)
+	assert.Equal(t, bot.ID, "search-hard-v1")
+	assert.Equal(t, bot.Label, "Search Hard")
+	assert.Equal(t, bot.PlayerID, "0194c000-0000-7001-8000-000000000006")
+	assert.Equal(t, bot.Engine, store.BotEngineSearch)
+	assert.Equal(t, bot.SearchDepth, 5)
+	assert.Equal(t, bot.ModelPath, "")
+
+	bot, err = s.Bots().Get(ctx, "hard-v1")
+	require.NoError(t, err)
+	assert.Equal(t, bot.Engine, store.BotEngineONNX)
+	assert.Equal(t, bot.SearchDepth, 0)
+	assert.Equal(t, bot.MctsWorkers, 4)
+	assert.Equal(t, bot.ThinkMs, 2000)
+	assert.True(t, bot.EarlyStop)
+	assert.Equal(t, bot.Temperature, 0.0)
+	assert.Equal(t, bot.BlunderChance, 0.0)
 }
@@ -13,6 +13,8 @@ type Game struct {
 	BlackPlayerID string
 	Status        string
 	Winner        *string
+	Termination   *string
+	WinningLine   *string
 	State         []byte
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
@@ -40,7 +42,7 @@ const (
 	`
 
 	selectGameSQL = `
-	SELECT id, room_id, white_player_id, black_player_id, status, winner, state, created_at, updated_at, ended_at
+	SELECT id, room_id, white_player_id, black_player_id, status, winner, termination, winning_line, state, created_at, updated_at, ended_at
 	FROM games
 	WHERE id = ?
 	`
@@ -53,25 +55,43 @@ const (
 
 	finishGameSQL = `
 	UPDATE games
-	SET winner = ?, state = ?, ended_at = ?, updated_at = ?, status = 'finished'
+	SET winner = ?, termination = ?, winning_line = ?, state = ?, ended_at = ?, updated_at = ?, status = 'finished'
 	WHERE id = ?
 	`
 
 	selectLatestGameByRoomSQL = `
-	SELECT id, room_id, white_player_id, black_player_id, status, winner, state, created_at, updated_at, ended_at
+	SELECT id, room_id, white_player_id, black_player_id, status, winner, termination, winning_line, state, created_at, updated_at, ended_at
 	FROM games
 	WHERE room_id = ?
 	ORDER BY created_at DESC
 	LIMIT 1
 	`
 
+	selectBotResultsSQL = `
+	SELECT games.white_player_id = ?, games.winner, bots.rating
+	FROM games
+	INNER JOIN players ON players.id IN (games.white_player_id, games.black_player_id) AND players.id != ?
+	INNER JOIN bots ON bots.id = players.bot_id
+	WHERE ? IN (games.white_player_id, games.black_player_id)
+		AND games.status = 'finished'
+		AND bots.rating > 0
+	ORDER BY games.ended_at, games.id
+	`
+
 	selectActiveGamesSQL = `
-	SELECT id, room_id, white_player_id, black_player_id, status, winner, state, created_at, updated_at, ended_at
+	SELECT id, room_id, white_player_id, black_player_id, status, winner, termination, winning_line, state, created_at, updated_at, ended_at
 	FROM games
 	WHERE status = 'active'
 	`
 )
 
+// BotResult is the outcome of a finished game against a rated bot.
+type BotResult struct {
+	BotRating int
+	// Score is the player's: 1 for a win, 0.5 for a draw, 0 for a loss.
+	Score float64
+}
+
 func NewGame(gameID, roomID, whitePlayerID, blackPlayerID string) Game {
 	game := Game{
 		ID:            gameID,
@@ -112,9 +132,11 @@ func (g *GameStore) UpdateState(ctx context.Context, id string, state []byte) er
 	return err
 }
 
-func (g *GameStore) Finish(ctx context.Context, id string, winner string, state []byte, endedAt time.Time) error {
+// Finish marks the game finished. winningLine is empty unless the game
+// was won by a line.
+func (g *GameStore) Finish(ctx context.Context, id string, winner, termination, winningLine string, state []byte, endedAt time.Time) error {
 	_, err := g.db.ExecContext(ctx, finishGameSQL,
-		winner, state, formatTime(endedAt), formatTime(endedAt), id,
+		winner, termination, nullableString(winningLine), state, formatTime(endedAt), formatTime(endedAt), id,
 	)
 	return err
 }
@@ -150,15 +172,60 @@ func (g *GameStore) LoadActive(ctx context.Context) ([]Game, error) {
 	return games, nil
 }
 
+// LoadBotResults returns the results of the player's finished games
+// against rated bots, oldest first.
+func (g *GameStore) LoadBotResults(ctx context.Context, playerID string) ([]BotResult, error) {
+	rows, err := g.db.QueryContext(ctx, selectBotResultsSQL, playerID, playerID, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []BotResult
+	for rows.Next() {
+		var (
+			white  bool
+			winner sql.NullString
+			result BotResult
+		)
+		if err := rows.Scan(&white, &winner, &result.BotRating); err != nil {
+			return nil, err
+		}
+
+		switch winner.String {
+		case "white":
+			result.Score = boolScore(white)
+		case "black":
+			result.Score = boolScore(!white)
+		default:
+			result.Score = 0.5
+		}
+		results = append(results, result)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return results, nil
+}
+
+func boolScore(won bool) float64 {
+	if won {
+		return 1
+	}
+	return 0
+}
+
 func (g *GameStore) scan(row rowScanner) (Game, error) {
 	var game Game
 	var winnerNS sql.NullString
+	var terminationNS sql.NullString
+	var winningLineNS sql.NullString
 	var endedAtNS sql.NullString
 	var createdAtStr string
 	var updatedAtStr string
 	if err := row.Scan(
 		&game.ID, &game.RoomID, &game.WhitePlayerID, &game.BlackPlayerID,
-		&game.Status, &winnerNS, &game.State,
+		&game.Status, &winnerNS, &terminationNS, &winningLineNS, &game.State,
 		&createdAtStr, &updatedAtStr, &endedAtNS,
 	); err != nil {
 		return Game{}, err
@@ -167,6 +234,14 @@ func (g *GameStore) scan(row rowScanner) (Game, error) {
 		s := winnerNS.String
 		game.Winner = &s
 	}
+	if terminationNS.Valid {
+		s := terminationNS.String
+		game.Termination = &s
+	}
+	if winningLineNS.Valid {
+		s := winningLineNS.String
+		game.WinningLine = &s
+	}
 	if endedAtNS.Valid {
 		s := endedAtNS.String
 		t, err := parseTime(s)
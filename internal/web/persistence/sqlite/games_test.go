@@ -2,6 +2,7 @@ package store_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	store "tic-tac-chec/internal/web/persistence/sqlite"
 	"time"
@@ -98,6 +99,7 @@ func TestGameStore_UpdateStateChangesOnlyStateAndUpdatedAt(t *testing.T) {
 // with winner=&"white" and a later endedAt time. Loads, asserts:
 //   - Status == "finished"
 //   - Winner != nil && *Winner == "white"
+//   - Termination and WinningLine stored
 //   - EndedAt != nil
 //   - UpdatedAt updated (== endedAt)
 func TestGameStore_Finish(t *testing.T) {
@@ -113,11 +115,13 @@ func TestGameStore_Finish(t *testing.T) {
 	require.NoError(t, err)
 
 	finishTime := time.Now().Add(10 * time.Second)
-	s.Games().Finish(ctx, game.ID, "white", []byte("final state"), finishTime)
+	s.Games().Finish(ctx, game.ID, "white", "fourInARow", "a1 b2 c3 d4", []byte("final state"), finishTime)
 
 	loaded, _ := s.Games().Load(ctx, game.ID)
 	assert.Equal(t, loaded.Status, "finished")
 	assert.Equal(t, *loaded.Winner, "white")
+	assert.Equal(t, *loaded.Termination, "fourInARow")
+	assert.Equal(t, *loaded.WinningLine, "a1 b2 c3 d4")
 	assert.Equal(t, *loaded.EndedAt, finishTime.Truncate(time.Second).UTC())
 	assert.Equal(t, loaded.UpdatedAt, finishTime.Truncate(time.Second).UTC())
 }
@@ -163,3 +167,68 @@ func TestGameStore_LoadLatestByRoom(t *testing.T) {
 	loaded, _ := s.Games().LoadLatestByRoom(ctx, "room-1")
 	assert.Equal(t, loaded.ID, game3.ID)
 }
+
+func TestGameStore_FinishWithoutWinningLine(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	u1, _ := s.Users().Create(ctx)
+	u2, _ := s.Users().Create(ctx)
+
+	game := store.NewGame("game-1", "room-1", u1.PlayerID, u2.PlayerID)
+	game.State = []byte("initial state")
+	require.NoError(t, s.Games().Create(ctx, game))
+
+	err := s.Games().Finish(ctx, game.ID, "black", "resignation", "", []byte("final state"), time.Now())
+	require.NoError(t, err)
+
+	loaded, _ := s.Games().Load(ctx, game.ID)
+	assert.Equal(t, *loaded.Termination, "resignation")
+	assert.Nil(t, loaded.WinningLine)
+}
+
+func TestGameStore_LoadBotResults(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	user, _ := s.Users().Create(ctx)
+	other, _ := s.Users().Create(ctx)
+	const (
+		easy     = "0194c000-0000-7001-8000-000000000001"
+		hard     = "0194c000-0000-7001-8000-000000000003"
+		search   = "0194c000-0000-7001-8000-000000000004"
+		adaptive = "0194c000-0000-7001-8000-000000000007"
+	)
+
+	start := time.Now().Add(-time.Hour)
+	for i, g := range []struct {
+		white, black, winner string
+		finished             bool
+	}{
+		{user.PlayerID, easy, "white", true},
+		{hard, user.PlayerID, "white", true},
+		{easy, user.PlayerID, "draw", true},
+		{user.PlayerID, hard, "", false},               // still under way
+		{user.PlayerID, search, "white", true},         // unrated bot
+		{adaptive, user.PlayerID, "black", true},       // unrated bot
+		{user.PlayerID, other.PlayerID, "white", true}, // not a bot
+		{hard, user.PlayerID, "black", true},
+	} {
+		game := store.NewGame(fmt.Sprintf("game-%d", i), "room-1", g.white, g.black)
+		game.State = []byte("state")
+		require.NoError(t, s.Games().Create(ctx, game))
+		if g.finished {
+			endedAt := start.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, s.Games().Finish(ctx, game.ID, g.winner, "fourInARow", "", []byte("state"), endedAt))
+		}
+	}
+
+	results, err := s.Games().LoadBotResults(ctx, user.PlayerID)
+	require.NoError(t, err)
+	assert.Equal(t, []store.BotResult{
+		{BotRating: 800, Score: 1},
+		{BotRating: 1600, Score: 0},
+		{BotRating: 800, Score: 0.5},
+		{BotRating: 1600, Score: 1},
+	}, results)
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Why a finished game ended, as in the wire format ("fourInARow",
+-- "resignation", ...), and the squares of the winning line ("a1 b2 c3 d4")
+-- for four-in-a-row wins.
+ALTER TABLE games ADD COLUMN termination TEXT;
+ALTER TABLE games ADD COLUMN winning_line TEXT;
+
+-- +goose Down
+ALTER TABLE games DROP COLUMN winning_line;
+ALTER TABLE games DROP COLUMN termination;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- How a bot picks its moves: 'onnx' runs the model at model_path with
+-- mcts_sims simulations, 'search' is the pure-Go alpha-beta search looking
+-- search_depth plies ahead and needs no ONNX Runtime.
+ALTER TABLE bots ADD COLUMN engine TEXT NOT NULL DEFAULT 'onnx' CHECK (engine IN ('onnx', 'search'));
+ALTER TABLE bots ADD COLUMN search_depth INTEGER NOT NULL DEFAULT 0;
+
+INSERT INTO bots (id, label, difficulty, version, model_path, engine, search_depth) VALUES
+  ('search-easy-v1',   'Search Easy',   'search-easy',   1, '', 'search', 2),
+  ('search-medium-v1', 'Search Medium', 'search-medium', 1, '', 'search', 4),
+  ('search-hard-v1',   'Search Hard',   'search-hard',   1, '', 'search', 5);
+
+INSERT INTO players (id, bot_id) VALUES
+  ('0194c000-0000-7001-8000-000000000004', 'search-easy-v1'),
+  ('0194c000-0000-7001-8000-000000000005', 'search-medium-v1'),
+  ('0194c000-0000-7001-8000-000000000006', 'search-hard-v1');
+
+-- +goose Down
+DELETE FROM players WHERE bot_id IN ('search-easy-v1', 'search-medium-v1', 'search-hard-v1');
+DELETE FROM bots WHERE engine = 'search';
+ALTER TABLE bots DROP COLUMN search_depth;
+ALTER TABLE bots DROP COLUMN engine;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Goroutines an 'onnx' bot runs its MCTS simulations on. The simulations
+-- are the same, they finish sooner.
+ALTER TABLE bots ADD COLUMN mcts_workers INTEGER NOT NULL DEFAULT 1 CHECK (mcts_workers >= 1);
+
+UPDATE bots SET mcts_workers = 2 WHERE id = 'medium-v1';
+UPDATE bots SET mcts_workers = 4 WHERE id = 'hard-v1';
+
+-- +goose Down
+ALTER TABLE bots DROP COLUMN mcts_workers;
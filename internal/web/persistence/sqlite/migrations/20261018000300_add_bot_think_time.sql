@@ -0,0 +1,14 @@
+-- +goose Up
+-- How long an 'onnx' bot thinks about a move, in milliseconds, 0 for no
+-- limit. The search stops at mcts_sims simulations or after think_ms,
+-- whichever comes first; the bot thinks less while the board is emptier.
+-- early_stop ends it sooner once the best move cannot be overtaken.
+ALTER TABLE bots ADD COLUMN think_ms INTEGER NOT NULL DEFAULT 0 CHECK (think_ms >= 0);
+ALTER TABLE bots ADD COLUMN early_stop BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE bots SET think_ms = 1000, early_stop = TRUE WHERE id = 'medium-v1';
+UPDATE bots SET think_ms = 2000, early_stop = TRUE WHERE id = 'hard-v1';
+
+-- +goose Down
+ALTER TABLE bots DROP COLUMN early_stop;
+ALTER TABLE bots DROP COLUMN think_ms;
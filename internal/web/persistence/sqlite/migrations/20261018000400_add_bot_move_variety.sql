@@ -0,0 +1,22 @@
+-- +goose Up
+-- Randomness of an 'onnx' bot's moves, so that weaker bots vary and feel
+-- human without weaker checkpoints. temperature > 0 samples moves by MCTS
+-- visits, or by policy without a search; dirichlet_alpha and
+-- dirichlet_weight mix noise into the root priors; with probability
+-- blunder_chance the bot plays one of the blunder_depth moves ranked after
+-- its best.
+ALTER TABLE bots ADD COLUMN temperature REAL NOT NULL DEFAULT 0 CHECK (temperature >= 0);
+ALTER TABLE bots ADD COLUMN dirichlet_alpha REAL NOT NULL DEFAULT 0 CHECK (dirichlet_alpha >= 0);
+ALTER TABLE bots ADD COLUMN dirichlet_weight REAL NOT NULL DEFAULT 0 CHECK (dirichlet_weight BETWEEN 0 AND 1);
+ALTER TABLE bots ADD COLUMN blunder_chance REAL NOT NULL DEFAULT 0 CHECK (blunder_chance BETWEEN 0 AND 1);
+ALTER TABLE bots ADD COLUMN blunder_depth INTEGER NOT NULL DEFAULT 1 CHECK (blunder_depth >= 1);
+
+UPDATE bots SET temperature = 1.0, blunder_chance = 0.1, blunder_depth = 3 WHERE id = 'easy-v1';
+UPDATE bots SET temperature = 0.5, dirichlet_alpha = 0.3, dirichlet_weight = 0.25, blunder_chance = 0.05, blunder_depth = 2 WHERE id = 'medium-v1';
+
+-- +goose Down
+ALTER TABLE bots DROP COLUMN blunder_depth;
+ALTER TABLE bots DROP COLUMN blunder_chance;
+ALTER TABLE bots DROP COLUMN dirichlet_weight;
+ALTER TABLE bots DROP COLUMN dirichlet_alpha;
+ALTER TABLE bots DROP COLUMN temperature;
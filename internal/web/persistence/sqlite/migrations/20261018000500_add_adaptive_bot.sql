@@ -0,0 +1,31 @@
+-- +goose Up
+-- rating is the nominal Elo strength of a bot, 0 if it has none. The
+-- results of humans against rated bots seed their player_ratings, which
+-- the 'adaptive' bot plays at: it picks its simulations and blunders from
+-- the human's rating, and the rating moves after every game against it.
+-- The nominal ratings are estimates; cmd/arena can calibrate them.
+ALTER TABLE bots ADD COLUMN rating INTEGER NOT NULL DEFAULT 0 CHECK (rating >= 0);
+
+UPDATE bots SET rating = 800 WHERE id = 'easy-v1';
+UPDATE bots SET rating = 1200 WHERE id = 'medium-v1';
+UPDATE bots SET rating = 1600 WHERE id = 'hard-v1';
+
+INSERT INTO bots (id, label, difficulty, version, model_path, mcts_workers, think_ms, early_stop) VALUES
+  ('adaptive-v1', 'Adaptive', 'adaptive', 1, 'bot/models/bot.onnx', 2, 2000, TRUE);
+
+INSERT INTO players (id, bot_id) VALUES
+  ('0194c000-0000-7001-8000-000000000007', 'adaptive-v1');
+
+CREATE TABLE player_ratings (
+    player_id  TEXT PRIMARY KEY REFERENCES players(id),
+    rating     REAL NOT NULL,
+    -- games is the number of rated games behind the rating.
+    games      INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE player_ratings;
+DELETE FROM players WHERE bot_id = 'adaptive-v1';
+DELETE FROM bots WHERE id = 'adaptive-v1';
+ALTER TABLE bots DROP COLUMN rating;
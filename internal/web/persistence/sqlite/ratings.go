@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Rating is a human player's Elo strength against the bots.
+type Rating struct {
+	PlayerID string
+	Rating   float64
+	// Games is the number of rated games behind the rating.
+	Games     int
+	UpdatedAt time.Time
+}
+
+type RatingStore struct {
+	db *sql.DB
+}
+
+const (
+	selectRatingSQL = `
+	SELECT player_id, rating, games, updated_at
+	FROM player_ratings
+	WHERE player_id = ?`
+
+	upsertRatingSQL = `
+	INSERT INTO player_ratings (player_id, rating, games, updated_at)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT (player_id) DO UPDATE SET rating = rating + ?, games = games + ?, updated_at = excluded.updated_at
+	RETURNING player_id, rating, games, updated_at`
+)
+
+func (s *RatingStore) Get(ctx context.Context, playerID string) (Rating, error) {
+	return scanRating(s.db.QueryRowContext(ctx, selectRatingSQL, playerID))
+}
+
+// Save stores the change of a player's rating from one value to the next.
+// It adds the change to the stored rating, which other rooms may have
+// moved since from was read, or stores to if the player has none yet.
+// It returns the rating as stored.
+func (s *RatingStore) Save(ctx context.Context, from, to Rating) (Rating, error) {
+	return scanRating(s.db.QueryRowContext(ctx, upsertRatingSQL,
+		to.PlayerID, to.Rating, to.Games, formatTime(to.UpdatedAt),
+		to.Rating-from.Rating, to.Games-from.Games,
+	))
+}
+
+func scanRating(row *sql.Row) (Rating, error) {
+	var (
+		rating       Rating
+		updatedAtStr string
+	)
+	err := row.Scan(&rating.PlayerID, &rating.Rating, &rating.Games, &updatedAtStr)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Rating{}, ErrNotFound
+	}
+	if err != nil {
+		return Rating{}, err
+	}
+
+	if rating.UpdatedAt, err = parseTime(updatedAtStr); err != nil {
+		return Rating{}, err
+	}
+	return rating, nil
+}
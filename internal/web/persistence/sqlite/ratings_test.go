@@ -0,0 +1,66 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	store "tic-tac-chec/internal/web/persistence/sqlite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRatingStore_SaveAndGet(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	user, _ := s.Users().Create(ctx)
+
+	_, err := s.Ratings().Get(ctx, user.PlayerID)
+	require.ErrorIs(t, err, store.ErrNotFound)
+
+	estimated := store.Rating{PlayerID: user.PlayerID, Rating: 1000, Games: 2}
+	rating := store.Rating{PlayerID: user.PlayerID, Rating: 1040.5, Games: 3, UpdatedAt: time.Now().Add(-time.Minute)}
+	saved, err := s.Ratings().Save(ctx, estimated, rating)
+	require.NoError(t, err)
+	assert.Equal(t, 1040.5, saved.Rating)
+	assert.Equal(t, 3, saved.Games)
+
+	next := store.Rating{PlayerID: user.PlayerID, Rating: 1010, Games: 4, UpdatedAt: time.Now()}
+	_, err = s.Ratings().Save(ctx, rating, next)
+	require.NoError(t, err)
+
+	loaded, err := s.Ratings().Get(ctx, user.PlayerID)
+	require.NoError(t, err)
+	assert.Equal(t, 1010.0, loaded.Rating)
+	assert.Equal(t, 4, loaded.Games)
+	assert.Equal(t, next.UpdatedAt.Truncate(time.Second).UTC(), loaded.UpdatedAt)
+}
+
+func TestRatingStore_SaveAddsToStoredRating(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	user, _ := s.Users().Create(ctx)
+
+	// two rooms rate games from the same stored rating
+	start := store.Rating{PlayerID: user.PlayerID, Rating: 1000, Games: 5}
+	_, err := s.Ratings().Save(ctx, store.Rating{PlayerID: user.PlayerID}, start)
+	require.NoError(t, err)
+
+	_, err = s.Ratings().Save(ctx, start, store.Rating{PlayerID: user.PlayerID, Rating: 1030, Games: 6, UpdatedAt: time.Now()})
+	require.NoError(t, err)
+	saved, err := s.Ratings().Save(ctx, start, store.Rating{PlayerID: user.PlayerID, Rating: 980, Games: 6, UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1010.0, saved.Rating)
+	assert.Equal(t, 7, saved.Games)
+}
+
+func TestRatingStore_SaveUnknownPlayer(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	rating := store.Rating{PlayerID: "unknown", Rating: 1000, UpdatedAt: time.Now()}
+	_, err := s.Ratings().Save(ctx, rating, rating)
+	require.Error(t, err)
+}
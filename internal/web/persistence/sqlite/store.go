@@ -61,6 +61,10 @@ func (s *Store) Games() *GameStore {
 	return &GameStore{db: s.db}
 }
 
+func (s *Store) Ratings() *RatingStore {
+	return &RatingStore{db: s.db}
+}
+
 func parseTime(str string) (time.Time, error) {
 	return time.Parse(time.RFC3339, str)
 }
@@ -75,3 +79,10 @@ func formatNullableTime(t *time.Time) any {
 	}
 	return formatTime(*t)
 }
+
+func nullableString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"strings"
 	"tic-tac-chec/engine"
 	"tic-tac-chec/internal/game"
+	"tic-tac-chec/internal/parse"
 	store "tic-tac-chec/internal/web/persistence/sqlite"
+	"tic-tac-chec/internal/wire"
 	"time"
 )
 
@@ -51,9 +54,10 @@ func recordGames(games *store.GameStore, listener <-chan game.RoomEvent) {
 				continue
 			}
 
-			if e.Game.Status == engine.GameOver {
-				winner := winnerStr(e.Game.Winner)
-				err := games.Finish(ctx, string(e.GameID), winner, jsonState, time.Now())
+			if e.Game.Status() == engine.GameOver {
+				winner := winnerStr(e.Game.Winner())
+				termination := wire.TerminationToString(e.Termination)
+				err := games.Finish(ctx, string(e.GameID), winner, termination, lineStr(e.WinningLine, e.Game.Rules().Size()), jsonState, time.Now())
 				if err != nil {
 					slog.Error("persistor.finish_failed", "err", err)
 				}
@@ -80,3 +84,13 @@ func winnerStr(winner *engine.Color) string {
 
 	return "draw"
 }
+
+// lineStr writes the squares of a winning line separated by spaces,
+// e.g. "a1 b2 c3 d4".
+func lineStr(line []engine.Cell, size int) string {
+	squares := make([]string, len(line))
+	for i, cell := range line {
+		squares[i] = parse.FormatSquareOn(cell, size)
+	}
+	return strings.Join(squares, " ")
+}
@@ -32,7 +32,10 @@ type Entry struct {
 	Participants [2]Participant
 }
 
-type botSpawner func(botID string) (game.Player, bool)
+// botSpawner seats the bot with the given ID against the human with the given
+// player ID. The returned watch, if not nil, is called with the room before it
+// runs.
+type botSpawner func(ctx context.Context, botID, humanID string) (player game.Player, watch func(*game.Room), err error)
 
 type registry struct {
 	mu       sync.Mutex
@@ -52,7 +55,8 @@ func (rr *registry) Create(pairing Pairing) Entry {
 
 	p1 := game.NewPlayerWithID(make(chan game.Command), pairing.Players[0].PlayerID)
 	p2 := game.NewPlayerWithID(make(chan game.Command), pairing.Players[1].PlayerID)
-	room := game.NewRoom(p1, p2)
+	room := game.NewRoom(p1, p2, pairing.Rules)
+	room.AbandonAfter = AbandonAfter
 
 	entry := Entry{
 		Room: room,
@@ -71,8 +75,11 @@ func (rr *registry) CreateWithPlayers(p1, p2 game.Player, clients [2]clients.Cli
 	rr.mu.Lock()
 	defer rr.mu.Unlock()
 
+	room := game.NewRoom(p1, p2)
+	room.AbandonAfter = AbandonAfter
+
 	entry := Entry{
-		Room: game.NewRoom(p1, p2),
+		Room: room,
 		Participants: [2]Participant{
 			{ClientID: clients[0], PlayerID: p1.ID},
 			{ClientID: clients[1], PlayerID: p2.ID},
@@ -117,25 +124,32 @@ func (rr *registry) Restore(ctx context.Context, roomId game.RoomID) (Entry, err
 		return Entry{}, ErrRoomNotFound
 	}
 
-	gamePlayerWhite, clientWhite, err := rr.playerFor(whitePlayer)
+	gamePlayerWhite, clientWhite, watchWhite, err := rr.playerFor(ctx, whitePlayer, blackPlayer)
 	if err != nil {
 		return Entry{}, ErrRoomNotFound
 	}
-	gamePlayerBlack, clientBlack, err := rr.playerFor(blackPlayer)
+	gamePlayerBlack, clientBlack, watchBlack, err := rr.playerFor(ctx, blackPlayer, whitePlayer)
 	if err != nil {
 		return Entry{}, ErrRoomNotFound
 	}
 
-	var gameState engine.Game
+	var gameState engine.Snapshot
 	err = json.Unmarshal(g.State, &gameState)
 	if err != nil {
 		return Entry{}, ErrRoomNotFound
 	}
 
-	room := game.NewRoom(gamePlayerWhite, gamePlayerBlack)
+	room := game.NewRoom(gamePlayerWhite, gamePlayerBlack, gameState.Rules())
 	room.ID = roomId
 	room.GameID = game.GameID(g.ID)
-	room.Game = &gameState
+	room.Game = gameState.Game()
+	room.AbandonAfter = AbandonAfter
+
+	for _, watch := range []func(*game.Room){watchWhite, watchBlack} {
+		if watch != nil {
+			watch(room)
+		}
+	}
 
 	entry := Entry{
 		Room: room,
@@ -159,15 +173,15 @@ func (re *Entry) ParticipantByClientID(clientID clients.ClientID) (Participant,
 	return Participant{}, false
 }
 
-func (rr *registry) playerFor(p store.Player) (game.Player, clients.ClientID, error) {
+func (rr *registry) playerFor(ctx context.Context, p, opponent store.Player) (game.Player, clients.ClientID, func(*game.Room), error) {
 	switch {
 	case p.BotID != nil:
-		player, ok := rr.spawnBot(*p.BotID)
-		if !ok {
-			return game.Player{}, "", fmt.Errorf("bot %s is not available", *p.BotID)
+		player, watch, err := rr.spawnBot(ctx, *p.BotID, opponent.ID)
+		if err != nil {
+			return game.Player{}, "", nil, err
 		}
 
-		return player, clients.BotClientID, nil
+		return player, clients.BotClientID, watch, nil
 	case p.UserID != nil:
 		player := game.Player{
 			ID:              game.PlayerID(p.ID),
@@ -178,8 +192,8 @@ func (rr *registry) playerFor(p store.Player) (game.Player, clients.ClientID, er
 			Color:    engine.Color(0),
 		}
 
-		return player, clients.ClientID(*p.UserID), nil
+		return player, clients.ClientID(*p.UserID), nil, nil
 	default:
-		return game.Player{}, "", fmt.Errorf("player neither bot nor user")
+		return game.Player{}, "", nil, fmt.Errorf("player neither bot nor user")
 	}
 }
@@ -1,7 +1,16 @@
 package room
 
-import "tic-tac-chec/internal/web/clients"
+import (
+	"tic-tac-chec/engine"
+	"tic-tac-chec/internal/web/clients"
+	"time"
+)
+
+// AbandonAfter is the game.Room AbandonAfter of web rooms: a player who
+// stays disconnected that long loses the game in progress.
+const AbandonAfter = 2 * time.Minute
 
 type Pairing struct {
 	Players [2]clients.Client
+	Rules   engine.Rules
 }
@@ -1,7 +1,7 @@
 const tokenKey = "ttc-client-token";
 const PIECE_CODES = {
-  white: { pawn: "WP", rook: "WR", bishop: "WB", knight: "WN" },
-  black: { pawn: "BP", rook: "BR", bishop: "BB", knight: "BN" },
+  white: { pawn: "WP", rook: "WR", bishop: "WB", knight: "WN", queen: "WQ" },
+  black: { pawn: "BP", rook: "BR", bishop: "BB", knight: "BN", queen: "BQ" },
 };
 
 // SVG chess pieces by Colin M.L. Burnett (Wikimedia Commons, CC BY-SA 3.0)
@@ -12,9 +12,23 @@ const PIECE_SVGS = {
     '<svg viewBox="0 0 45 45"><g style="fill:currentColor;stroke:var(--piece-stroke);stroke-width:1;stroke-linecap:round;stroke-linejoin:round" transform="translate(0,0.6)"><path d="M 9,36 C 12.39,35.03 19.11,36.43 22.5,34 C 25.89,36.43 32.61,35.03 36,36 C 36,36 37.65,36.54 39,38 C 38.32,38.97 37.35,38.99 36,38.5 C 32.61,37.53 25.89,38.96 22.5,37.5 C 19.11,38.96 12.39,37.53 9,38.5 C 7.65,38.99 6.68,38.97 6,38 C 7.35,36.54 9,36 9,36 z"/><path d="M 15,32 C 17.5,34.5 27.5,34.5 30,32 C 30.5,30.5 30,30 30,30 C 30,27.5 27.5,26 27.5,26 C 33,24.5 33.5,14.5 22.5,10.5 C 11.5,14.5 12,24.5 17.5,26 C 17.5,26 15,27.5 15,30 C 15,30 14.5,30.5 15,32 z"/><path d="M 25 8 A 2.5 2.5 0 1 1 20,8 A 2.5 2.5 0 1 1 25 8 z"/><path d="M 17.5,26 L 27.5,26 M 15,30 L 30,30 M 22.5,15.5 L 22.5,20.5 M 20,18 L 25,18" style="fill:none;stroke-linejoin:miter"/></g></svg>',
   knight:
     '<svg viewBox="0 0 45 45"><g style="fill:currentColor;stroke:var(--piece-stroke);stroke-width:1;stroke-linecap:round;stroke-linejoin:round" transform="translate(0,0.3)"><path d="M 22,10 C 32.5,11 38.5,18 38,39 L 15,39 C 15,30 25,32.5 23,18"/><path d="M 24,18 C 24.38,20.91 18.45,25.37 16,27 C 13,29 13.18,31.34 11,31 C 9.958,30.06 12.41,27.96 11,28 C 10,28 11.19,29.23 10,30 C 9,30 5.997,31 6,26 C 6,24 12,14 12,14 C 12,14 13.89,12.1 14,10.5 C 13.27,9.506 13.5,8.5 13.5,7.5 C 14.5,6.5 16.5,10 16.5,10 L 18.5,10 C 18.5,10 19.28,8.008 21,7 C 22,7 22,10 22,10"/><path d="M 9.5 25.5 A 0.5 0.5 0 1 1 8.5,25.5 A 0.5 0.5 0 1 1 9.5 25.5 z" style="fill:var(--piece-stroke);stroke:var(--piece-stroke)"/><path d="M 15 15.5 A 0.5 1.5 0 1 1 14,15.5 A 0.5 1.5 0 1 1 15 15.5 z" transform="matrix(0.866,0.5,-0.5,0.866,9.693,-5.173)" style="fill:var(--piece-stroke);stroke:var(--piece-stroke)"/></g></svg>',
+  queen:
+    '<svg viewBox="0 0 45 45"><g style="fill:currentColor;stroke:var(--piece-stroke);stroke-width:1;stroke-linecap:round;stroke-linejoin:round"><path d="M 9,26 C 17.5,24.5 30,24.5 36,26 L 38.5,13.5 L 31,25 L 30.7,10.9 L 25.5,24.5 L 22.5,10 L 19.5,24.5 L 14.3,10.9 L 14,25 L 6.5,13.5 L 9,26 z"/><path d="M 9,26 C 9,28 10.5,28 11.5,30 C 12.5,31.5 12.5,31 12,33.5 C 10.5,34.5 11,36 11,36 C 9.5,37.5 11,38.5 11,38.5 C 17.5,39.5 27.5,39.5 34,38.5 C 34,38.5 35.5,37.5 34,36 C 34,36 34.5,34.5 33,33.5 C 32.5,31 32.5,31.5 33.5,30 C 34.5,28 36,28 36,26 C 27.5,24.5 17.5,24.5 9,26 z"/><path d="M 11.5,30 C 15,29 30,29 33.5,30 M 12,33.5 C 18,32.5 27,32.5 33,33.5" style="fill:none"/><circle cx="6" cy="12" r="2"/><circle cx="14" cy="9" r="2"/><circle cx="22.5" cy="8" r="2"/><circle cx="31" cy="9" r="2"/><circle cx="39" cy="12" r="2"/></g></svg>',
 };
 
 const KINDS = ["pawn", "rook", "bishop", "knight"];
+const FILES = ["a", "b", "c", "d", "e"];
+
+// Explains how a game ended, after "You win", "You lose" or "Draw".
+// Four-in-a-row wins are shown by the line on the board instead.
+const TERMINATION_REASONS = {
+  repetition: "by repetition",
+  moveLimit: "by move limit",
+  resignation: "by resignation",
+  timeout: "on time",
+  abandonment: "by abandonment",
+  agreedDraw: "by agreement",
+};
 
 const HOME_BOARD = {
   cells: [
@@ -56,7 +70,7 @@ const HOME_DEMO = {
 
 let homeDemoPlayed = false;
 
-const DIFFICULTIES = ["easy", "medium", "hard"];
+const DIFFICULTIES = ["easy", "medium", "hard", "adaptive"];
 const DIFFICULTY_STORAGE_KEY = "ttc-bot-difficulty";
 
 const state = {
@@ -73,13 +87,17 @@ const state = {
   turn: null,
   status: null,
   winner: null,
+  termination: null,
+  winningLine: null,
   winLineShown: false,
   selectedPiece: null,
-  pawnDirections: null,
+  legalMoves: [],
   prev: null,
   error: null,
   rematchSent: false,
   opponentWantsRematch: false,
+  drawOfferSent: false,
+  opponentOffersDraw: false,
   opponentStatus: null,
   installMessage: null,
   score: { me: 0, opponent: 0 },
@@ -218,8 +236,9 @@ function playMoveSound(prev, next) {
 }
 
 function boardChanged(a, b) {
-  for (let r = 0; r < 4; r++) {
-    for (let c = 0; c < 4; c++) {
+  if (a.length !== b.length) return true;
+  for (let r = 0; r < a.length; r++) {
+    for (let c = 0; c < a.length; c++) {
       const pa = a[r][c];
       const pb = b[r][c];
       if (!pa && !pb) continue;
@@ -673,10 +692,19 @@ function handleRoomMessage(data) {
         status: state.status,
       };
       state.board = data.state.board;
+      state.rules = data.state.rules;
+      state.hands = data.state.hands;
       state.turn = data.state.turn;
       state.status = data.state.status;
       state.winner = data.state.winner;
-      state.pawnDirections = data.state.pawnDirections;
+      state.termination = data.state.termination;
+      state.winningLine = data.state.winningLine;
+      state.legalMoves = data.state.legalMoves || [];
+      // draw offers lapse with the next move
+      if (state.prev.turn !== state.turn || state.status === "over") {
+        state.drawOfferSent = false;
+        state.opponentOffersDraw = false;
+      }
       reconcileSelectedPiece();
       state.roomReady = true;
       state.roomEverReady = true;
@@ -716,6 +744,10 @@ function handleRoomMessage(data) {
       state.opponentWantsRematch = true;
       render();
       break;
+    case "drawOffered":
+      state.opponentOffersDraw = true;
+      render();
+      break;
     case "opponentAway":
       state.opponentStatus = "away";
       render();
@@ -858,11 +890,13 @@ function renderTurnIndicator() {
     const row = document.createElement("div");
     row.className = "turn-row";
     const result = document.createElement("span");
+    const reason = TERMINATION_REASONS[state.termination];
+    const suffix = reason ? ` ${reason}!` : "!";
     if (state.winner) {
       result.textContent =
-        state.winner === state.myColor ? "You win!" : "You lose!";
+        (state.winner === state.myColor ? "You win" : "You lose") + suffix;
     } else {
-      result.textContent = "Draw!";
+      result.textContent = "Draw" + suffix;
     }
     row.appendChild(result);
     turnIndicator.appendChild(row);
@@ -973,6 +1007,8 @@ function renderGameArea() {
 
   gameArea.innerHTML = "";
   gameArea.classList.toggle("game-over", state.status === "over");
+  gameArea.style.setProperty("--board-size", boardSize());
+  gameArea.style.setProperty("--hand-size", handKinds().length);
   gameArea.appendChild(renderHand(topColor));
   gameArea.appendChild(renderColLabels());
   const boardEl = renderBoard(flipped);
@@ -981,11 +1017,13 @@ function renderGameArea() {
   gameArea.appendChild(renderHand(bottomColor));
   if (state.status === "over") {
     gameArea.appendChild(renderRematchActions());
+  } else {
+    gameArea.appendChild(renderGameActions());
   }
   gameArea.appendChild(renderEmojiButton());
 
-  if (state.winner) {
-    const winLine = findWinLine(state.board, state.winner);
+  if (state.winner && state.winningLine) {
+    const winLine = state.winningLine.map(squareCell);
     if (winLine) {
       const animate = !state.winLineShown;
       state.winLineShown = true;
@@ -1023,6 +1061,26 @@ function renderRematchActions() {
   return wrap;
 }
 
+function renderGameActions() {
+  const wrap = document.createElement("div");
+  wrap.className = "rematch-area";
+
+  wrap.appendChild(rematchButton("Resign", sendResign, "rematch-btn-ghost"));
+
+  let drawBtn;
+  if (state.drawOfferSent) {
+    drawBtn = rematchButton("Draw offered\u2026", () => {}, "rematch-btn-ghost");
+    drawBtn.disabled = true;
+  } else if (state.opponentOffersDraw) {
+    drawBtn = rematchButton("Accept draw", sendDrawOffer, "rematch-btn-primary");
+  } else {
+    drawBtn = rematchButton("Offer draw", sendDrawOffer, "rematch-btn-ghost");
+  }
+  wrap.appendChild(drawBtn);
+
+  return wrap;
+}
+
 function renderInviteLobby() {
   const card = document.createElement("div");
   card.className = "invite-card";
@@ -1116,11 +1174,11 @@ function renderHand(color) {
 
   panel.appendChild(document.createElement("span"));
 
-  for (const kind of KINDS) {
+  for (const kind of handKinds()) {
     const cell = document.createElement("div");
     cell.className = "hand-cell";
 
-    const inHand = !isPieceOnBoard(color, kind);
+    const inHand = isPieceInHand(color, kind);
 
     if (inHand) {
       const span = document.createElement("span");
@@ -1171,37 +1229,19 @@ function renderBoard(flipped) {
   const board = document.createElement("div");
   board.className = "board";
 
-  let moves = [];
-  if (
-    state.selectedPiece &&
-    state.selectedPiece.source === "board" &&
-    state.pawnDirections
-  ) {
-    const pos = findPiecePosition(state.board, state.selectedPiece.code);
-    if (pos) {
-      moves = computeMoves(
-        state.board,
-        state.selectedPiece,
-        pos.row,
-        pos.col,
-        state.pawnDirections,
-      );
-    }
-  }
-
-  const handPlacement =
-    state.selectedPiece && state.selectedPiece.source === "hand";
+  const moves = state.selectedPiece ? legalTargets(state.selectedPiece) : [];
 
-  for (let i = 0; i < 4; i += 1) {
-    const engineRow = flipped ? 3 - i : i;
-    const rankNum = 4 - engineRow;
+  const size = boardSize();
+  for (let i = 0; i < size; i += 1) {
+    const engineRow = flipped ? size - 1 - i : i;
+    const rankNum = size - engineRow;
 
     const leftLabel = document.createElement("span");
     leftLabel.className = "row-label";
     leftLabel.textContent = rankNum;
     board.appendChild(leftLabel);
 
-    for (let col = 0; col < 4; col += 1) {
+    for (let col = 0; col < size; col += 1) {
       const cell = document.createElement("div");
       cell.className = "board-cell";
       cell.dataset.row = engineRow;
@@ -1210,8 +1250,6 @@ function renderBoard(flipped) {
       const move = moves.find((m) => m.row === engineRow && m.col === col);
       if (move) {
         cell.classList.add(move.capture ? "target-capture" : "target");
-      } else if (handPlacement && !state.board[engineRow][col]) {
-        cell.classList.add("target");
       }
 
       const piece = state.board[engineRow][col];
@@ -1297,8 +1335,13 @@ function renderBoard(flipped) {
 function renderColLabels() {
   const labels = document.createElement("div");
   labels.className = "col-labels";
-  labels.innerHTML =
-    "<span></span><span>a</span><span>b</span><span>c</span><span>d</span><span></span>";
+  labels.appendChild(document.createElement("span"));
+  for (const file of FILES.slice(0, boardSize())) {
+    const span = document.createElement("span");
+    span.textContent = file;
+    labels.appendChild(span);
+  }
+  labels.appendChild(document.createElement("span"));
   return labels;
 }
 
@@ -1364,30 +1407,23 @@ function drawWinLine(boardEl, winLine, color, animate = true) {
   boardEl.appendChild(svg);
 }
 
-function findWinLine(board, color) {
-  const line = [];
-
-  board.forEach((row, rowIndex) => {
-    row.forEach((piece, colIndex) => {
-      if (piece && piece.color === color) {
-        line.push({ row: rowIndex, col: colIndex });
-      }
-    });
-  });
-
-  if (line.length < 4) {
-    return null;
-  }
-
-  return line;
-}
-
 function sendRematch() {
   send({ type: "rematch" });
   state.rematchSent = true;
   render();
 }
 
+function sendResign() {
+  if (!window.confirm("Resign this game?")) return;
+  send({ type: "resign" });
+}
+
+function sendDrawOffer() {
+  send({ type: "drawOffer" });
+  state.drawOfferSent = true;
+  render();
+}
+
 function leaveCurrentPage() {
   resetReconnect();
   disconnectSocket();
@@ -1551,15 +1587,21 @@ async function copyInviteLink(inviteURL) {
 
 function resetBoardState() {
   state.board = null;
+  state.rules = null;
+  state.hands = null;
   state.prev = null;
   state.turn = null;
   state.status = null;
   state.winner = null;
+  state.termination = null;
+  state.winningLine = null;
   state.winLineShown = false;
-  state.pawnDirections = null;
+  state.legalMoves = [];
   state.selectedPiece = null;
   state.rematchSent = false;
   state.opponentWantsRematch = false;
+  state.drawOfferSent = false;
+  state.opponentOffersDraw = false;
   state.opponentStatus = null;
 }
 
@@ -1583,6 +1625,24 @@ function reconcileSelectedPiece() {
   }
 }
 
+function boardSize() {
+  return state.board ? state.board.length : 4;
+}
+
+function handKinds() {
+  if (state.rules && state.rules.queen) {
+    return [...KINDS, "queen"];
+  }
+  return KINDS;
+}
+
+function isPieceInHand(color, kind) {
+  if (state.hands) {
+    return state.hands[color].includes(kind);
+  }
+  return !isPieceOnBoard(color, kind);
+}
+
 function isPieceOnBoard(color, kind) {
   if (!state.board) {
     return false;
@@ -1728,108 +1788,9 @@ function rematchButton(label, onClick, extraClass = "") {
   return btn;
 }
 
-function canMoveTo(board, color, row, col) {
-  if (row < 0 || row > 3 || col < 0 || col > 3) {
-    return { allowed: false, capture: false };
-  }
-  const piece = board[row][col];
-  if (!piece) {
-    return { allowed: true, capture: false };
-  }
-  if (piece.color !== color) {
-    return { allowed: true, capture: true };
-  }
-  return { allowed: false, capture: false };
-}
-
-function slideMoves(board, color, row, col, directions) {
-  const moves = [];
-  for (const [dr, dc] of directions) {
-    let r = row;
-    let c = col;
-    for (let i = 0; i < 3; i++) {
-      r += dr;
-      c += dc;
-      const result = canMoveTo(board, color, r, c);
-      if (result.allowed) {
-        moves.push({ row: r, col: c, capture: result.capture });
-        if (result.capture) break;
-      } else {
-        break;
-      }
-    }
-  }
-  return moves;
-}
-
-function rookMoves(board, color, row, col) {
-  return slideMoves(board, color, row, col, [
-    [0, 1],
-    [0, -1],
-    [-1, 0],
-    [1, 0],
-  ]);
-}
-
-function bishopMoves(board, color, row, col) {
-  return slideMoves(board, color, row, col, [
-    [-1, 1],
-    [1, 1],
-    [1, -1],
-    [-1, -1],
-  ]);
-}
-
-function knightMoves(board, color, row, col) {
-  const moves = [];
-  const jumps = [
-    [-2, -1],
-    [-2, 1],
-    [2, -1],
-    [2, 1],
-    [-1, -2],
-    [-1, 2],
-    [1, -2],
-    [1, 2],
-  ];
-  for (const [dr, dc] of jumps) {
-    const result = canMoveTo(board, color, row + dr, col + dc);
-    if (result.allowed) {
-      moves.push({ row: row + dr, col: col + dc, capture: result.capture });
-    }
-  }
-  return moves;
-}
-
-function pawnMoves(board, color, row, col, direction) {
-  const moves = [];
-  const forwardRow = row + direction;
-
-  if (forwardRow >= 0 && forwardRow <= 3 && !board[forwardRow][col]) {
-    moves.push({ row: forwardRow, col: col, capture: false });
-  }
-
-  for (const dc of [-1, 1]) {
-    const captureCol = col + dc;
-    if (forwardRow < 0 || forwardRow > 3 || captureCol < 0 || captureCol > 3)
-      continue;
-    const target = board[forwardRow][captureCol];
-    if (target && target.color !== color) {
-      moves.push({ row: forwardRow, col: captureCol, capture: true });
-    }
-  }
-
-  return moves;
-}
-
-function pawnDirection(pawnDirections, color) {
-  const dir = pawnDirections[color];
-  return dir === "toBlackSide" ? -1 : 1;
-}
-
 function findPiecePosition(board, code) {
-  for (let row = 0; row < 4; row++) {
-    for (let col = 0; col < 4; col++) {
+  for (let row = 0; row < board.length; row++) {
+    for (let col = 0; col < board.length; col++) {
       const piece = board[row][col];
       if (piece && PIECE_CODES[piece.color][piece.kind] === code) {
         return { row, col };
@@ -1839,29 +1800,24 @@ function findPiecePosition(board, code) {
   return null;
 }
 
-function computeMoves(board, piece, row, col, pawnDirections) {
-  switch (piece.kind) {
-    case "rook":
-      return rookMoves(board, piece.color, row, col);
-    case "bishop":
-      return bishopMoves(board, piece.color, row, col);
-    case "knight":
-      return knightMoves(board, piece.color, row, col);
-    case "pawn":
-      return pawnMoves(
-        board,
-        piece.color,
-        row,
-        col,
-        pawnDirection(pawnDirections, piece.color),
-      );
-    default:
-      return [];
-  }
+// Returns the cells the selected piece can move or be dropped to, from the
+// legal moves the server sends with every state.
+function legalTargets(selected) {
+  const drop = selected.source === "hand";
+  return state.legalMoves
+    .filter((m) => m.piece === selected.code && m.drop === drop)
+    .map((m) => ({ ...squareCell(m.to), capture: m.capture }));
 }
 
 function cellNotation(row, col) {
-  return "abcd"[col] + (4 - row);
+  return FILES[col] + (boardSize() - row);
+}
+
+function squareCell(square) {
+  return {
+    row: boardSize() - Number(square.slice(1)),
+    col: FILES.indexOf(square[0]),
+  };
 }
 
 function wsURL(path) {
@@ -3,6 +3,8 @@ package ws
 import (
 	"tic-tac-chec/engine"
 	"tic-tac-chec/internal/game"
+	"tic-tac-chec/internal/parse"
+	"tic-tac-chec/internal/wire"
 )
 
 func roomEventMessage(event game.Event) (any, bool) {
@@ -10,7 +12,7 @@ func roomEventMessage(event game.Event) (any, bool) {
 	case game.SnapshotEvent:
 		return GameStateMessage{
 			Type:  "gameState",
-			State: gameStatePayloadFrom(event.Game),
+			State: gameStatePayloadFrom(event.Game.Game()),
 		}, true
 	case game.ErrorEvent:
 		errText := "unknown error"
@@ -39,6 +41,10 @@ func roomEventMessage(event game.Event) (any, bool) {
 		return struct {
 			Type string `json:"type"`
 		}{Type: "rematchRequested"}, true
+	case game.DrawOfferedEvent:
+		return struct {
+			Type string `json:"type"`
+		}{Type: "drawOffered"}, true
 	case game.ReactionEvent:
 		return OutboundReactionMessage{
 			Type:     "reaction",
@@ -49,11 +55,23 @@ func roomEventMessage(event game.Event) (any, bool) {
 	}
 }
 
-func gameStatePayloadFrom(g engine.Game) GameStatePayload {
+func gameStatePayloadFrom(g *engine.Game) GameStatePayload {
+	size := g.Rules.Size()
 	payload := GameStatePayload{
-		Turn:   colorName(g.Turn),
-		Status: gameStatusName(g.Status),
-		Winner: nil,
+		Rules: RulesPayload{
+			Name:              g.Rules.String(),
+			BoardSize:         size,
+			WinLength:         g.Rules.LineLength(),
+			DiscardCaptures:   g.Rules.DiscardCaptures,
+			KeepPawnDirection: g.Rules.KeepPawnDirection,
+			Queen:             g.Rules.Queen,
+		},
+		Board:       make([][]*PiecePayload, size),
+		Hands:       HandsPayload{White: handPayload(g, engine.White), Black: handPayload(g, engine.Black)},
+		Turn:        colorName(g.Turn),
+		Status:      gameStatusName(g.Status),
+		Winner:      nil,
+		Termination: wire.TerminationToString(g.Termination),
 		PawnDirections: PawnDirectionsPayload{
 			White: pawnDirectionName(g.PawnDirections[engine.White]),
 			Black: pawnDirectionName(g.PawnDirections[engine.Black]),
@@ -65,8 +83,13 @@ func gameStatePayloadFrom(g engine.Game) GameStatePayload {
 		payload.Winner = &winner
 	}
 
-	for row := range g.Board {
-		for col := range g.Board[row] {
+	for _, cell := range g.WinningLine {
+		payload.WinningLine = append(payload.WinningLine, parse.FormatSquareOn(cell, size))
+	}
+
+	for row := range size {
+		payload.Board[row] = make([]*PiecePayload, size)
+		for col := range size {
 			piece := g.Board[row][col]
 			if piece == nil {
 				continue
@@ -79,9 +102,33 @@ func gameStatePayloadFrom(g engine.Game) GameStatePayload {
 		}
 	}
 
+	payload.LegalMoves = []MovePayload{}
+	for _, move := range g.AllLegalMoves() {
+		m := MovePayload{
+			Piece:   parse.FormatPiece(move.Piece),
+			To:      parse.FormatSquareOn(move.To, size),
+			Drop:    move.Drop,
+			Capture: move.Capture,
+		}
+		if !move.Drop {
+			m.From = parse.FormatSquareOn(move.From, size)
+		}
+		payload.LegalMoves = append(payload.LegalMoves, m)
+	}
+
 	return payload
 }
 
+func handPayload(g *engine.Game, color engine.Color) []string {
+	hand := []string{}
+	for _, kind := range g.Rules.Kinds() {
+		if g.PieceInHand(engine.Piece{Color: color, Kind: kind}) {
+			hand = append(hand, pieceKindName(kind))
+		}
+	}
+	return hand
+}
+
 func pawnDirectionName(direction engine.PawnDirection) string {
 	switch direction {
 	case engine.ToBlackSide:
@@ -114,6 +161,8 @@ func pieceKindName(kind engine.PieceKind) string {
 		return "bishop"
 	case engine.Knight:
 		return "knight"
+	case engine.Queen:
+		return "queen"
 	default:
 		return ""
 	}
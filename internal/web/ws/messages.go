@@ -3,7 +3,6 @@ package ws
 import (
 	"context"
 	"encoding/json"
-	"tic-tac-chec/engine"
 	"tic-tac-chec/internal/game"
 
 	"github.com/coder/websocket"
@@ -58,11 +57,44 @@ type GameStateMessage struct {
 }
 
 type GameStatePayload struct {
-	Board          [engine.BoardSize][engine.BoardSize]*PiecePayload `json:"board"`
-	Turn           string                                            `json:"turn"`
-	Status         string                                            `json:"status"`
-	Winner         *string                                           `json:"winner"`
-	PawnDirections PawnDirectionsPayload                             `json:"pawnDirections"`
+	Rules          RulesPayload          `json:"rules"`
+	Board          [][]*PiecePayload     `json:"board"` // Rules.BoardSize rows from the top
+	Hands          HandsPayload          `json:"hands"`
+	Turn           string                `json:"turn"`
+	Status         string                `json:"status"`
+	Winner         *string               `json:"winner"`
+	Termination    string                `json:"termination,omitempty"`
+	WinningLine    []string              `json:"winningLine,omitempty"` // squares, e.g. "a1"
+	PawnDirections PawnDirectionsPayload `json:"pawnDirections"`
+	LegalMoves     []MovePayload         `json:"legalMoves"`
+}
+
+// RulesPayload describes the variant being played, see engine.Rules.
+// Name is the compact form, e.g. "4x4" or "5x5-4+queen".
+type RulesPayload struct {
+	Name              string `json:"name"`
+	BoardSize         int    `json:"boardSize"`
+	WinLength         int    `json:"winLength"`
+	DiscardCaptures   bool   `json:"discardCaptures"`
+	KeepPawnDirection bool   `json:"keepPawnDirection"`
+	Queen             bool   `json:"queen"`
+}
+
+// HandsPayload lists the kinds each side can drop. Pieces that are neither
+// on the board nor in hand were discarded.
+type HandsPayload struct {
+	White []string `json:"white"`
+	Black []string `json:"black"`
+}
+
+// MovePayload is a legal move for the side to move.
+// From is empty for drops.
+type MovePayload struct {
+	Piece   string `json:"piece"`
+	From    string `json:"from,omitempty"`
+	To      string `json:"to"`
+	Drop    bool   `json:"drop"`
+	Capture bool   `json:"capture"`
 }
 
 type PawnDirectionsPayload struct {
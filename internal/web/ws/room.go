@@ -86,7 +86,7 @@ func ServeRoom(ctx context.Context, ws *websocket.Conn, room *game.Room, partici
 				target = move.Cell
 			}
 
-			to, err := parse.Square(target)
+			to, err := parse.SquareOn(target, room.Rules.Size())
 			if err != nil {
 				sendMessage(ctx, ws, ErrorMessage{Type: "error", Error: err.Error()})
 				continue
@@ -95,6 +95,10 @@ func ServeRoom(ctx context.Context, ws *websocket.Conn, room *game.Room, partici
 			commands <- game.MoveCommand{Piece: piece, To: to}
 		case "rematch":
 			commands <- game.RematchCommand{PlayerID: participant.PlayerID}
+		case "resign":
+			commands <- game.ResignCommand{PlayerID: participant.PlayerID}
+		case "drawOffer":
+			commands <- game.DrawOfferCommand{PlayerID: participant.PlayerID}
 		case "reaction":
 			var reaction InboundReactionMessage
 			if err := json.Unmarshal(msg, &reaction); err != nil {
@@ -3,37 +3,80 @@ package wire
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"tic-tac-chec/engine"
 )
 
 type GameState struct {
-	Board          Board          `json:"board"`
-	Turn           Turn           `json:"turn"`
-	Status         GameStatus     `json:"status"`
-	Winner         *Turn          `json:"winner"`
-	PawnDirections PawnDirections `json:"pawnDirections"`
-	MoveCount      uint           `json:"moveCount"`
+	// Rules are written by engine.Rules.String and left out for standard games.
+	Rules           string         `json:"rules,omitempty"`
+	Board           Board          `json:"board"`
+	Turn            Turn           `json:"turn"`
+	Status          GameStatus     `json:"status"`
+	Winner          *Turn          `json:"winner"`
+	PawnDirections  PawnDirections `json:"pawnDirections"`
+	MoveCount       uint           `json:"moveCount"`
+	Termination     Termination    `json:"termination"`
+	WinningLine     []cellJSON     `json:"winningLine,omitempty"`
+	QuietMoveCount  uint           `json:"quietMoveCount"`
+	NoProgressLimit *uint          `json:"noProgressLimit"`
+	Positions       []engine.Key   `json:"positions"`
+	History         []Move         `json:"history"`
+	Undone          []Move         `json:"undone"`
+	// Discarded lists the pieces taken out of the game
+	// under engine.Rules.DiscardCaptures.
+	Discarded []pieceJSON `json:"discarded,omitempty"`
 }
 
-type Board engine.Board
+type pieceJSON struct {
+	Color string `json:"color"`
+	Kind  string `json:"kind"`
+}
 
-func (b Board) MarshalJSON() ([]byte, error) {
-	type pieceJSON struct {
-		Color string `json:"color"`
-		Kind  string `json:"kind"`
+func toPieceJSON(piece engine.Piece) *pieceJSON {
+	return &pieceJSON{
+		Color: ColorToString(piece.Color),
+		Kind:  kindToString(piece.Kind),
+	}
+}
+
+func (p pieceJSON) piece() (engine.Piece, error) {
+	color, err := strToColor(p.Color)
+	if err != nil {
+		return engine.Piece{}, err
+	}
+	kind, err := strToKind(p.Kind)
+	if err != nil {
+		return engine.Piece{}, err
+	}
+	return engine.Piece{Color: color, Kind: kind}, nil
+}
+
+// Board holds the rows of the board, as many as the board size of the rules.
+type Board [][]*engine.Piece
+
+func toBoard(board engine.Board, size int) Board {
+	res := make(Board, size)
+	for i := range size {
+		res[i] = make([]*engine.Piece, size)
+		for j := range size {
+			if piece := board[i][j]; piece != nil {
+				p := *piece
+				res[i][j] = &p
+			}
+		}
 	}
+	return res
+}
 
-	res := [engine.BoardSize][engine.BoardSize]*pieceJSON{}
+func (b Board) MarshalJSON() ([]byte, error) {
+	res := make([][]*pieceJSON, len(b))
 
 	for i, row := range b {
+		res[i] = make([]*pieceJSON, len(row))
 		for j, piece := range row {
-			if piece == nil {
-				res[i][j] = nil
-			} else {
-				res[i][j] = &pieceJSON{
-					Color: ColorToString(piece.Color),
-					Kind:  kindToString(piece.Kind),
-				}
+			if piece != nil {
+				res[i][j] = toPieceJSON(*piece)
 			}
 		}
 	}
@@ -42,37 +85,128 @@ func (b Board) MarshalJSON() ([]byte, error) {
 }
 
 func (b *Board) UnmarshalJSON(data []byte) error {
-	type pieceJSON struct {
-		Color string `json:"color"`
-		Kind  string `json:"kind"`
-	}
-
-	var wire [engine.BoardSize][engine.BoardSize]*pieceJSON
+	var wire [][]*pieceJSON
 	if err := json.Unmarshal(data, &wire); err != nil {
 		return err
 	}
+	if len(wire) > engine.MaxBoardSize {
+		return fmt.Errorf("board has %d rows, at most %d allowed", len(wire), engine.MaxBoardSize)
+	}
 
+	*b = make(Board, len(wire))
 	for i, row := range wire {
+		if len(row) != len(wire) {
+			return fmt.Errorf("board row %d has %d cells, want %d", i, len(row), len(wire))
+		}
+
+		(*b)[i] = make([]*engine.Piece, len(row))
 		for j, piece := range row {
 			if piece == nil {
-				b[i][j] = nil
-			} else {
-				color, err := strToColor(piece.Color)
-				if err != nil {
-					return err
-				}
-				kind, err := strToKind(piece.Kind)
-				if err != nil {
-					return err
-				}
-				b[i][j] = &engine.Piece{Color: color, Kind: kind}
+				continue
+			}
+			p, err := piece.piece()
+			if err != nil {
+				return err
 			}
+			(*b)[i][j] = &p
 		}
 	}
 
 	return nil
 }
 
+type Move engine.Move
+
+type cellJSON struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+type moveJSON struct {
+	Piece                pieceJSON      `json:"piece"`
+	From                 cellJSON       `json:"from"`
+	To                   cellJSON       `json:"to"`
+	Drop                 bool           `json:"drop"`
+	Captured             *pieceJSON     `json:"captured"`
+	PawnDirectionsBefore PawnDirections `json:"pawnDirectionsBefore"`
+	PawnDirectionsAfter  PawnDirections `json:"pawnDirectionsAfter"`
+	QuietMoveCountBefore uint           `json:"quietMoveCountBefore"`
+}
+
+func (m Move) MarshalJSON() ([]byte, error) {
+	res := moveJSON{
+		Piece:                *toPieceJSON(m.Piece),
+		From:                 cellJSON{Row: m.From.Row, Col: m.From.Col},
+		To:                   cellJSON{Row: m.To.Row, Col: m.To.Col},
+		Drop:                 m.Drop,
+		PawnDirectionsBefore: PawnDirections(m.PawnDirectionsBefore),
+		PawnDirectionsAfter:  PawnDirections(m.PawnDirectionsAfter),
+		QuietMoveCountBefore: m.QuietMoveCountBefore,
+	}
+	if m.Capture {
+		res.Captured = toPieceJSON(m.Captured)
+	}
+
+	return json.Marshal(res)
+}
+
+func (m *Move) UnmarshalJSON(data []byte) error {
+	var wire moveJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	piece, err := wire.Piece.piece()
+	if err != nil {
+		return err
+	}
+
+	*m = Move{
+		Piece:                piece,
+		From:                 engine.Cell{Row: wire.From.Row, Col: wire.From.Col},
+		To:                   engine.Cell{Row: wire.To.Row, Col: wire.To.Col},
+		Drop:                 wire.Drop,
+		PawnDirectionsBefore: engine.PawnDirections(wire.PawnDirectionsBefore),
+		PawnDirectionsAfter:  engine.PawnDirections(wire.PawnDirectionsAfter),
+		QuietMoveCountBefore: wire.QuietMoveCountBefore,
+	}
+
+	if wire.Captured != nil {
+		captured, err := wire.Captured.piece()
+		if err != nil {
+			return err
+		}
+		m.Capture = true
+		m.Captured = captured
+	}
+
+	return nil
+}
+
+func toMoves(moves []engine.Move) []Move {
+	if moves == nil {
+		return nil
+	}
+
+	res := make([]Move, len(moves))
+	for i, move := range moves {
+		res[i] = Move(move)
+	}
+	return res
+}
+
+func fromMoves(moves []Move) []engine.Move {
+	if moves == nil {
+		return nil
+	}
+
+	res := make([]engine.Move, len(moves))
+	for i, move := range moves {
+		res[i] = engine.Move(move)
+	}
+	return res
+}
+
 type Turn engine.Color
 
 func (t Turn) MarshalJSON() ([]byte, error) {
@@ -121,6 +255,71 @@ func (gs *GameStatus) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+type Termination engine.Termination
+
+func (t Termination) MarshalJSON() ([]byte, error) {
+	return json.Marshal(TerminationToString(engine.Termination(t)))
+}
+
+func (t *Termination) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	termination, err := strToTermination(str)
+	if err != nil {
+		return err
+	}
+	*t = Termination(termination)
+	return nil
+}
+
+func TerminationToString(termination engine.Termination) string {
+	switch termination {
+	case engine.NotTerminated:
+		return ""
+	case engine.FourInARow:
+		return "fourInARow"
+	case engine.Repetition:
+		return "repetition"
+	case engine.MoveLimit:
+		return "moveLimit"
+	case engine.Resignation:
+		return "resignation"
+	case engine.Timeout:
+		return "timeout"
+	case engine.Abandonment:
+		return "abandonment"
+	case engine.AgreedDraw:
+		return "agreedDraw"
+	default:
+		panic("unknown termination")
+	}
+}
+
+func strToTermination(str string) (engine.Termination, error) {
+	switch str {
+	case "":
+		return engine.NotTerminated, nil
+	case "fourInARow":
+		return engine.FourInARow, nil
+	case "repetition":
+		return engine.Repetition, nil
+	case "moveLimit":
+		return engine.MoveLimit, nil
+	case "resignation":
+		return engine.Resignation, nil
+	case "timeout":
+		return engine.Timeout, nil
+	case "abandonment":
+		return engine.Abandonment, nil
+	case "agreedDraw":
+		return engine.AgreedDraw, nil
+	default:
+		return engine.NotTerminated, fmt.Errorf("unknown termination: %s", str)
+	}
+}
+
 type PawnDirections engine.PawnDirections
 
 func (pd PawnDirections) MarshalJSON() ([]byte, error) {
@@ -217,6 +416,8 @@ func kindToString(kind engine.PieceKind) string {
 		return "bishop"
 	case engine.Rook:
 		return "rook"
+	case engine.Queen:
+		return "queen"
 	default:
 		panic("unknown kind")
 	}
@@ -232,13 +433,25 @@ func strToKind(kind string) (engine.PieceKind, error) {
 		return engine.Bishop, nil
 	case "knight":
 		return engine.Knight, nil
+	case "queen":
+		return engine.Queen, nil
 	default:
 		return engine.PieceKind(0), fmt.Errorf("unknown kind: %s", kind)
 	}
 }
 
-func GameFromState(state *GameState) *engine.Game {
-	game := engine.NewGame()
+// GameFromState builds the game a state describes. It fails if the state's
+// rules are invalid or its board does not fit them.
+func GameFromState(state *GameState) (*engine.Game, error) {
+	rules, err := engine.ParseRules(state.Rules)
+	if err != nil {
+		return nil, err
+	}
+	if len(state.Board) > rules.Size() {
+		return nil, fmt.Errorf("board has %d rows, rules %v allow %d", len(state.Board), rules, rules.Size())
+	}
+
+	game := engine.NewGame(rules)
 	for i, row := range state.Board {
 		for j, piece := range row {
 			if piece == nil {
@@ -252,17 +465,70 @@ func GameFromState(state *GameState) *engine.Game {
 	game.Winner = (*engine.Color)(state.Winner)
 	game.PawnDirections = engine.PawnDirections(state.PawnDirections)
 	game.MoveCount = state.MoveCount
+	game.Termination = engine.Termination(state.Termination)
+	for _, cell := range state.WinningLine {
+		game.WinningLine = append(game.WinningLine, engine.Cell{Row: cell.Row, Col: cell.Col})
+	}
+	game.QuietMoveCount = state.QuietMoveCount
+	// states saved before the move limit keep the default from NewGame
+	if state.NoProgressLimit != nil {
+		game.NoProgressLimit = *state.NoProgressLimit
+	}
+
+	if state.Positions != nil {
+		game.Positions = slices.Clone(state.Positions)
+	}
+	game.History = fromMoves(state.History)
+	game.Undone = fromMoves(state.Undone)
 
-	return game
+	for _, p := range state.Discarded {
+		piece, err := p.piece()
+		if err != nil {
+			return nil, err
+		}
+		game.Discarded[piece.Color][piece.Kind] = true
+	}
+
+	return game, nil
 }
 
 func ToGameState(game *engine.Game) *GameState {
+	noProgressLimit := game.NoProgressLimit
+
+	var rules string
+	if !game.Rules.Standard() {
+		rules = game.Rules.String()
+	}
+
+	var winningLine []cellJSON
+	for _, cell := range game.WinningLine {
+		winningLine = append(winningLine, cellJSON{Row: cell.Row, Col: cell.Col})
+	}
+
+	var discarded []pieceJSON
+	for color := range engine.ColorCount {
+		for kind := range engine.PieceKindCount {
+			if game.Discarded[color][kind] {
+				discarded = append(discarded, *toPieceJSON(engine.Piece{Color: color, Kind: kind}))
+			}
+		}
+	}
+
 	return &GameState{
-		Board:          Board(game.Board),
-		Turn:           Turn(game.Turn),
-		Status:         GameStatus(game.Status),
-		Winner:         (*Turn)(game.Winner),
-		PawnDirections: PawnDirections(game.PawnDirections),
-		MoveCount:      game.MoveCount,
+		Rules:           rules,
+		Board:           toBoard(game.Board, game.Rules.Size()),
+		Turn:            Turn(game.Turn),
+		Status:          GameStatus(game.Status),
+		Winner:          (*Turn)(game.Winner),
+		PawnDirections:  PawnDirections(game.PawnDirections),
+		MoveCount:       game.MoveCount,
+		Termination:     Termination(game.Termination),
+		WinningLine:     winningLine,
+		QuietMoveCount:  game.QuietMoveCount,
+		NoProgressLimit: &noProgressLimit,
+		Positions:       slices.Clone(game.Positions),
+		History:         toMoves(game.History),
+		Undone:          toMoves(game.Undone),
+		Discarded:       discarded,
 	}
 }